@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+)
+
+// AbciInfoChecker alerts when the app version changes under the same commitId.
+// An intended upgrade is rolled out with a new commitId, so a change within a commitId wasn't planned.
+func AbciInfoChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(abciInfoFormatf("Starting: " + fn))
+
+	// Only events of the current commitId are compared.
+	abciInfoRepository := repository.AbciInfoRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName := range c.AgentCheckers {
+		abciInfos, err := abciInfoRepository.FindLatestAbciInfos(2, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(abciInfoFormatf(err.Error())))
+			continue
+		}
+		if len(abciInfos) < 2 {
+			log.Debug(abciInfoFormatf("Not enough abci infos found for this agent: %s", agentName))
+			continue
+		}
+
+		latest, previous := abciInfos[0], abciInfos[1]
+		if latest.Version != previous.Version || latest.AppVersion != previous.AppVersion {
+			var errorMsg = fmt.Sprintf("\nApp: %s\nVersion: %s -> %s\nAppVersion: %d -> %d\nLastBlockHeight: %d\nCommitId: %s (not changed)",
+				latest.AppName, previous.Version, latest.Version, previous.AppVersion, latest.AppVersion, latest.LastBlockHeight, c.CommitId)
+
+			sendAlert(c, client, agentName, APP_VERSION_TM_ALARM_TYPE, errorMsg, abciInfoFormatf)
+		}
+
+		log.Debug(abciInfoFormatf("Complete to check Agent: (%s). version: %s", agentName, latest.Version))
+	}
+}
@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"time"
+)
+
+// roundStepPrevote is CometBFT's RoundStepPrevote. From this step on, the validator's prevote is expected.
+const roundStepPrevote = 4
+
+func ConsensusStateChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(consensusStateFormatf("Starting: " + fn))
+
+	consensusStateRepository := repository.ConsensusStateRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.ConsensusCheck == nil {
+			continue
+		}
+
+		// Judge stuck in round > 0
+		startTime := time.Now().UTC().Add(-*agentChecker.ConsensusCheck.MaxNonZeroRoundTime)
+		consensusStates, err := consensusStateRepository.FindConsensusStatesAfterStartTime(startTime, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(consensusStateFormatf(err.Error())))
+			continue
+		}
+
+		if len(consensusStates) < 2 {
+			log.Debug(consensusStateFormatf("Not enough consensus states found after %v for this agent: %s", startTime, agentName))
+		} else {
+			nonZeroRound := true
+			for _, consensusState := range consensusStates {
+				if consensusState.Round == 0 {
+					nonZeroRound = false
+					break
+				}
+			}
+
+			if nonZeroRound {
+				latest, oldest := consensusStates[0], consensusStates[len(consensusStates)-1]
+				var errorMsg = fmt.Sprintf("\nLatestConsensusState: \n height: %d, round: %d, step: %d\nRound has been > 0 since %v(%v)\nThresholdNonZeroRoundTime: %v",
+					latest.Height, latest.Round, latest.Step, oldest.CreatedAt, time.Now().UTC().Sub(oldest.CreatedAt), agentChecker.ConsensusCheck.MaxNonZeroRoundTime)
+
+				sendAlert(c, client, agentName, CONSENSUS_ROUND_TM_ALARM_TYPE, errorMsg, consensusStateFormatf)
+			}
+		}
+
+		// Judge missing prevote
+		consensusStates, err = consensusStateRepository.FindLatestConsensusStates(agentChecker.ConsensusCheck.MaxMissingPrevoteCount, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(consensusStateFormatf(err.Error())))
+			continue
+		}
+
+		if len(consensusStates) == 0 || len(consensusStates) < agentChecker.ConsensusCheck.MaxMissingPrevoteCount {
+			log.Debug(consensusStateFormatf("Not enough consensus states found for this agent: %s", agentName))
+			continue
+		}
+
+		missingPrevote := true
+		for _, consensusState := range consensusStates {
+			// Not a validator in the active set, or sampled before prevote step.
+			if consensusState.ValidatorAddress == "" || consensusState.Step < roundStepPrevote || consensusState.Prevoted {
+				missingPrevote = false
+				break
+			}
+		}
+
+		if missingPrevote {
+			latest := consensusStates[0]
+			var errorMsg = fmt.Sprintf("\nValidator: %s\nLatestConsensusState: \n height: %d, round: %d, step: %d, prevotes: %.2f\nMissing prevote in latest %d samples",
+				latest.ValidatorAddress, latest.Height, latest.Round, latest.Step, latest.PrevotesRatio, len(consensusStates))
+
+			sendAlert(c, client, agentName, MISSING_PREVOTE_TM_ALARM_TYPE, errorMsg, consensusStateFormatf)
+		}
+
+		log.Debug(consensusStateFormatf("Complete to check Agent: (%s). missing prevote = %t", agentName, missingPrevote))
+	}
+}
@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"time"
+)
+
+// GovChecker reminds proposals in voting period which the validator hasn't voted on.
+// Each proposal is reminded once per stage of GovCheck.RemindBefore.
+func GovChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(govFormatf("Starting: " + fn))
+
+	govRepository := repository.GovRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.GovCheck == nil || len(agentChecker.GovCheck.RemindBefore) == 0 {
+			continue
+		}
+
+		proposals, err := govRepository.FindLatestProposals(string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(govFormatf(err.Error())))
+			continue
+		}
+
+		var (
+			errorMsg  string
+			reminders = map[uint64]time.Duration{}
+			now       = time.Now().UTC()
+		)
+		for _, proposal := range proposals {
+			if proposal.Voted || !proposal.VotingEndTime.After(now) {
+				continue
+			}
+
+			remaining := proposal.VotingEndTime.Sub(now)
+			stage, ok := remindStage(remaining, agentChecker.GovCheck.RemindBefore)
+			if !ok {
+				continue
+			}
+
+			exists, err := govRepository.ExistsGovVoteReminder(string(agentName), proposal.ProposalId, stage)
+			if err != nil {
+				log.Error(errors.New(govFormatf(err.Error())))
+				continue
+			}
+			if exists {
+				continue
+			}
+
+			errorMsg += fmt.Sprintf("\nProposal #%d: %s\nVoting ends at: %s (in %s)\nVoter: %s",
+				proposal.ProposalId, proposal.Title, proposal.VotingEndTime.Format(time.RFC3339), remaining.Round(time.Minute), proposal.Voter)
+			reminders[proposal.ProposalId] = stage
+		}
+
+		if errorMsg != "" {
+			sendAlert(c, client, agentName, GOV_VOTE_TM_ALARM_TYPE, errorMsg, govFormatf)
+
+			for proposalId, stage := range reminders {
+				err = govRepository.SaveGovVoteReminder(string(agentName), proposalId, stage)
+				if err != nil {
+					log.Error(errors.New(govFormatf(err.Error())))
+				}
+			}
+		}
+
+		log.Debug(govFormatf("Complete to check Agent: (%s). proposals: %d, reminded: %d", agentName, len(proposals), len(reminders)))
+	}
+}
+
+// remindStage returns the smallest stage which the remaining time has reached.
+// It returns false when the remaining time is longer than every stage.
+func remindStage(remaining time.Duration, stages []time.Duration) (time.Duration, bool) {
+	var (
+		stage time.Duration
+		found bool
+	)
+	for _, s := range stages {
+		if remaining <= s && (!found || s < stage) {
+			stage, found = s, true
+		}
+	}
+	return stage, found
+}
@@ -0,0 +1,36 @@
+package checker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRemindStage(t *testing.T) {
+	stages := []time.Duration{24 * time.Hour, 6 * time.Hour, 1 * time.Hour}
+
+	t.Run("before every stage", func(t *testing.T) {
+		_, ok := remindStage(30*time.Hour, stages)
+		assert.False(t, ok)
+	})
+
+	t.Run("smallest reached stage", func(t *testing.T) {
+		stage, ok := remindStage(20*time.Hour, stages)
+		assert.True(t, ok)
+		assert.Equal(t, 24*time.Hour, stage)
+
+		stage, ok = remindStage(5*time.Hour, stages)
+		assert.True(t, ok)
+		assert.Equal(t, 6*time.Hour, stage)
+
+		stage, ok = remindStage(10*time.Minute, stages)
+		assert.True(t, ok)
+		assert.Equal(t, 1*time.Hour, stage)
+	})
+
+	t.Run("unsorted stages", func(t *testing.T) {
+		stage, ok := remindStage(30*time.Minute, []time.Duration{1 * time.Hour, 24 * time.Hour})
+		assert.True(t, ok)
+		assert.Equal(t, 1*time.Hour, stage)
+	})
+}
@@ -0,0 +1,156 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"time"
+)
+
+// minDiskPredictionSamples is how many disk usages are needed at least to predict when the disk will be full.
+const minDiskPredictionSamples = 3
+
+func HostResourceChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(hostResourceFormatf("Starting: " + fn))
+
+	hostResourceRepository := repository.HostResourceRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+	diskUsageRepository := repository.DiskUsageRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.HostCheck == nil {
+			continue
+		}
+		hostCheck := agentChecker.HostCheck
+
+		hostResources, err := hostResourceRepository.FindLatestHostResources(1, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(hostResourceFormatf(err.Error())))
+			continue
+		}
+		if len(hostResources) == 0 {
+			log.Debug(hostResourceFormatf("No host resource found for this agent: %s", agentName))
+			continue
+		}
+
+		hostResource := hostResources[0]
+		if hostResource.CreatedAt.Add(5 * time.Minute).Before(time.Now().UTC()) {
+			log.Warn(hostResourceFormatf("Agent(%s)'s latest host resource is too old: %v (%s ago)", agentName, hostResource.CreatedAt, time.Now().UTC().Sub(hostResource.CreatedAt)))
+		}
+
+		checkHostResource(c, client, agentName, hostCheck, hostResource)
+
+		diskUsages, err := diskUsageRepository.FindDiskUsagesAfterStartTime(string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME, time.Now().UTC().Add(-*hostCheck.DiskPredictionWindow))
+		if err != nil {
+			log.Error(errors.New(hostResourceFormatf(err.Error())))
+			continue
+		}
+
+		diskUsagesByPath := map[string][]repository.DiskUsage{}
+		for _, diskUsage := range diskUsages {
+			diskUsagesByPath[diskUsage.Path] = append(diskUsagesByPath[diskUsage.Path], diskUsage)
+		}
+		for path, usages := range diskUsagesByPath {
+			checkDiskUsage(c, client, agentName, hostCheck, path, usages)
+		}
+
+		log.Debug(hostResourceFormatf("Complete to check Agent: (%s). disks: %d", agentName, len(diskUsagesByPath)))
+	}
+}
+
+func checkHostResource(c *types.CheckerConfig, client *types.CheckerClient, agentName types.AgentName, hostCheck *types.HostCheck, hostResource repository.HostResource) {
+	var errorMsg string
+
+	if ratio := hostResource.MemUsageRatio(); ratio >= hostCheck.MaxMemoryUsageRatio {
+		errorMsg += fmt.Sprintf("\nMemory: %.1f%% used (%d/%d bytes available)\nThreshold: %.1f%%",
+			ratio*100, hostResource.MemAvailBytes, hostResource.MemTotalBytes, hostCheck.MaxMemoryUsageRatio*100)
+	}
+	if hostResource.NumCPU > 0 {
+		if loadPerCPU := hostResource.Load5 / float64(hostResource.NumCPU); loadPerCPU >= hostCheck.MaxLoadPerCPU {
+			errorMsg += fmt.Sprintf("\nLoad average(5m): %.2f (%d cpus)\nThreshold: %.2f per cpu",
+				hostResource.Load5, hostResource.NumCPU, hostCheck.MaxLoadPerCPU)
+		}
+	}
+	if ratio := hostResource.FdUsageRatio(); ratio >= hostCheck.MaxFdUsageRatio {
+		errorMsg += fmt.Sprintf("\nFile descriptors: %d/%d\nThreshold: %.1f%%",
+			hostResource.OpenFds, hostResource.MaxFds, hostCheck.MaxFdUsageRatio*100)
+	}
+
+	if errorMsg != "" {
+		sendAlert(c, client, agentName, HOST_RESOURCE_TM_ALARM_TYPE, errorMsg, hostResourceFormatf)
+	}
+}
+
+// checkDiskUsage checks the latest usage of the path and when it will be full. usages must be sorted oldest first.
+func checkDiskUsage(c *types.CheckerConfig, client *types.CheckerClient, agentName types.AgentName, hostCheck *types.HostCheck, path string, usages []repository.DiskUsage) {
+	latest := usages[len(usages)-1]
+
+	if ratio := latest.UsageRatio(); ratio >= hostCheck.MaxDiskUsageRatio {
+		var errorMsg = fmt.Sprintf("\nPath: %s (%s)\nUsage: %.1f%% (%d bytes available)\nThreshold: %.1f%%",
+			path, latest.MountPoint, ratio*100, latest.AvailBytes, hostCheck.MaxDiskUsageRatio*100)
+
+		sendAlert(c, client, agentName, DISK_USAGE_TM_ALARM_TYPE, errorMsg, hostResourceFormatf)
+	}
+
+	if len(usages) < minDiskPredictionSamples {
+		log.Debug(hostResourceFormatf("Not enough disk usages to predict. agent: %s, path: %s, samples: %d", agentName, path, len(usages)))
+		return
+	}
+
+	timeToFull, ok := predictTimeToDiskFull(usages)
+	if !ok {
+		return
+	}
+	if timeToFull < *hostCheck.MinTimeToDiskFull {
+		var errorMsg = fmt.Sprintf("\nPath: %s (%s)\nPredicted to be full in: %s (%d bytes available)\nThreshold: %s",
+			path, latest.MountPoint, timeToFull.Round(time.Minute), latest.AvailBytes, hostCheck.MinTimeToDiskFull)
+
+		sendAlert(c, client, agentName, DISK_FULL_TM_ALARM_TYPE, errorMsg, hostResourceFormatf)
+	}
+}
+
+// predictTimeToDiskFull fits used bytes over time by least squares,
+// and returns how long after the latest usage the fitted line reaches the capacity.
+// It returns false when the usage isn't growing.
+func predictTimeToDiskFull(usages []repository.DiskUsage) (time.Duration, bool) {
+	var (
+		n      = float64(len(usages))
+		origin = usages[0].CreatedAt
+		sumX   float64
+		sumY   float64
+	)
+	for _, u := range usages {
+		sumX += u.CreatedAt.Sub(origin).Seconds()
+		sumY += float64(u.UsedBytes)
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var covXY, varX float64
+	for _, u := range usages {
+		dx := u.CreatedAt.Sub(origin).Seconds() - meanX
+		covXY += dx * (float64(u.UsedBytes) - meanY)
+		varX += dx * dx
+	}
+	if varX == 0 {
+		return 0, false
+	}
+
+	slope := covXY / varX
+	if slope <= 0 {
+		return 0, false
+	}
+
+	latest := usages[len(usages)-1]
+	capacity := float64(latest.UsedBytes + latest.AvailBytes)
+	fittedLatest := meanY + slope*(latest.CreatedAt.Sub(origin).Seconds()-meanX)
+
+	remaining := (capacity - fittedLatest) / slope
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(remaining * float64(time.Second)), true
+}
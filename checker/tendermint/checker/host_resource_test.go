@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPredictTimeToDiskFull(t *testing.T) {
+	start := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	usagesOf := func(used ...uint64) []repository.DiskUsage {
+		var usages []repository.DiskUsage
+		for i, u := range used {
+			usages = append(usages, repository.DiskUsage{
+				CreatedAt:  start.Add(time.Duration(i) * time.Hour),
+				UsedBytes:  u,
+				AvailBytes: 1000 - u,
+			})
+		}
+		return usages
+	}
+
+	t.Run("growing usage", func(t *testing.T) {
+		// 100 bytes per hour, 600 bytes left.
+		timeToFull, ok := predictTimeToDiskFull(usagesOf(100, 200, 300, 400))
+		assert.True(t, ok)
+		assert.Equal(t, 6*time.Hour, timeToFull)
+	})
+
+	t.Run("not growing usage", func(t *testing.T) {
+		_, ok := predictTimeToDiskFull(usagesOf(400, 400, 400))
+		assert.False(t, ok)
+
+		_, ok = predictTimeToDiskFull(usagesOf(400, 300, 200))
+		assert.False(t, ok)
+	})
+}
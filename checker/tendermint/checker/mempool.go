@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"time"
+)
+
+func MempoolChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(mempoolFormatf("Starting: " + fn))
+
+	mempoolRepository := repository.MempoolRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.MempoolCheck == nil {
+			continue
+		}
+
+		mempools, err := mempoolRepository.FindLatestMempools(1, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(mempoolFormatf(err.Error())))
+			continue
+		}
+		if len(mempools) == 0 {
+			log.Debug(mempoolFormatf("No mempool found for this agent: %s", agentName))
+			continue
+		}
+
+		mempool := mempools[0]
+		if mempool.CreatedAt.Add(5 * time.Minute).Before(time.Now().UTC()) {
+			log.Warn(mempoolFormatf("Agent(%s)'s latest mempool is too old: %v (%s ago)", agentName, mempool.CreatedAt, time.Now().UTC().Sub(mempool.CreatedAt)))
+		}
+
+		if mempool.NTxs >= agentChecker.MempoolCheck.MaxTxCount || mempool.TotalBytes >= agentChecker.MempoolCheck.MaxTotalBytes {
+			var errorMsg = fmt.Sprintf("\nUnconfirmed Txs: %d (%d bytes)\nThresholdTxCount: %d\nThresholdTotalBytes: %d",
+				mempool.NTxs, mempool.TotalBytes, agentChecker.MempoolCheck.MaxTxCount, agentChecker.MempoolCheck.MaxTotalBytes)
+
+			sendAlert(c, client, agentName, MEMPOOL_FULL_TM_ALARM_TYPE, errorMsg, mempoolFormatf)
+		}
+
+		log.Debug(mempoolFormatf("Complete to check Agent: (%s). n_txs: %d", agentName, mempool.NTxs))
+	}
+}
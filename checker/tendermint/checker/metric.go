@@ -0,0 +1,107 @@
+package checker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"strings"
+	"time"
+)
+
+// metricNamespaces are the prefixes of the node's metrics, which thresholds may leave out as the prometheus monitor's allow-list does.
+var metricNamespaces = []string{"cometbft_", "tendermint_"}
+
+func MetricChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(metricFormatf("Starting: " + fn))
+
+	metricRepository := repository.MetricRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		for _, threshold := range agentChecker.MetricCheck {
+			metrics, err := findLatestMetrics(&metricRepository, threshold.Name, agentName)
+			if err != nil {
+				log.Error(errors.New(metricFormatf(err.Error())))
+				continue
+			}
+			if len(metrics) == 0 {
+				log.Debug(metricFormatf("No metric found for this agent: %s, name: %s", agentName, threshold.Name))
+				continue
+			}
+
+			for _, metric := range metrics {
+				if metric.CreatedAt.Add(5 * time.Minute).Before(time.Now().UTC()) {
+					log.Warn(metricFormatf("Agent(%s)'s latest metric is too old: %s, %v (%s ago)", agentName, metric.Name, metric.CreatedAt, time.Now().UTC().Sub(metric.CreatedAt)))
+				}
+
+				var labels map[string]string
+				if metric.Labels != "" {
+					err = json.Unmarshal([]byte(metric.Labels), &labels)
+					if err != nil {
+						log.Error(errors.New(metricFormatf("invalid labels: %s, %v", metric.Labels, err)))
+						continue
+					}
+				}
+				if !matchLabels(labels, threshold.Labels) {
+					continue
+				}
+
+				if (threshold.Min != nil && metric.Value < *threshold.Min) || (threshold.Max != nil && metric.Value > *threshold.Max) {
+					var errorMsg = fmt.Sprintf("\nMetric: %s%s\nValue: %g\nThreshold: [%s, %s]",
+						metric.Name, metric.Labels, metric.Value, formatBound(threshold.Min), formatBound(threshold.Max))
+
+					sendAlert(c, client, agentName, METRIC_TM_ALARM_TYPE, errorMsg, metricFormatf)
+				}
+			}
+		}
+
+		log.Debug(metricFormatf("Complete to check Agent: (%s). thresholds: %d", agentName, len(agentChecker.MetricCheck)))
+	}
+}
+
+// findLatestMetrics returns samples of the named series, or of the series the name without a namespace is of.
+func findLatestMetrics(metricRepository *repository.MetricRepository, name string, agentName types.AgentName) ([]repository.Metric, error) {
+	for _, candidate := range metricNames(name) {
+		metrics, err := metricRepository.FindLatestMetricsByName(candidate, string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil || len(metrics) > 0 {
+			return metrics, err
+		}
+	}
+	return nil, nil
+}
+
+// metricNames returns the name, followed by the name in each namespace unless it's already in one.
+func metricNames(name string) []string {
+	names := []string{name}
+	for _, namespace := range metricNamespaces {
+		if strings.HasPrefix(name, namespace) {
+			return names
+		}
+	}
+	for _, namespace := range metricNamespaces {
+		names = append(names, namespace+name)
+	}
+	return names
+}
+
+// matchLabels reports whether labels has every label of selector.
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func formatBound(bound *float64) string {
+	if bound == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%g", *bound)
+}
@@ -0,0 +1,19 @@
+package checker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMetricNames(t *testing.T) {
+
+	t.Run("name without a namespace", func(t *testing.T) {
+		assert.Equal(t, []string{"consensus_height", "cometbft_consensus_height", "tendermint_consensus_height"}, metricNames("consensus_height"))
+	})
+
+	t.Run("name in a namespace", func(t *testing.T) {
+		assert.Equal(t, []string{"cometbft_consensus_height"}, metricNames("cometbft_consensus_height"))
+		assert.Equal(t, []string{"tendermint_p2p_peers"}, metricNames("tendermint_p2p_peers"))
+	})
+
+}
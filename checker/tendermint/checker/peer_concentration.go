@@ -0,0 +1,130 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"sort"
+	"strings"
+)
+
+// minConcentrationPeers is how many located peers are needed at least to check their concentration.
+// With fewer peers, a single network always holds most of them, which the low peer alert already covers.
+const minConcentrationPeers = 3
+
+// PeerConcentrationChecker alerts when too many of the agent's located peers are in one ASN or provider.
+func PeerConcentrationChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(netInfoFormatf("Starting: " + fn))
+
+	netInfoRepository := repository.NetInfoRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.PeerCheck == nil || agentChecker.PeerCheck.MaxPeerConcentration <= 0 {
+			continue
+		}
+
+		peerNetworks, err := netInfoRepository.FindLatestPeerNetworks(string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(netInfoFormatf(err.Error())))
+			continue
+		}
+
+		concentration := peerConcentrationOf(peerNetworks)
+		if concentration.Located < minConcentrationPeers {
+			log.Debug(netInfoFormatf("Not enough located peers to check concentration of this agent: %s (%d)", agentName, concentration.Located))
+			continue
+		}
+
+		if concentration.Ratio() > agentChecker.PeerCheck.MaxPeerConcentration {
+			var errorMsg = fmt.Sprintf("\nPeers are concentrated in %s\nPeers: %d of %d located (%.0f%%)\nThreshold: %.0f%%",
+				concentration.Network, concentration.Peers, concentration.Located, concentration.Ratio()*100, agentChecker.PeerCheck.MaxPeerConcentration*100)
+
+			sendAlert(c, client, agentName, PEER_CONCENTRATION_TM_ALARM_TYPE, errorMsg, netInfoFormatf)
+		}
+
+		log.Debug(netInfoFormatf("Complete to check peer concentration of Agent: (%s).", agentName))
+	}
+}
+
+// peerConcentration is the network holding the most of the located peers.
+type peerConcentration struct {
+	// Network is an ASN(e.g. `AS16509 (AMAZON-02)`) or a provider(e.g. `provider amazon`).
+	Network string
+	Peers   int
+	Located int
+}
+
+func (p peerConcentration) Ratio() float64 {
+	if p.Located == 0 {
+		return 0
+	}
+	return float64(p.Peers) / float64(p.Located)
+}
+
+// peerConcentrationOf finds the ASN or provider holding the most of the located peers.
+// Providers are AS organizations grouped by providerOf, since a provider may operate several ASNs. Peers without ASN aren't counted.
+func peerConcentrationOf(peerNetworks []repository.PeerNetwork) peerConcentration {
+	var (
+		concentration peerConcentration
+		asns          = map[string]int{}
+		providers     = map[string]int{}
+	)
+	for _, network := range peerNetworks {
+		if network.ASN == 0 {
+			continue
+		}
+		concentration.Located += network.Peers
+		asns[fmt.Sprintf("AS%d (%s)", network.ASN, network.ASOrganization)] += network.Peers
+		if provider := providerOf(network.ASOrganization); provider != "" {
+			providers[provider] += network.Peers
+		}
+	}
+
+	for _, asn := range sortedKeys(asns) {
+		if asns[asn] > concentration.Peers {
+			concentration.Network, concentration.Peers = asn, asns[asn]
+		}
+	}
+	// Provider is reported only when it holds more than its largest ASN does.
+	for _, provider := range sortedKeys(providers) {
+		if providers[provider] > concentration.Peers {
+			concentration.Network, concentration.Peers = "provider "+provider, providers[provider]
+		}
+	}
+	return concentration
+}
+
+// organizationSuffixes are words which don't tell providers apart.
+var organizationSuffixes = map[string]bool{
+	"as": true, "asn": true, "co": true, "com": true, "corp": true, "corporation": true, "gmbh": true,
+	"inc": true, "limited": true, "llc": true, "ltd": true, "sa": true, "sas": true, "bv": true,
+}
+
+// providerOf normalizes the AS organization into a provider name, leaving out suffixes and numbers.
+// e.g. `Amazon.com, Inc.` and `AMAZON-02` into `amazon`
+func providerOf(organization string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(organization), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if organizationSuffixes[word] || strings.Trim(word, "0123456789") == "" {
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,37 @@
+package checker
+
+import (
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPeerConcentrationOf(t *testing.T) {
+
+	t.Run("concentrated in an asn", func(t *testing.T) {
+		concentration := peerConcentrationOf([]repository.PeerNetwork{
+			{ASN: 24940, ASOrganization: "Hetzner Online GmbH", Peers: 6},
+			{ASN: 16276, ASOrganization: "OVH SAS", Peers: 2},
+			{ASN: 0, Peers: 5},
+		})
+		assert.Equal(t, peerConcentration{Network: "AS24940 (Hetzner Online GmbH)", Peers: 6, Located: 8}, concentration)
+		assert.Equal(t, 0.75, concentration.Ratio())
+	})
+
+	t.Run("concentrated in a provider operating several asns", func(t *testing.T) {
+		concentration := peerConcentrationOf([]repository.PeerNetwork{
+			{ASN: 16509, ASOrganization: "AMAZON-02", Peers: 3},
+			{ASN: 14618, ASOrganization: "Amazon.com, Inc.", Peers: 3},
+			{ASN: 4134, ASOrganization: "CHINANET-BACKBONE", Peers: 2},
+			{ASN: 4837, ASOrganization: "CHINA UNICOM China169 Backbone", Peers: 2},
+		})
+		assert.Equal(t, peerConcentration{Network: "provider amazon", Peers: 6, Located: 10}, concentration)
+	})
+
+	t.Run("no located peer", func(t *testing.T) {
+		concentration := peerConcentrationOf([]repository.PeerNetwork{{ASN: 0, Peers: 10}})
+		assert.Equal(t, 0, concentration.Located)
+		assert.Equal(t, 0.0, concentration.Ratio())
+	})
+
+}
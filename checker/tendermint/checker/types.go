@@ -1,8 +1,11 @@
 package checker
 
 import (
+	"errors"
 	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/alarmer"
 	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	"github.com/b-harvest/Harvestmon/log"
 )
 
 const (
@@ -11,8 +14,51 @@ const (
 	HEIGHT_STUCK_TM_ALARM_TYPE  types.AlertName = TM_ALARM_TYPE + ":height_stuck"
 	LOW_PEER_TM_ALARM_TYPE      types.AlertName = TM_ALARM_TYPE + ":low_peer"
 	MISSING_BLOCK_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":missing_block"
+
+	CONSENSUS_ROUND_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":consensus_round"
+	MISSING_PREVOTE_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":missing_prevote"
+	MEMPOOL_FULL_TM_ALARM_TYPE    types.AlertName = TM_ALARM_TYPE + ":mempool_full"
+	APP_VERSION_TM_ALARM_TYPE     types.AlertName = TM_ALARM_TYPE + ":app_version_changed"
+	METRIC_TM_ALARM_TYPE          types.AlertName = TM_ALARM_TYPE + ":metric"
+
+	DISK_USAGE_TM_ALARM_TYPE    types.AlertName = TM_ALARM_TYPE + ":disk_usage"
+	DISK_FULL_TM_ALARM_TYPE     types.AlertName = TM_ALARM_TYPE + ":disk_full_prediction"
+	HOST_RESOURCE_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":host_resource"
+
+	GOV_VOTE_TM_ALARM_TYPE            types.AlertName = TM_ALARM_TYPE + ":gov_vote"
+	UPGRADE_REMINDER_TM_ALARM_TYPE    types.AlertName = TM_ALARM_TYPE + ":upgrade_reminder"
+	UPGRADE_OLD_VERSION_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":upgrade_old_version"
+
+	PEER_CONCENTRATION_TM_ALARM_TYPE types.AlertName = TM_ALARM_TYPE + ":peer_concentration"
 )
 
+// sendAlert passes the alert to every alarmer specified for the alert's level of the agent.
+func sendAlert(c *types.CheckerConfig, client *types.CheckerClient, agentName types.AgentName, alertName types.AlertName, errorMsg string, formatf func(string, ...any) string) {
+	var (
+		alertLevel types.AlertLevel
+		sent       bool
+	)
+
+	if alertLevelP := client.GetAlertLevel(agentName, string(alertName)); alertLevelP == nil {
+		log.Error(errors.New(formatf("alertLevel not found: %s", string(alertName))))
+	} else {
+		alertLevel = *alertLevelP
+	}
+
+	for _, a := range client.GetAlarmerList(agentName, alertLevel.AlertLevel) {
+		sent = true
+
+		// Pass to alarmer
+		err := alarmer.RunAlarm(c, *client, types.NewAlert(a, alertLevel, agentName, errorMsg))
+		if err != nil {
+			log.Error(errors.New(formatf("error occurred while sending alarm: %s, %v", alertName, err)))
+		}
+	}
+	if !sent {
+		log.Error(errors.New(formatf("Didn't send any alert cause of no alarmer specified for the level: %s, %s", alertName, alertLevel.AlertLevel)))
+	}
+}
+
 func netInfoFormatf(str string, args ...any) string {
 	return fmt.Sprintf("[net_info] "+str, args...)
 }
@@ -28,3 +74,31 @@ func heartbeatFormatf(str string, args ...any) string {
 func heightCheckFormatf(str string, args ...any) string {
 	return fmt.Sprintf("[height_check] "+str, args...)
 }
+
+func consensusStateFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[consensus_state] "+str, args...)
+}
+
+func mempoolFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[mempool] "+str, args...)
+}
+
+func abciInfoFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[abci_info] "+str, args...)
+}
+
+func metricFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[metric] "+str, args...)
+}
+
+func hostResourceFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[host_resource] "+str, args...)
+}
+
+func govFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[gov] "+str, args...)
+}
+
+func upgradeFormatf(str string, args ...any) string {
+	return fmt.Sprintf("[upgrade] "+str, args...)
+}
@@ -0,0 +1,106 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/checker/tendermint/types"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"time"
+)
+
+// UpgradeChecker reminds a scheduled upgrade at each stage of UpgradeCheck.RemindBefore,
+// and alerts when the node still runs the version it had before the upgrade height.
+func UpgradeChecker(c *types.CheckerConfig, client *types.CheckerClient) {
+	_, _, fn := util.TraceFirst()
+	log.Debug(upgradeFormatf("Starting: " + fn))
+
+	upgradeRepository := repository.UpgradeRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+	statusRepository := repository.StatusRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(), CommitId: c.CommitId}}
+
+	for agentName, agentChecker := range c.AgentCheckers {
+		if agentChecker.UpgradeCheck == nil {
+			continue
+		}
+
+		plan, err := upgradeRepository.FindLatestUpgradePlan(string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(upgradeFormatf(err.Error())))
+			continue
+		}
+		if plan == nil {
+			log.Debug(upgradeFormatf("No upgrade plan found for this agent: %s", agentName))
+			continue
+		}
+
+		now := time.Now().UTC()
+
+		if plan.Scheduled && plan.EstimatedTime != nil && plan.EstimatedTime.After(now) {
+			remindUpgrade(c, client, upgradeRepository, agentName, agentChecker.UpgradeCheck, *plan, now)
+		}
+
+		status, err := statusRepository.FindLatestNodeVersionStatus(string(agentName), _const.HARVESTMON_TENDERMINT_SERVICE_NAME)
+		if err != nil {
+			log.Error(errors.New(upgradeFormatf(err.Error())))
+			continue
+		}
+		if status == nil {
+			log.Debug(upgradeFormatf("No status found for this agent: %s", agentName))
+			continue
+		}
+
+		if upgradeHeightPassed(*plan, *status, now) && status.Version == plan.OldNodeVersion {
+			var errorMsg = fmt.Sprintf("\nPlan: %s (height: %d)\nCurrent height: %d\nNode version: %s (not upgraded)",
+				plan.Name, plan.Height, status.LatestBlockHeight, status.Version)
+
+			sendAlert(c, client, agentName, UPGRADE_OLD_VERSION_TM_ALARM_TYPE, errorMsg, upgradeFormatf)
+		}
+
+		log.Debug(upgradeFormatf("Complete to check Agent: (%s). plan: %s, height: %d, scheduled: %t", agentName, plan.Name, plan.Height, plan.Scheduled))
+	}
+}
+
+func remindUpgrade(c *types.CheckerConfig, client *types.CheckerClient, upgradeRepository repository.UpgradeRepository, agentName types.AgentName, upgradeCheck *types.UpgradeCheck, plan repository.UpgradePlanState, now time.Time) {
+	remaining := plan.EstimatedTime.Sub(now)
+	stage, ok := remindStage(remaining, upgradeCheck.RemindBefore)
+	if !ok {
+		return
+	}
+
+	exists, err := upgradeRepository.ExistsUpgradeReminder(string(agentName), plan.Name, plan.Height, stage)
+	if err != nil {
+		log.Error(errors.New(upgradeFormatf(err.Error())))
+		return
+	}
+	if exists {
+		return
+	}
+
+	var errorMsg = fmt.Sprintf("\nPlan: %s (height: %d)\nCurrent height: %d\nEstimated time: %s (in %s)",
+		plan.Name, plan.Height, plan.LatestBlockHeight, plan.EstimatedTime.Format(time.RFC3339), remaining.Round(time.Minute))
+
+	sendAlert(c, client, agentName, UPGRADE_REMINDER_TM_ALARM_TYPE, errorMsg, upgradeFormatf)
+
+	err = upgradeRepository.SaveUpgradeReminder(string(agentName), plan.Name, plan.Height, stage)
+	if err != nil {
+		log.Error(errors.New(upgradeFormatf(err.Error())))
+	}
+}
+
+// upgradeHeightPassed returns whether the node should have been upgraded.
+// An old binary halts right before the upgrade height, so the estimated time having passed at that height also counts.
+// A plan which disappeared while the node was far below its height is regarded as cancelled.
+func upgradeHeightPassed(plan repository.UpgradePlanState, status repository.NodeVersionStatus, now time.Time) bool {
+	height := uint64(plan.Height)
+
+	if !plan.Scheduled && plan.LatestBlockHeight+1 < height {
+		return false
+	}
+	if status.LatestBlockHeight >= height {
+		return true
+	}
+	return plan.Scheduled && status.LatestBlockHeight+1 >= height &&
+		plan.EstimatedTime != nil && now.After(*plan.EstimatedTime)
+}
@@ -0,0 +1,36 @@
+package checker
+
+import (
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUpgradeHeightPassed(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	t.Run("below the height", func(t *testing.T) {
+		plan := repository.UpgradePlanState{Height: 1000, LatestBlockHeight: 900, Scheduled: true, EstimatedTime: &future}
+		assert.False(t, upgradeHeightPassed(plan, repository.NodeVersionStatus{LatestBlockHeight: 950}, now))
+	})
+
+	t.Run("halted right before the height", func(t *testing.T) {
+		plan := repository.UpgradePlanState{Height: 1000, LatestBlockHeight: 999, Scheduled: true, EstimatedTime: &past}
+		assert.True(t, upgradeHeightPassed(plan, repository.NodeVersionStatus{LatestBlockHeight: 999}, now))
+
+		plan.EstimatedTime = &future
+		assert.False(t, upgradeHeightPassed(plan, repository.NodeVersionStatus{LatestBlockHeight: 999}, now))
+	})
+
+	t.Run("applied", func(t *testing.T) {
+		plan := repository.UpgradePlanState{Height: 1000, LatestBlockHeight: 999, Scheduled: false, EstimatedTime: &past}
+		assert.True(t, upgradeHeightPassed(plan, repository.NodeVersionStatus{LatestBlockHeight: 1010}, now))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		plan := repository.UpgradePlanState{Height: 1000, LatestBlockHeight: 500, Scheduled: false, EstimatedTime: &past}
+		assert.False(t, upgradeHeightPassed(plan, repository.NodeVersionStatus{LatestBlockHeight: 1010}, now))
+	})
+}
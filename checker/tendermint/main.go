@@ -20,8 +20,11 @@ import (
 )
 
 var (
-	err             error
-	client          *types.CheckerClient
+	err    error
+	client *types.CheckerClient
+
+	// baseCfg is the last valid checker rules. cfg merges custom agent checkers into a clone of it on every invocation.
+	baseCfg         = types.CheckerConfig{}
 	cfg             = types.CheckerConfig{}
 	alertDefinition = types.AlertDefinition{}
 	agentFilesPath  *string
@@ -29,26 +32,12 @@ var (
 )
 
 func init() {
-	var (
-		configBytes []byte
-	)
-
 	pwd, err = os.Getwd()
-	configBytes, err = os.ReadFile(filepath.Join(pwd, "resources/default_checker_rules.yaml"))
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = yaml.Unmarshal(configBytes, &cfg)
+	baseCfg, err = loadCheckerConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = cfg.ApplyConfigFromEnvAndDefault()
-	if err != nil {
-		log.Fatal(errors.New("Error occurred while parsing env. " + err.Error()))
-	}
-
 	// Parse default_alert_definition.yaml
 	customDefinition, err := types.ParseAlertDefinition()
 	if err != nil {
@@ -99,15 +88,60 @@ func main() {
 }
 
 var DefaultCheckerRegistry = map[string]types.Func{
-	"hearbeat":     checker.HeartbeatChecker,
-	"block_commit": checker.BlockCommitChecker,
-	"height_stuck": checker.HeightStuckChecker,
-	"net_info":     checker.NetInfoChecker,
+	"hearbeat":           checker.HeartbeatChecker,
+	"block_commit":       checker.BlockCommitChecker,
+	"height_stuck":       checker.HeightStuckChecker,
+	"net_info":           checker.NetInfoChecker,
+	"consensus_state":    checker.ConsensusStateChecker,
+	"mempool":            checker.MempoolChecker,
+	"abci_info":          checker.AbciInfoChecker,
+	"metric":             checker.MetricChecker,
+	"host_resource":      checker.HostResourceChecker,
+	"gov":                checker.GovChecker,
+	"upgrade":            checker.UpgradeChecker,
+	"peer_concentration": checker.PeerConcentrationChecker,
+}
+
+// loadCheckerConfig reads default checker rules and applies env and defaults to them.
+func loadCheckerConfig() (types.CheckerConfig, error) {
+	var checkerConfig types.CheckerConfig
+
+	configBytes, err := os.ReadFile(filepath.Join(pwd, "resources/default_checker_rules.yaml"))
+	if err != nil {
+		return checkerConfig, err
+	}
+
+	err = yaml.Unmarshal(configBytes, &checkerConfig)
+	if err != nil {
+		return checkerConfig, err
+	}
+
+	err = checkerConfig.ApplyConfigFromEnvAndDefault()
+	if err != nil {
+		return checkerConfig, errors.New("Error occurred while parsing env. " + err.Error())
+	}
+	return checkerConfig, nil
 }
 
 func handleAction() {
+	// Rules and alert definitions are read again on every invocation,
+	// so changed thresholds, alert levels and alarmers apply without redeploying a warm instance.
+	reloaded, err := loadCheckerConfig()
+	if err != nil {
+		log.Error(errors.New("Invalid checker rules. keeping the previous ones. err: " + err.Error()))
+	} else {
+		baseCfg = reloaded
+	}
+
+	reloadedDefinition, err := types.ParseAlertDefinition()
+	if err != nil {
+		log.Error(errors.New("Invalid alert definition. keeping the previous one. err: " + err.Error()))
+	} else {
+		alertDefinition = *reloadedDefinition
+	}
 
 	customAgentConfigs := types.GetCustomAgentFiles()
+	cfg = baseCfg.Clone()
 	cfg.MergeWithCustomAgentChecker(customAgentConfigs)
 
 	log.Info("Starting... Checker: " + _const.HARVESTMON_TENDERMINT_SERVICE_NAME + ", CommitID: " + cfg.CommitId)
@@ -27,9 +27,16 @@ type AgentChecker struct {
 	HeightCheck *HeightCheck `yaml:"heightCheck"`
 	// Heartbeat determine how long checker will wait for new event.
 	// It could be specifiable by events name(etc: `tm:event:net_info`: 1m)
-	Heartbeat   *map[string]*time.Duration `yaml:"heartbeat"`
-	PeerCheck   *PeerCheck                 `yaml:"peerCheck"`
-	CommitCheck *CommitCheck               `yaml:"commitCheck"`
+	Heartbeat      *map[string]*time.Duration `yaml:"heartbeat"`
+	PeerCheck      *PeerCheck                 `yaml:"peerCheck"`
+	CommitCheck    *CommitCheck               `yaml:"commitCheck"`
+	ConsensusCheck *ConsensusCheck            `yaml:"consensusCheck"`
+	MempoolCheck   *MempoolCheck              `yaml:"mempoolCheck"`
+	HostCheck      *HostCheck                 `yaml:"hostCheck"`
+	GovCheck       *GovCheck                  `yaml:"govCheck"`
+	UpgradeCheck   *UpgradeCheck              `yaml:"upgradeCheck"`
+	// MetricCheck are thresholds on the latest samples scraped by prometheus monitor.
+	MetricCheck []MetricThreshold `yaml:"metricCheck"`
 }
 
 const DefaultMaxWaitTimeKey = "maxWaitTime"
@@ -40,6 +47,174 @@ type HeightCheck struct {
 
 type PeerCheck struct {
 	LowPeerCount int `yaml:"lowPeerCount"`
+	// MaxPeerConcentration is how much of the located peers may be in one ASN or provider. (0 ~ 1)
+	// Peers concentrated in one network are an eclipse or centralization risk.
+	MaxPeerConcentration float64 `yaml:"maxPeerConcentration"`
+}
+
+// mergeDefault fills the fields an agent's block leaves unset with the default block's.
+func (check *PeerCheck) mergeDefault(defaultCheck *PeerCheck) {
+	if defaultCheck == nil {
+		return
+	}
+	if check.LowPeerCount == 0 {
+		check.LowPeerCount = defaultCheck.LowPeerCount
+	}
+	if check.MaxPeerConcentration == 0 {
+		check.MaxPeerConcentration = defaultCheck.MaxPeerConcentration
+	}
+}
+
+func (check *PeerCheck) validate() error {
+	if check.LowPeerCount <= 0 {
+		return errors.New("lowPeerCount must be greater than 0")
+	}
+	if check.MaxPeerConcentration <= 0 || check.MaxPeerConcentration > 1 {
+		return errors.New("maxPeerConcentration must be greater than 0 and at most 1")
+	}
+	return nil
+}
+
+type ConsensusCheck struct {
+	// MaxNonZeroRoundTime is how long the node may stay in round > 0.
+	MaxNonZeroRoundTime *time.Duration `yaml:"maxNonZeroRoundTime"`
+	// MaxMissingPrevoteCount is how many consecutive consensus states may miss the validator's prevote.
+	MaxMissingPrevoteCount int `yaml:"maxMissingPrevoteCount"`
+}
+
+// mergeDefault fills the fields an agent's block leaves unset with the default block's.
+func (check *ConsensusCheck) mergeDefault(defaultCheck *ConsensusCheck) {
+	if defaultCheck == nil {
+		return
+	}
+	if check.MaxNonZeroRoundTime == nil {
+		check.MaxNonZeroRoundTime = defaultCheck.MaxNonZeroRoundTime
+	}
+	if check.MaxMissingPrevoteCount == 0 {
+		check.MaxMissingPrevoteCount = defaultCheck.MaxMissingPrevoteCount
+	}
+}
+
+func (check *ConsensusCheck) validate() error {
+	if check.MaxNonZeroRoundTime == nil || *check.MaxNonZeroRoundTime <= 0 {
+		return errors.New("maxNonZeroRoundTime must be greater than 0")
+	}
+	if check.MaxMissingPrevoteCount <= 0 {
+		return errors.New("maxMissingPrevoteCount must be greater than 0")
+	}
+	return nil
+}
+
+type MempoolCheck struct {
+	// MaxTxCount is how many unconfirmed txs may be in the mempool. (CometBFT's default mempool size is 5000)
+	MaxTxCount int `yaml:"maxTxCount"`
+	// MaxTotalBytes is how many bytes unconfirmed txs may occupy. (CometBFT's default max_txs_bytes is 1GB)
+	MaxTotalBytes int64 `yaml:"maxTotalBytes"`
+}
+
+// mergeDefault fills the fields an agent's block leaves unset with the default block's.
+func (check *MempoolCheck) mergeDefault(defaultCheck *MempoolCheck) {
+	if defaultCheck == nil {
+		return
+	}
+	if check.MaxTxCount == 0 {
+		check.MaxTxCount = defaultCheck.MaxTxCount
+	}
+	if check.MaxTotalBytes == 0 {
+		check.MaxTotalBytes = defaultCheck.MaxTotalBytes
+	}
+}
+
+func (check *MempoolCheck) validate() error {
+	if check.MaxTxCount <= 0 {
+		return errors.New("maxTxCount must be greater than 0")
+	}
+	if check.MaxTotalBytes <= 0 {
+		return errors.New("maxTotalBytes must be greater than 0")
+	}
+	return nil
+}
+
+type HostCheck struct {
+	// MaxDiskUsageRatio is how much of a filesystem may be used. (0 ~ 1)
+	MaxDiskUsageRatio float64 `yaml:"maxDiskUsageRatio"`
+	// MinTimeToDiskFull alerts when a filesystem is predicted to be full sooner than this.
+	MinTimeToDiskFull *time.Duration `yaml:"minTimeToDiskFull"`
+	// DiskPredictionWindow is how long the disk usages are used for the prediction.
+	DiskPredictionWindow *time.Duration `yaml:"diskPredictionWindow"`
+	MaxMemoryUsageRatio  float64        `yaml:"maxMemoryUsageRatio"`
+	// MaxLoadPerCPU is the threshold of 5 minutes load average divided by the number of cpus.
+	MaxLoadPerCPU   float64 `yaml:"maxLoadPerCPU"`
+	MaxFdUsageRatio float64 `yaml:"maxFdUsageRatio"`
+}
+
+// mergeDefault fills the fields an agent's block leaves unset with the default block's.
+func (check *HostCheck) mergeDefault(defaultCheck *HostCheck) {
+	if defaultCheck == nil {
+		return
+	}
+	if check.MaxDiskUsageRatio == 0 {
+		check.MaxDiskUsageRatio = defaultCheck.MaxDiskUsageRatio
+	}
+	if check.MinTimeToDiskFull == nil {
+		check.MinTimeToDiskFull = defaultCheck.MinTimeToDiskFull
+	}
+	if check.DiskPredictionWindow == nil {
+		check.DiskPredictionWindow = defaultCheck.DiskPredictionWindow
+	}
+	if check.MaxMemoryUsageRatio == 0 {
+		check.MaxMemoryUsageRatio = defaultCheck.MaxMemoryUsageRatio
+	}
+	if check.MaxLoadPerCPU == 0 {
+		check.MaxLoadPerCPU = defaultCheck.MaxLoadPerCPU
+	}
+	if check.MaxFdUsageRatio == 0 {
+		check.MaxFdUsageRatio = defaultCheck.MaxFdUsageRatio
+	}
+}
+
+func (check *HostCheck) validate() error {
+	if check.MaxDiskUsageRatio <= 0 {
+		return errors.New("maxDiskUsageRatio must be greater than 0")
+	}
+	if check.MinTimeToDiskFull == nil || *check.MinTimeToDiskFull <= 0 {
+		return errors.New("minTimeToDiskFull must be greater than 0")
+	}
+	if check.DiskPredictionWindow == nil || *check.DiskPredictionWindow <= 0 {
+		return errors.New("diskPredictionWindow must be greater than 0")
+	}
+	if check.MaxMemoryUsageRatio <= 0 {
+		return errors.New("maxMemoryUsageRatio must be greater than 0")
+	}
+	if check.MaxLoadPerCPU <= 0 {
+		return errors.New("maxLoadPerCPU must be greater than 0")
+	}
+	if check.MaxFdUsageRatio <= 0 {
+		return errors.New("maxFdUsageRatio must be greater than 0")
+	}
+	return nil
+}
+
+type GovCheck struct {
+	// RemindBefore are the stages before voting end to remind a proposal the validator hasn't voted on.
+	// A proposal is reminded once per stage.
+	RemindBefore []time.Duration `yaml:"remindBefore"`
+}
+
+type UpgradeCheck struct {
+	// RemindBefore are the stages before the estimated upgrade time to remind a scheduled upgrade.
+	// An upgrade is reminded once per stage.
+	RemindBefore []time.Duration `yaml:"remindBefore"`
+}
+
+// MetricThreshold alerts when a sample of the series is out of [Min, Max].
+// Only samples having all of Labels are checked.
+type MetricThreshold struct {
+	// Name may leave out the namespace(`cometbft_`, `tendermint_`) as the prometheus monitor's allow-list does.
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels"`
+	Min    *float64          `yaml:"min"`
+	Max    *float64          `yaml:"max"`
 }
 
 type CommitCheck struct {
@@ -54,10 +229,29 @@ var (
 	EnvHeightMaxStuckTime        = "HEIGHT_MAX_STUCK_TIME"
 	EnvHeartbeatMaxWaitTime      = "HEARTBEAT_MAX_WAIT_TIME"
 	EnvLowPeerCount              = "LOW_PEER_COUNT"
+	EnvPeerCheckMaxConcentration = "PEER_CHECK_MAX_CONCENTRATION"
 	EnvCommitCheckValAddr        = "COMMIT_CHECK_VALIDATOR_ADDRESS"
 	EnvCommitCheckMaxMissingCnt  = "COMMIT_CHECK_MAX_MISSING_COUNT"
 	EnvCommitCheckTargetBlockCnt = "COMMIT_CHECK_TARGET_BLOCK_COUNT"
 
+	EnvConsensusCheckMaxNonZeroRoundTime    = "CONSENSUS_CHECK_MAX_NON_ZERO_ROUND_TIME"
+	EnvConsensusCheckMaxMissingPrevoteCount = "CONSENSUS_CHECK_MAX_MISSING_PREVOTE_COUNT"
+
+	EnvMempoolCheckMaxTxCount    = "MEMPOOL_CHECK_MAX_TX_COUNT"
+	EnvMempoolCheckMaxTotalBytes = "MEMPOOL_CHECK_MAX_TOTAL_BYTES"
+
+	EnvHostCheckMaxDiskUsageRatio    = "HOST_CHECK_MAX_DISK_USAGE_RATIO"
+	EnvHostCheckMinTimeToDiskFull    = "HOST_CHECK_MIN_TIME_TO_DISK_FULL"
+	EnvHostCheckDiskPredictionWindow = "HOST_CHECK_DISK_PREDICTION_WINDOW"
+	EnvHostCheckMaxMemoryUsageRatio  = "HOST_CHECK_MAX_MEMORY_USAGE_RATIO"
+	EnvHostCheckMaxLoadPerCPU        = "HOST_CHECK_MAX_LOAD_PER_CPU"
+	EnvHostCheckMaxFdUsageRatio      = "HOST_CHECK_MAX_FD_USAGE_RATIO"
+
+	// EnvGovCheckRemindBefore is comma separated durations. (e.g. `24h,6h,1h`)
+	EnvGovCheckRemindBefore = "GOV_CHECK_REMIND_BEFORE"
+	// EnvUpgradeCheckRemindBefore is comma separated durations. (e.g. `24h,1h,10m`)
+	EnvUpgradeCheckRemindBefore = "UPGRADE_CHECK_REMIND_BEFORE"
+
 	EnvGithubOwner  = "GITHUB_OWNER"
 	EnvGithubRepo   = "GITHUB_REPO"
 	EnvGithubBranch = "GITHUB_BRANCH"
@@ -74,8 +268,25 @@ var (
 	DefaultHeightMaxStuckTime        = 5 * time.Minute
 	DefaultHeartbeatMaxWaitTime      = 3 * time.Minute
 	DefaultLowPeerCount              = 5
+	DefaultPeerCheckMaxConcentration = 0.5
 	DefaultCommitCheckMaxMissingCnt  = 10
 	DefaultCommitCheckTargetBlockCnt = 50
+
+	DefaultConsensusCheckMaxNonZeroRoundTime    = 1 * time.Minute
+	DefaultConsensusCheckMaxMissingPrevoteCount = 5
+
+	DefaultMempoolCheckMaxTxCount          = 4000
+	DefaultMempoolCheckMaxTotalBytes int64 = 800 * 1024 * 1024
+
+	DefaultHostCheckMaxDiskUsageRatio    = 0.9
+	DefaultHostCheckMinTimeToDiskFull    = 24 * time.Hour
+	DefaultHostCheckDiskPredictionWindow = 6 * time.Hour
+	DefaultHostCheckMaxMemoryUsageRatio  = 0.9
+	DefaultHostCheckMaxLoadPerCPU        = 2.0
+	DefaultHostCheckMaxFdUsageRatio      = 0.8
+
+	DefaultGovCheckRemindBefore     = []time.Duration{24 * time.Hour, 6 * time.Hour, 1 * time.Hour}
+	DefaultUpgradeCheckRemindBefore = []time.Duration{24 * time.Hour, 1 * time.Hour, 10 * time.Minute}
 )
 
 // ApplyConfigFromEnvAndDefault will read the environmental variables into a config
@@ -138,23 +349,47 @@ func (cfg *CheckerConfig) ApplyConfigFromEnvAndDefault() error {
 		log.Debug("HeartbeatMaxWaitTime set as " + (*cfg.AgentCheckers[DEFAULT_AGENT_NAME].Heartbeat)[DefaultMaxWaitTimeKey].String())
 	}
 
-	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck == nil || cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount == 0 {
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck = &PeerCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount == 0 {
 		v := os.Getenv(EnvLowPeerCount)
 		if v == "" {
-			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck = &PeerCheck{LowPeerCount: DefaultLowPeerCount}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount = DefaultLowPeerCount
 			log.Debug("LowPeerCount set as default: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount))
 		} else {
 			lowPeerCount, err := strconv.Atoi(v)
 			if err != nil {
 				return errors.New(err.Error())
 			}
-			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck = &PeerCheck{LowPeerCount: lowPeerCount}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount = lowPeerCount
 			log.Debug("LowPeerCount set as ENV: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount))
 		}
 	} else {
 		log.Debug("LowPeerCount set as " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.LowPeerCount))
 	}
 
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration == 0 {
+		v := os.Getenv(EnvPeerCheckMaxConcentration)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration = DefaultPeerCheckMaxConcentration
+			log.Debug("MaxPeerConcentration set as default: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration, 'f', -1, 64))
+		} else {
+			maxPeerConcentration, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration = maxPeerConcentration
+			log.Debug("MaxPeerConcentration set as ENV: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration, 'f', -1, 64))
+		}
+	} else {
+		log.Debug("MaxPeerConcentration set as " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.MaxPeerConcentration, 'f', -1, 64))
+	}
+	if err := cfg.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck.validate(); err != nil {
+		return fmt.Errorf("peerCheck: %w", err)
+	}
+
 	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].CommitCheck == nil || (cfg.AgentCheckers[DEFAULT_AGENT_NAME].CommitCheck.ValidatorAddress == "" && os.Getenv(EnvCommitCheckValAddr) == "") {
 		cfg.AgentCheckers[DEFAULT_AGENT_NAME].CommitCheck = &CommitCheck{}
 		log.Debug("BlockCommit check feature will be disabled.")
@@ -200,6 +435,250 @@ func (cfg *CheckerConfig) ApplyConfigFromEnvAndDefault() error {
 		}
 	}
 
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck = &ConsensusCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime == nil {
+		v := os.Getenv(EnvConsensusCheckMaxNonZeroRoundTime)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime = &DefaultConsensusCheckMaxNonZeroRoundTime
+			log.Debug("ConsensusMaxNonZeroRoundTime set as default: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime.String())
+		} else {
+			maxNonZeroRoundTime, err := parseEnvDuration(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime = &maxNonZeroRoundTime
+			log.Debug("ConsensusMaxNonZeroRoundTime set as ENV: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime.String())
+		}
+	} else {
+		log.Debug("ConsensusMaxNonZeroRoundTime set as " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxNonZeroRoundTime.String())
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount == 0 {
+		v := os.Getenv(EnvConsensusCheckMaxMissingPrevoteCount)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount = DefaultConsensusCheckMaxMissingPrevoteCount
+			log.Debug("ConsensusMaxMissingPrevoteCount set as default: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount))
+		} else {
+			maxMissingPrevoteCount, err := strconv.Atoi(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount = maxMissingPrevoteCount
+			log.Debug("ConsensusMaxMissingPrevoteCount set as ENV: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount))
+		}
+	} else {
+		log.Debug("ConsensusMaxMissingPrevoteCount set as " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.MaxMissingPrevoteCount))
+	}
+	err := cfg.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck.validate()
+	if err != nil {
+		return fmt.Errorf("consensusCheck: %w", err)
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck = &MempoolCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount == 0 {
+		v := os.Getenv(EnvMempoolCheckMaxTxCount)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount = DefaultMempoolCheckMaxTxCount
+			log.Debug("MempoolMaxTxCount set as default: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount))
+		} else {
+			maxTxCount, err := strconv.Atoi(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount = maxTxCount
+			log.Debug("MempoolMaxTxCount set as ENV: " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount))
+		}
+	} else {
+		log.Debug("MempoolMaxTxCount set as " + strconv.Itoa(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTxCount))
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes == 0 {
+		v := os.Getenv(EnvMempoolCheckMaxTotalBytes)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes = DefaultMempoolCheckMaxTotalBytes
+			log.Debug("MempoolMaxTotalBytes set as default: " + strconv.FormatInt(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes, 10))
+		} else {
+			maxTotalBytes, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes = maxTotalBytes
+			log.Debug("MempoolMaxTotalBytes set as ENV: " + strconv.FormatInt(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes, 10))
+		}
+	} else {
+		log.Debug("MempoolMaxTotalBytes set as " + strconv.FormatInt(cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.MaxTotalBytes, 10))
+	}
+	err = cfg.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck.validate()
+	if err != nil {
+		return fmt.Errorf("mempoolCheck: %w", err)
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck = &HostCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio == 0 {
+		v := os.Getenv(EnvHostCheckMaxDiskUsageRatio)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio = DefaultHostCheckMaxDiskUsageRatio
+			log.Debug("HostMaxDiskUsageRatio set as default: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio, 'f', -1, 64))
+		} else {
+			maxDiskUsageRatio, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio = maxDiskUsageRatio
+			log.Debug("HostMaxDiskUsageRatio set as ENV: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio, 'f', -1, 64))
+		}
+	} else {
+		log.Debug("HostMaxDiskUsageRatio set as " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio, 'f', -1, 64))
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull == nil {
+		v := os.Getenv(EnvHostCheckMinTimeToDiskFull)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull = &DefaultHostCheckMinTimeToDiskFull
+			log.Debug("HostMinTimeToDiskFull set as default: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull.String())
+		} else {
+			minTimeToDiskFull, err := parseEnvDuration(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull = &minTimeToDiskFull
+			log.Debug("HostMinTimeToDiskFull set as ENV: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull.String())
+		}
+	} else {
+		log.Debug("HostMinTimeToDiskFull set as " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MinTimeToDiskFull.String())
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow == nil {
+		v := os.Getenv(EnvHostCheckDiskPredictionWindow)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow = &DefaultHostCheckDiskPredictionWindow
+			log.Debug("HostDiskPredictionWindow set as default: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow.String())
+		} else {
+			diskPredictionWindow, err := parseEnvDuration(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow = &diskPredictionWindow
+			log.Debug("HostDiskPredictionWindow set as ENV: " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow.String())
+		}
+	} else {
+		log.Debug("HostDiskPredictionWindow set as " + cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.DiskPredictionWindow.String())
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio == 0 {
+		v := os.Getenv(EnvHostCheckMaxMemoryUsageRatio)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio = DefaultHostCheckMaxMemoryUsageRatio
+			log.Debug("HostMaxMemoryUsageRatio set as default: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio, 'f', -1, 64))
+		} else {
+			maxMemoryUsageRatio, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio = maxMemoryUsageRatio
+			log.Debug("HostMaxMemoryUsageRatio set as ENV: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio, 'f', -1, 64))
+		}
+	} else {
+		log.Debug("HostMaxMemoryUsageRatio set as " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxMemoryUsageRatio, 'f', -1, 64))
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU == 0 {
+		v := os.Getenv(EnvHostCheckMaxLoadPerCPU)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU = DefaultHostCheckMaxLoadPerCPU
+			log.Debug("HostMaxLoadPerCPU set as default: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU, 'f', -1, 64))
+		} else {
+			maxLoadPerCPU, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU = maxLoadPerCPU
+			log.Debug("HostMaxLoadPerCPU set as ENV: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU, 'f', -1, 64))
+		}
+	} else {
+		log.Debug("HostMaxLoadPerCPU set as " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxLoadPerCPU, 'f', -1, 64))
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio == 0 {
+		v := os.Getenv(EnvHostCheckMaxFdUsageRatio)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio = DefaultHostCheckMaxFdUsageRatio
+			log.Debug("HostMaxFdUsageRatio set as default: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio, 'f', -1, 64))
+		} else {
+			maxFdUsageRatio, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio = maxFdUsageRatio
+			log.Debug("HostMaxFdUsageRatio set as ENV: " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio, 'f', -1, 64))
+		}
+	} else {
+		log.Debug("HostMaxFdUsageRatio set as " + strconv.FormatFloat(cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxFdUsageRatio, 'f', -1, 64))
+	}
+	err = cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.validate()
+	if err != nil {
+		return fmt.Errorf("hostCheck: %w", err)
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck = &GovCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore == nil {
+		v := os.Getenv(EnvGovCheckRemindBefore)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore = DefaultGovCheckRemindBefore
+			log.Debug(fmt.Sprintf("GovRemindBefore set as default: %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore))
+		} else {
+			var remindBefore []time.Duration
+			for _, s := range strings.Split(v, ",") {
+				d, err := parseEnvDuration(strings.TrimSpace(s))
+				if err != nil {
+					return errors.New(err.Error())
+				}
+				remindBefore = append(remindBefore, d)
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore = remindBefore
+			log.Debug(fmt.Sprintf("GovRemindBefore set as ENV: %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore))
+		}
+	} else {
+		log.Debug(fmt.Sprintf("GovRemindBefore set as %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck.RemindBefore))
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck == nil {
+		cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck = &UpgradeCheck{}
+	}
+
+	if cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore == nil {
+		v := os.Getenv(EnvUpgradeCheckRemindBefore)
+		if v == "" {
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore = DefaultUpgradeCheckRemindBefore
+			log.Debug(fmt.Sprintf("UpgradeRemindBefore set as default: %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore))
+		} else {
+			var remindBefore []time.Duration
+			for _, s := range strings.Split(v, ",") {
+				d, err := parseEnvDuration(strings.TrimSpace(s))
+				if err != nil {
+					return errors.New(err.Error())
+				}
+				remindBefore = append(remindBefore, d)
+			}
+			cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore = remindBefore
+			log.Debug(fmt.Sprintf("UpgradeRemindBefore set as ENV: %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore))
+		}
+	} else {
+		log.Debug(fmt.Sprintf("UpgradeRemindBefore set as %v", cfg.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck.RemindBefore))
+	}
+
 	if cfg.CommitId == "" {
 		v := os.Getenv(EnvCommitId)
 		if v == "" {
@@ -250,7 +729,58 @@ func GetCustomAgentFiles() []CustomAgentConfig {
 	return agentConfigs
 }
 
+// Clone returns a copy of the config whose agent checkers and their check blocks are copied,
+// so merging custom agent checkers into it leaves c intact.
+func (c CheckerConfig) Clone() CheckerConfig {
+	clone := c
+	clone.AgentCheckers = make(map[AgentName]*AgentChecker, len(c.AgentCheckers))
+	for agentName, agentChecker := range c.AgentCheckers {
+		clone.AgentCheckers[agentName] = agentChecker.clone()
+	}
+	return clone
+}
+
+func (a *AgentChecker) clone() *AgentChecker {
+	if a == nil {
+		return nil
+	}
+	clone := AgentChecker{
+		HeightCheck:    clonePointer(a.HeightCheck),
+		PeerCheck:      clonePointer(a.PeerCheck),
+		CommitCheck:    clonePointer(a.CommitCheck),
+		ConsensusCheck: clonePointer(a.ConsensusCheck),
+		MempoolCheck:   clonePointer(a.MempoolCheck),
+		HostCheck:      clonePointer(a.HostCheck),
+		GovCheck:       clonePointer(a.GovCheck),
+		UpgradeCheck:   clonePointer(a.UpgradeCheck),
+		MetricCheck:    append([]MetricThreshold(nil), a.MetricCheck...),
+	}
+	if a.Heartbeat != nil {
+		heartbeat := make(map[string]*time.Duration, len(*a.Heartbeat))
+		for eventType, maxWaitTime := range *a.Heartbeat {
+			heartbeat[eventType] = maxWaitTime
+		}
+		clone.Heartbeat = &heartbeat
+	}
+	return &clone
+}
+
+// clonePointer copies the value p points to. Pointers within it are shared, since merging only replaces them.
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// MergeWithCustomAgentChecker sets checkers of custom agents, filling what they leave out with the default one, then removes the default one.
+// It's done on a Clone of the config, since the config can't be merged again afterwards.
 func (c *CheckerConfig) MergeWithCustomAgentChecker(agentConfigs []CustomAgentConfig) {
+	if c.AgentCheckers[DEFAULT_AGENT_NAME] == nil {
+		log.Error(errors.New("no default agent checker to merge custom agent checkers with"))
+		return
+	}
 
 	for _, agentConfig := range agentConfigs {
 		if c.AgentCheckers[agentConfig.AgentName] == nil {
@@ -270,6 +800,48 @@ func (c *CheckerConfig) MergeWithCustomAgentChecker(agentConfigs []CustomAgentCo
 			}
 			if agentConfig.AgentChecker.PeerCheck == nil {
 				c.AgentCheckers[agentConfig.AgentName].PeerCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck
+			} else {
+				agentConfig.AgentChecker.PeerCheck.mergeDefault(c.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck)
+				if err := agentConfig.AgentChecker.PeerCheck.validate(); err != nil {
+					log.Error(fmt.Errorf("invalid peerCheck of agent(%s). the default one is used instead: %w", agentConfig.AgentName, err))
+					c.AgentCheckers[agentConfig.AgentName].PeerCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].PeerCheck
+				}
+			}
+			if agentConfig.AgentChecker.ConsensusCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].ConsensusCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck
+			} else {
+				agentConfig.AgentChecker.ConsensusCheck.mergeDefault(c.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck)
+				if err := agentConfig.AgentChecker.ConsensusCheck.validate(); err != nil {
+					log.Error(fmt.Errorf("invalid consensusCheck of agent(%s). the default one is used instead: %w", agentConfig.AgentName, err))
+					c.AgentCheckers[agentConfig.AgentName].ConsensusCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].ConsensusCheck
+				}
+			}
+			if agentConfig.AgentChecker.MempoolCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].MempoolCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck
+			} else {
+				agentConfig.AgentChecker.MempoolCheck.mergeDefault(c.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck)
+				if err := agentConfig.AgentChecker.MempoolCheck.validate(); err != nil {
+					log.Error(fmt.Errorf("invalid mempoolCheck of agent(%s). the default one is used instead: %w", agentConfig.AgentName, err))
+					c.AgentCheckers[agentConfig.AgentName].MempoolCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].MempoolCheck
+				}
+			}
+			if agentConfig.AgentChecker.HostCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].HostCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck
+			} else {
+				agentConfig.AgentChecker.HostCheck.mergeDefault(c.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck)
+				if err := agentConfig.AgentChecker.HostCheck.validate(); err != nil {
+					log.Error(fmt.Errorf("invalid hostCheck of agent(%s). the default one is used instead: %w", agentConfig.AgentName, err))
+					c.AgentCheckers[agentConfig.AgentName].HostCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck
+				}
+			}
+			if agentConfig.AgentChecker.GovCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].GovCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].GovCheck
+			}
+			if agentConfig.AgentChecker.UpgradeCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].UpgradeCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].UpgradeCheck
+			}
+			if agentConfig.AgentChecker.MetricCheck == nil {
+				c.AgentCheckers[agentConfig.AgentName].MetricCheck = c.AgentCheckers[DEFAULT_AGENT_NAME].MetricCheck
 			}
 		} else {
 			c.AgentCheckers[agentConfig.AgentName] = c.AgentCheckers[DEFAULT_AGENT_NAME]
@@ -413,14 +985,17 @@ func ParseAlertDefinition() (*AlertDefinition, error) {
 	)
 
 	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
 	defaultAlertBytes, err = os.ReadFile(filepath.Join(pwd, "resources/default_alert_definition.yaml"))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	err = yaml.Unmarshal(defaultAlertBytes, &defaultAlert)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	var (
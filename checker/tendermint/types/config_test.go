@@ -0,0 +1,152 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMergeWithCustomAgentChecker(t *testing.T) {
+
+	var (
+		agentName            AgentName = "test-agent"
+		maxNonZeroRoundTime            = 10 * time.Minute
+		minTimeToDiskFull              = 24 * time.Hour
+		diskPredictionWindow           = 6 * time.Hour
+	)
+
+	newConfig := func() CheckerConfig {
+		return CheckerConfig{AgentCheckers: map[AgentName]*AgentChecker{
+			DEFAULT_AGENT_NAME: {
+				ConsensusCheck: &ConsensusCheck{MaxNonZeroRoundTime: &maxNonZeroRoundTime, MaxMissingPrevoteCount: 5},
+				MempoolCheck:   &MempoolCheck{MaxTxCount: 4000, MaxTotalBytes: 1024},
+				PeerCheck:      &PeerCheck{LowPeerCount: 3, MaxPeerConcentration: 0.5},
+				HostCheck: &HostCheck{
+					MaxDiskUsageRatio:    0.9,
+					MinTimeToDiskFull:    &minTimeToDiskFull,
+					DiskPredictionWindow: &diskPredictionWindow,
+					MaxMemoryUsageRatio:  0.9,
+					MaxLoadPerCPU:        2,
+					MaxFdUsageRatio:      0.8,
+				},
+			},
+		}}
+	}
+
+	t.Run("partial consensus check is filled with defaults", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{ConsensusCheck: &ConsensusCheck{MaxMissingPrevoteCount: 3}}},
+		})
+
+		consensusCheck := cfg.AgentCheckers[agentName].ConsensusCheck
+		assert.Equal(t, maxNonZeroRoundTime, *consensusCheck.MaxNonZeroRoundTime)
+		assert.Equal(t, 3, consensusCheck.MaxMissingPrevoteCount)
+	})
+
+	t.Run("invalid consensus check falls back to the default", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{ConsensusCheck: &ConsensusCheck{MaxMissingPrevoteCount: -1}}},
+		})
+
+		consensusCheck := cfg.AgentCheckers[agentName].ConsensusCheck
+		assert.Equal(t, maxNonZeroRoundTime, *consensusCheck.MaxNonZeroRoundTime)
+		assert.Equal(t, 5, consensusCheck.MaxMissingPrevoteCount)
+	})
+
+	t.Run("partial host check is filled with defaults", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{HostCheck: &HostCheck{MaxDiskUsageRatio: 0.95}}},
+		})
+
+		hostCheck := cfg.AgentCheckers[agentName].HostCheck
+		assert.Equal(t, 0.95, hostCheck.MaxDiskUsageRatio)
+		assert.Equal(t, minTimeToDiskFull, *hostCheck.MinTimeToDiskFull)
+		assert.Equal(t, diskPredictionWindow, *hostCheck.DiskPredictionWindow)
+		assert.Equal(t, 2.0, hostCheck.MaxLoadPerCPU)
+	})
+
+	t.Run("invalid host check falls back to the default", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{HostCheck: &HostCheck{MaxFdUsageRatio: -0.5}}},
+		})
+
+		assert.Equal(t, 0.8, cfg.AgentCheckers[agentName].HostCheck.MaxFdUsageRatio)
+	})
+
+	t.Run("partial mempool check is filled with defaults", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{MempoolCheck: &MempoolCheck{MaxTxCount: 100}}},
+		})
+
+		mempoolCheck := cfg.AgentCheckers[agentName].MempoolCheck
+		assert.Equal(t, 100, mempoolCheck.MaxTxCount)
+		assert.Equal(t, int64(1024), mempoolCheck.MaxTotalBytes)
+	})
+
+	t.Run("invalid mempool check falls back to the default", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{MempoolCheck: &MempoolCheck{MaxTotalBytes: -1}}},
+		})
+
+		assert.Equal(t, MempoolCheck{MaxTxCount: 4000, MaxTotalBytes: 1024}, *cfg.AgentCheckers[agentName].MempoolCheck)
+	})
+
+	t.Run("partial peer check is filled with defaults", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{PeerCheck: &PeerCheck{LowPeerCount: 10}}},
+		})
+
+		assert.Equal(t, PeerCheck{LowPeerCount: 10, MaxPeerConcentration: 0.5}, *cfg.AgentCheckers[agentName].PeerCheck)
+	})
+
+	t.Run("invalid peer check falls back to the default", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{
+			{AgentName: agentName, AgentChecker: &AgentChecker{PeerCheck: &PeerCheck{MaxPeerConcentration: 1.5}}},
+		})
+
+		assert.Equal(t, PeerCheck{LowPeerCount: 3, MaxPeerConcentration: 0.5}, *cfg.AgentCheckers[agentName].PeerCheck)
+	})
+
+	t.Run("clone is merged without touching the config", func(t *testing.T) {
+		cfg := newConfig()
+		for i := 0; i < 2; i++ {
+			merged := cfg.Clone()
+			merged.MergeWithCustomAgentChecker([]CustomAgentConfig{
+				{AgentName: agentName, AgentChecker: &AgentChecker{HostCheck: &HostCheck{MaxDiskUsageRatio: 0.95}}},
+			})
+			assert.Equal(t, 0.95, merged.AgentCheckers[agentName].HostCheck.MaxDiskUsageRatio)
+			assert.NotContains(t, merged.AgentCheckers, DEFAULT_AGENT_NAME)
+		}
+
+		assert.Len(t, cfg.AgentCheckers, 1)
+		assert.Equal(t, 0.9, cfg.AgentCheckers[DEFAULT_AGENT_NAME].HostCheck.MaxDiskUsageRatio)
+	})
+
+	t.Run("config without the default isn't merged", func(t *testing.T) {
+		cfg := CheckerConfig{AgentCheckers: map[AgentName]*AgentChecker{}}
+		assert.NotPanics(t, func() {
+			cfg.MergeWithCustomAgentChecker([]CustomAgentConfig{{AgentName: agentName}})
+		})
+	})
+
+}
+
+func TestConsensusCheckValidate(t *testing.T) {
+	var (
+		maxNonZeroRoundTime = time.Minute
+		zero                = time.Duration(0)
+	)
+
+	assert.NoError(t, (&ConsensusCheck{MaxNonZeroRoundTime: &maxNonZeroRoundTime, MaxMissingPrevoteCount: 1}).validate())
+	assert.Error(t, (&ConsensusCheck{MaxMissingPrevoteCount: 1}).validate())
+	assert.Error(t, (&ConsensusCheck{MaxNonZeroRoundTime: &zero, MaxMissingPrevoteCount: 1}).validate())
+	assert.Error(t, (&ConsensusCheck{MaxNonZeroRoundTime: &maxNonZeroRoundTime, MaxMissingPrevoteCount: 0}).validate())
+}
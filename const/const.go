@@ -6,4 +6,14 @@ const (
 	TM_STATUS_EVENT_TYPE               = TM_EVENT_TYPE + ":status"
 	TM_NET_INFO_EVENT_TYPE             = TM_EVENT_TYPE + ":net_info"
 	TM_COMMIT_EVENT_TYPE               = TM_EVENT_TYPE + ":commit"
+	TM_VALIDATORS_EVENT_TYPE           = TM_EVENT_TYPE + ":validators"
+	TM_CONSENSUS_STATE_EVENT_TYPE      = TM_EVENT_TYPE + ":consensus_state"
+	TM_MEMPOOL_EVENT_TYPE              = TM_EVENT_TYPE + ":mempool"
+	TM_ABCI_INFO_EVENT_TYPE            = TM_EVENT_TYPE + ":abci_info"
+	TM_BLOCK_RESULTS_EVENT_TYPE        = TM_EVENT_TYPE + ":block_results"
+	TM_METRIC_EVENT_TYPE               = TM_EVENT_TYPE + ":metric"
+	TM_HOST_RESOURCE_EVENT_TYPE        = TM_EVENT_TYPE + ":host_resource"
+	TM_COSMOS_VALIDATOR_EVENT_TYPE     = TM_EVENT_TYPE + ":cosmos_validator"
+	TM_GOV_EVENT_TYPE                  = TM_EVENT_TYPE + ":gov"
+	TM_UPGRADE_EVENT_TYPE              = TM_EVENT_TYPE + ":upgrade"
 )
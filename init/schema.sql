@@ -1,4 +1,20 @@
 -- Drop tables if they exist
+DROP TABLE IF EXISTS block_commit_backfill;
+DROP TABLE IF EXISTS upgrade_reminder;
+DROP TABLE IF EXISTS cosmos_upgrade_plan;
+DROP TABLE IF EXISTS gov_vote_reminder;
+DROP TABLE IF EXISTS cosmos_proposal;
+DROP TABLE IF EXISTS cosmos_validator;
+DROP TABLE IF EXISTS disk_usage;
+DROP TABLE IF EXISTS host_resource;
+DROP TABLE IF EXISTS metric;
+DROP TABLE IF EXISTS tendermint_block_event;
+DROP TABLE IF EXISTS tendermint_block_results;
+DROP TABLE IF EXISTS tendermint_abci_info;
+DROP TABLE IF EXISTS tendermint_mempool;
+DROP TABLE IF EXISTS tendermint_consensus_state;
+DROP TABLE IF EXISTS tendermint_validator;
+DROP TABLE IF EXISTS tendermint_validator_set;
 DROP TABLE IF EXISTS tendermint_commit_signature_list;
 DROP TABLE IF EXISTS tendermint_commit;
 DROP TABLE IF EXISTS tendermint_status;
@@ -27,10 +43,11 @@ CREATE TABLE `agent` (
     `agent_name`	varchar(100)	NOT NULL,
     `commit_id`	varchar(255)	NOT NULL,
 
-    `host`	varchar(30)	NOT NULL,
+    `host`	varchar(255)	NOT NULL,
     `port` int NULL,
     `platform`	varchar(255)	NULL,
-    `location`	varchar(255)	NULL
+    `location`	varchar(255)	NULL,
+    `active`	BOOLEAN	NOT NULL	DEFAULT TRUE
 );
 
 CREATE TABLE `service` (
@@ -49,7 +66,8 @@ CREATE TABLE `event` (
     `commit_id`	varchar(255)	NOT NULL,
 
     `event_type`	varchar(100)	NULL,
-    `created_at`	timestamp(6)	NULL
+    `created_at`	timestamp(6)	NULL,
+    `rpc_endpoint`	varchar(255)	NULL
 );
 
 CREATE TABLE `commit_record` (
@@ -114,7 +132,8 @@ CREATE TABLE `tendermint_node_info` (
     `node_id`	varchar(100)	NULL,
     `listen_addr`	varchar(255)	NULL,
     `chain_id`	varchar(20)	NULL,
-    `moniker`	varchar(50)	NULL
+    `moniker`	varchar(50)	NULL,
+    `version`	varchar(50)	NULL
 );
 
 CREATE TABLE `tendermint_peer_info` (
@@ -125,7 +144,22 @@ CREATE TABLE `tendermint_peer_info` (
 
     `is_outbound`	Bool	NULL,
     `tendermint_node_info_uuid`	UUID	NOT NULL,
-    `remote_ip`	varchar(50)	NULL
+    `remote_ip`	varchar(50)	NULL,
+
+    `connection_duration`	BigInt	NULL,
+    `send_bytes`	BigInt	NULL,
+    `recv_bytes`	BigInt	NULL,
+    `send_rate`	BigInt	NULL,
+    `recv_rate`	BigInt	NULL,
+    `send_queue_size`	Int	NULL,
+    `send_queue_capacity`	Int	NULL,
+    `send_queue_saturation`	Double	NULL,
+
+    `country_code`	varchar(2)	NULL,
+    `country`	varchar(100)	NULL,
+    `city`	varchar(100)	NULL,
+    `asn`	Int unsigned	NULL,
+    `as_organization`	varchar(255)	NULL
 );
 
 CREATE TABLE `tendermint_commit_signature_list` (
@@ -138,6 +172,204 @@ CREATE TABLE `tendermint_commit_signature_list` (
     `block_id_flag`	Int	NOT NULL
 );
 
+CREATE TABLE `tendermint_validator_set` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `chain_id`	varchar(20)	NULL,
+    `height`	BigInt	NULL,
+    `validators_hash`	varchar(100)	NULL,
+    `total`	Int	NULL,
+    `total_voting_power`	BigInt	NULL
+);
+
+CREATE TABLE `tendermint_validator` (
+    `address`	varchar(100)	NOT NULL,
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `pub_key_type`	varchar(100)	NULL,
+    `pub_key`	varchar(200)	NULL,
+    `voting_power`	BigInt	NULL,
+    `proposer_priority`	BigInt	NULL
+);
+
+CREATE TABLE `tendermint_consensus_state` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `height`	BigInt	NULL,
+    `round`	Int	NULL,
+    `step`	Int	NULL,
+    `start_time`	timestamp(6)	NULL,
+    `validator_address`	varchar(100)	NULL,
+    `prevoted`	Bool	NULL,
+    `precommitted`	Bool	NULL,
+    `prevotes_ratio`	Double	NULL,
+    `precommits_ratio`	Double	NULL
+);
+
+CREATE TABLE `tendermint_mempool` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `n_txs`	Int	NULL,
+    `total`	Int	NULL,
+    `total_bytes`	BigInt	NULL
+);
+
+CREATE TABLE `tendermint_abci_info` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `app_name`	varchar(100)	NULL,
+    `version`	varchar(100)	NULL,
+    `app_version`	BigInt	NULL,
+    `last_block_height`	BigInt	NULL,
+    `last_block_app_hash`	varchar(100)	NULL
+);
+
+CREATE TABLE `tendermint_block_results` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `height`	BigInt	NULL,
+    `tx_count`	Int	NULL,
+    `failed_tx_count`	Int	NULL,
+    `gas_wanted`	BigInt	NULL,
+    `gas_used`	BigInt	NULL
+);
+
+CREATE TABLE `tendermint_block_event` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+    `event_index`	Int	NOT NULL,
+
+    `stage`	varchar(20)	NULL,
+    `event_type`	varchar(100)	NULL,
+    `attributes`	Text	NULL
+);
+
+CREATE TABLE `metric` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+    `metric_index`	Int	NOT NULL,
+
+    `name`	varchar(255)	NOT NULL,
+    `labels`	Text	NULL,
+    `value`	Double	NULL
+);
+
+CREATE TABLE `host_resource` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `mem_total_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `mem_avail_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `swap_total_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `swap_free_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `load1`	Double	NOT NULL,
+    `load5`	Double	NOT NULL,
+    `load15`	Double	NOT NULL,
+    `num_cpu`	Int	NOT NULL,
+    `open_fds`	BIGINT UNSIGNED	NOT NULL,
+    `max_fds`	BIGINT UNSIGNED	NOT NULL
+);
+
+CREATE TABLE `disk_usage` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+    `path`	varchar(255)	NOT NULL,
+
+    `mount_point`	varchar(255)	NOT NULL,
+    `device`	varchar(255)	NULL,
+    `fs_type`	varchar(50)	NULL,
+    `total_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `used_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `avail_bytes`	BIGINT UNSIGNED	NOT NULL,
+    `total_inodes`	BIGINT UNSIGNED	NOT NULL,
+    `free_inodes`	BIGINT UNSIGNED	NOT NULL
+);
+
+CREATE TABLE `cosmos_validator` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `operator_address`	varchar(100)	NOT NULL,
+    `consensus_address`	varchar(100)	NOT NULL,
+    `status`	varchar(30)	NOT NULL,
+    `jailed`	Bool	NOT NULL,
+    `tombstoned`	Bool	NOT NULL,
+    `jailed_until`	datetime(6)	NOT NULL,
+    `tokens`	varchar(100)	NOT NULL,
+    `delegator_shares`	varchar(100)	NOT NULL,
+    `commission_rate`	varchar(30)	NOT NULL,
+    `commission_max_rate`	varchar(30)	NOT NULL,
+    `commission_max_change_rate`	varchar(30)	NOT NULL,
+    `start_height`	BIGINT	NOT NULL,
+    `index_offset`	BIGINT	NOT NULL,
+    `missed_blocks_counter`	BIGINT	NOT NULL,
+    `signed_blocks_window`	BIGINT	NOT NULL,
+    `min_signed_per_window`	varchar(30)	NOT NULL,
+    `downtime_jail_duration`	BIGINT	NOT NULL
+);
+
+CREATE TABLE `cosmos_proposal` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+    `proposal_id`	BIGINT UNSIGNED	NOT NULL,
+
+    `title`	varchar(255)	NULL,
+    `status`	varchar(50)	NOT NULL,
+    `voting_start_time`	datetime(6)	NOT NULL,
+    `voting_end_time`	datetime(6)	NOT NULL,
+    `voter`	varchar(100)	NOT NULL,
+    `voted`	Bool	NOT NULL,
+    `vote_option`	varchar(255)	NULL
+);
+
+CREATE TABLE `gov_vote_reminder` (
+    `agent_name`	varchar(100)	NOT NULL,
+    `commit_id`	varchar(255)	NOT NULL,
+    `proposal_id`	BIGINT UNSIGNED	NOT NULL,
+    `remind_before`	BIGINT	NOT NULL,
+
+    `created_at`	datetime(6)	NOT NULL
+);
+
+CREATE TABLE `cosmos_upgrade_plan` (
+    `created_at`	datetime(6)	NOT NULL,
+    `event_uuid`	varchar(255)	NOT NULL,
+
+    `name`	varchar(255)	NOT NULL,
+    `height`	BIGINT	NOT NULL,
+    `info`	TEXT	NULL,
+    `latest_block_height`	BIGINT	NOT NULL,
+    `node_version`	varchar(50)	NOT NULL,
+    `average_block_time`	BIGINT	NOT NULL,
+    `estimated_time`	datetime(6)	NULL
+);
+
+CREATE TABLE `upgrade_reminder` (
+    `agent_name`	varchar(100)	NOT NULL,
+    `commit_id`	varchar(255)	NOT NULL,
+    `plan_name`	varchar(255)	NOT NULL,
+    `height`	BIGINT	NOT NULL,
+    `remind_before`	BIGINT	NOT NULL,
+
+    `created_at`	datetime(6)	NOT NULL
+);
+
+CREATE TABLE `block_commit_backfill` (
+    `agent_name`	varchar(100)	NOT NULL,
+    `commit_id`	varchar(255)	NOT NULL,
+    `start_height`	BIGINT	NOT NULL,
+    `end_height`	BIGINT	NOT NULL,
+
+    `next_height`	BIGINT	NOT NULL,
+    `updated_at`	datetime(6)	NOT NULL
+);
+
 CREATE TABLE `alarmer` (
     `alarmer_name`	varchar(100)	NOT NULL,
     `commit_id`	varchar(255)	NOT NULL,
@@ -228,6 +460,98 @@ ALTER TABLE `tendermint_commit_signature_list` ADD CONSTRAINT `PK_TENDERMINT_COM
     `event_uuid`
 );
 
+ALTER TABLE `tendermint_validator_set` ADD CONSTRAINT `PK_TENDERMINT_VALIDATOR_SET` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_validator` ADD CONSTRAINT `PK_TENDERMINT_VALIDATOR` PRIMARY KEY (
+    `address`,
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_consensus_state` ADD CONSTRAINT `PK_TENDERMINT_CONSENSUS_STATE` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_mempool` ADD CONSTRAINT `PK_TENDERMINT_MEMPOOL` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_abci_info` ADD CONSTRAINT `PK_TENDERMINT_ABCI_INFO` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_block_results` ADD CONSTRAINT `PK_TENDERMINT_BLOCK_RESULTS` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `tendermint_block_event` ADD CONSTRAINT `PK_TENDERMINT_BLOCK_EVENT` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`,
+    `event_index`
+);
+
+ALTER TABLE `metric` ADD CONSTRAINT `PK_METRIC` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`,
+    `metric_index`
+);
+
+ALTER TABLE `host_resource` ADD CONSTRAINT `PK_HOST_RESOURCE` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `disk_usage` ADD CONSTRAINT `PK_DISK_USAGE` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`,
+    `path`
+);
+
+ALTER TABLE `cosmos_validator` ADD CONSTRAINT `PK_COSMOS_VALIDATOR` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `cosmos_proposal` ADD CONSTRAINT `PK_COSMOS_PROPOSAL` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`,
+    `proposal_id`
+);
+
+ALTER TABLE `gov_vote_reminder` ADD CONSTRAINT `PK_GOV_VOTE_REMINDER` PRIMARY KEY (
+    `agent_name`,
+    `commit_id`,
+    `proposal_id`,
+    `remind_before`
+);
+
+ALTER TABLE `cosmos_upgrade_plan` ADD CONSTRAINT `PK_COSMOS_UPGRADE_PLAN` PRIMARY KEY (
+    `created_at`,
+    `event_uuid`
+);
+
+ALTER TABLE `upgrade_reminder` ADD CONSTRAINT `PK_UPGRADE_REMINDER` PRIMARY KEY (
+    `agent_name`,
+    `commit_id`,
+    `plan_name`,
+    `height`,
+    `remind_before`
+);
+
+ALTER TABLE `block_commit_backfill` ADD CONSTRAINT `PK_BLOCK_COMMIT_BACKFILL` PRIMARY KEY (
+    `agent_name`,
+    `commit_id`,
+    `start_height`,
+    `end_height`
+);
+
 ALTER TABLE `alarmer` ADD CONSTRAINT `PK_ALARMER` PRIMARY KEY (
     `alarmer_name`,
     `commit_id`
@@ -292,6 +616,49 @@ REFERENCES `tendermint_commit` (`event_uuid`, `created_at`);
 ALTER TABLE `tendermint_commit_signature_list` ADD CONSTRAINT `FK_event_TO_tendermint_commit_signature_list_1` FOREIGN KEY (`event_uuid`)
 REFERENCES `tendermint_commit` (`event_uuid`);
 
+ALTER TABLE `tendermint_validator_set` ADD CONSTRAINT `FK_event_TO_tendermint_validator_set_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `tendermint_validator` ADD CONSTRAINT `FK_tendermint_validator_set_TO_tendermint_validator_1` FOREIGN KEY (`event_uuid`, `created_at`)
+REFERENCES `tendermint_validator_set` (`event_uuid`, `created_at`);
+
+ALTER TABLE `tendermint_consensus_state` ADD CONSTRAINT `FK_event_TO_tendermint_consensus_state_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `tendermint_mempool` ADD CONSTRAINT `FK_event_TO_tendermint_mempool_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `tendermint_abci_info` ADD CONSTRAINT `FK_event_TO_tendermint_abci_info_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `tendermint_block_results` ADD CONSTRAINT `FK_event_TO_tendermint_block_results_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `tendermint_block_event` ADD CONSTRAINT `FK_tendermint_block_results_TO_tendermint_block_event_1` FOREIGN KEY (`event_uuid`, `created_at`)
+REFERENCES `tendermint_block_results` (`event_uuid`, `created_at`);
+
+ALTER TABLE `metric` ADD CONSTRAINT `FK_event_TO_metric_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `host_resource` ADD CONSTRAINT `FK_event_TO_host_resource_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `disk_usage` ADD CONSTRAINT `FK_host_resource_TO_disk_usage_1` FOREIGN KEY (`event_uuid`, `created_at`)
+REFERENCES `host_resource` (`event_uuid`, `created_at`);
+
+ALTER TABLE `cosmos_validator` ADD CONSTRAINT `FK_event_TO_cosmos_validator_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `cosmos_proposal` ADD CONSTRAINT `FK_event_TO_cosmos_proposal_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+ALTER TABLE `cosmos_upgrade_plan` ADD CONSTRAINT `FK_event_TO_cosmos_upgrade_plan_1` FOREIGN KEY (`event_uuid`)
+REFERENCES `event` (`event_uuid`);
+
+CREATE INDEX `INDEX_metric_name` ON `metric` (`name`, `created_at`);
+
+CREATE INDEX `INDEX_validator_set_chain_id_height` ON `tendermint_validator_set` (`chain_id`, `height`);
+
 ALTER TABLE `alert_level` ADD CONSTRAINT `FK_commit_record_TO_alert_level_1` FOREIGN KEY (`commit_id`)
 REFERENCES `commit_record` (`commit_id`);
 
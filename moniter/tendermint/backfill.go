@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/monitor"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"os/signal"
+	"syscall"
+)
+
+const backfillCommand = "backfill"
+
+// backfill stores block commits of a height range which the agent has missed. e.g. after an outage
+//
+//	backfill -agent node-a -from 1000 -to 2000
+func backfill(args []string) error {
+	var (
+		flags       = flag.NewFlagSet(backfillCommand, flag.ExitOnError)
+		agentName   = flags.String("agent", "", "name of the agent to backfill. can be omitted when only one agent is configured")
+		fromHeight  = flags.Uint64("from", 0, "first height to backfill")
+		toHeight    = flags.Uint64("to", 0, "last height to backfill")
+		concurrency = flags.Int("concurrency", 0, "max concurrent commit fetches. blockCommitMaxConcurrency of the agent by default")
+		chunkSize   = flags.Uint64("chunk", 1000, "heights to fetch and store at a time. progress is saved after every chunk")
+	)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	agents := mConfig.MonitoringAgents()
+	if *agentName == "" {
+		if len(agents) != 1 {
+			return errors.New("-agent is required when several agents are configured")
+		}
+		*agentName = agents[0].AgentName
+	}
+
+	var agent *types.MonitoringAgent
+	for i := range agents {
+		if agents[i].AgentName == *agentName {
+			agent = &agents[i]
+		}
+	}
+	if agent == nil {
+		return errors.New("agent not found: " + *agentName)
+	}
+
+	agentConfig := mConfig.ForAgent(*agent)
+	if *concurrency > 0 {
+		agentConfig.Agent.BlockCommitMaxConcurrency = *concurrency
+	}
+	agentClient, err := client.ForAgent(&agentConfig.Agent)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return monitor.BackfillBlockCommits(ctx, agentConfig, agentClient, *fromHeight, *toHeight, *chunkSize)
+}
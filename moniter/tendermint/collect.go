@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/monitor"
+	"os/signal"
+	"syscall"
+)
+
+const collectCommand = "collect"
+
+// collect serves the collector, which writes records pushed by monitors in sink mode into the database.
+//
+//	collect -address :8443
+func collect(args []string) error {
+	var (
+		flags   = flag.NewFlagSet(collectCommand, flag.ExitOnError)
+		address = flags.String("address", mConfig.Collector.Address, "address to listen on")
+	)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if client.Sink() != nil {
+		return errors.New("collector writes into the database itself. remove `sink` from its config")
+	}
+	if len(mConfig.Collector.Tokens) == 0 {
+		return errors.New("no collector token is configured. set `collector.tokens` so that monitors can authenticate")
+	}
+	for i, token := range mConfig.Collector.Tokens {
+		if token.Token == "" || len(token.Agents) == 0 {
+			return fmt.Errorf("token and agents of collector.tokens[%d] are required", i)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	backend := collector.Backend{
+		Write: func(ctx context.Context, record collector.Record) error {
+			return monitor.WriteRecord(ctx, &mConfig, client, record)
+		},
+		Query: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+			return monitor.RunQuery(ctx, &mConfig, client, name, args)
+		},
+		Ping: client.DB.PingContext,
+	}
+	handler := collector.Handler(mConfig.Collector.Tokens, backend, mConfig.Collector.MaxBodyBytes)
+	return collector.Serve(ctx, *address, mConfig.Collector.TLSCertFile, mConfig.Collector.TLSKeyFile, handler)
+}
@@ -0,0 +1,166 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// retryInterval is the delay before the first retry. It doubles on every retry.
+const retryInterval = 500 * time.Millisecond
+
+// Client pushes records to a collector, which is how monitors in sink mode write.
+type Client struct {
+	address    string
+	token      string
+	retries    int
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the collector at address. (e.g. `https://collector:8443`)
+// Requests failing temporarily are retried up to retries times. caFile adds a CA to trust, e.g. of a self-signed collector.
+func NewClient(address, token string, timeout time.Duration, retries int, caFile string) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		retries:    retries,
+		httpClient: &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+// Write pushes the records in a batch. It returns how many records, from the first, have been written.
+// The error wraps ErrRejected when the record after them can never be written.
+func (c *Client) Write(ctx context.Context, records []Record) (int, error) {
+	var written int
+	err := c.retry(ctx, func() (bool, error) {
+		var response recordsResponse
+		statusCode, err := c.post(ctx, recordsPath, recordsRequest{Records: records[written:]}, &response)
+		written += response.Written
+		if err != nil {
+			return true, err
+		}
+		return retryable(statusCode), responseError(statusCode, response.Error)
+	})
+	return written, err
+}
+
+// Query runs the query named name on the collector and decodes its result into result.
+func (c *Client) Query(ctx context.Context, name string, args any, result any) error {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	return c.retry(ctx, func() (bool, error) {
+		var response queryResponse
+		statusCode, err := c.post(ctx, queriesPath, queryRequest{Name: name, Args: argsBytes}, &response)
+		if err != nil {
+			return true, err
+		}
+		if err = responseError(statusCode, response.Error); err != nil {
+			return retryable(statusCode), err
+		}
+		return false, json.Unmarshal(response.Result, result)
+	})
+}
+
+// Ping checks the collector and its database are reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+healthPath, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("collector responded %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// retry calls try until it succeeds, fails permanently or runs out of retries.
+func (c *Client) retry(ctx context.Context, try func() (retry bool, err error)) error {
+	interval := retryInterval
+	for attempt := 0; ; attempt++ {
+		retry, err := try()
+		if err == nil || !retry || attempt >= c.retries || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// post sends the request and decodes the response into response. err is set when the collector couldn't be reached.
+func (c *Client) post(ctx context.Context, path string, request any, response any) (int, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.address+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Errors outside of the api(e.g. a proxy in front of it) have no json body.
+	_ = json.NewDecoder(io.LimitReader(res.Body, DefaultMaxBodyBytes)).Decode(response)
+	return res.StatusCode, nil
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+func responseError(statusCode int, message string) error {
+	switch {
+	case statusCode == http.StatusOK:
+		return nil
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusRequestEntityTooLarge || statusCode == http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w(%d): %s", ErrRejected, statusCode, message)
+	default:
+		return fmt.Errorf("collector responded %d: %s", statusCode, message)
+	}
+}
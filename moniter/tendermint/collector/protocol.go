@@ -0,0 +1,121 @@
+// Package collector lets monitors on untrusted hosts store records without database credentials.
+// Monitors in sink mode push records to a collector over http, which writes them into the database on their behalf.
+//
+//	POST /v1/records  {"records": [{"name": "status", "payload": {...}}]}  ->  {"written": 1}
+//	POST /v1/queries  {"name": "highest_commit_height", "args": {...}}     ->  {"result": 123}
+//
+// Requests are authenticated by `Authorization: Bearer <token>`, and a token may only submit records of its own agents.
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	recordsPath = "/v1/records"
+	queriesPath = "/v1/queries"
+	healthPath  = "/healthz"
+)
+
+// ErrRejected is wrapped by errors of records or queries which can never succeed. e.g. unknown writer, invalid payload
+// They shouldn't be retried.
+var ErrRejected = errors.New("rejected by collector")
+
+// Record is a write of a monitor. Name is of the writer which writes Payload.
+type Record struct {
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type recordsRequest struct {
+	Records []Record `json:"records"`
+}
+
+// recordsResponse tells how many records, from the first, have been written. The rest weren't when Error is set.
+type recordsResponse struct {
+	Written int    `json:"written"`
+	Error   string `json:"error,omitempty"`
+}
+
+type queryRequest struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args"`
+}
+
+type queryResponse struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// agentNameKey is the field naming the agent in records and query args. Every record has it in its event.
+const agentNameKey = "AgentName"
+
+// agentNamesOf returns every agent name in the json document.
+// Keys are matched case-insensitively and duplicated ones are all returned, since encoding/json decodes any of them into the record,
+// the last one winning. Otherwise a document could name an allowed agent to be authorized and another one to be stored.
+func agentNamesOf(document json.RawMessage) ([]string, error) {
+	var names []string
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	err := walkAgentNames(decoder, &names)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the document")
+	}
+	return names, nil
+}
+
+// walkAgentNames reads the next value of decoder, appending agent names in it to names.
+func walkAgentNames(decoder *json.Decoder, names *[]string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			if !strings.EqualFold(key, agentNameKey) {
+				err = walkAgentNames(decoder, names)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			token, err = decoder.Token()
+			if err != nil {
+				return err
+			}
+			name, ok := token.(string)
+			if !ok {
+				return fmt.Errorf("%s is not a string", key)
+			}
+			*names = append(*names, name)
+		}
+	case json.Delim('['):
+		for decoder.More() {
+			err = walkAgentNames(decoder, names)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Closing delimiter of the object or the array.
+	_, err = decoder.Token()
+	return err
+}
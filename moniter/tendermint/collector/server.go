@@ -0,0 +1,206 @@
+package collector
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBodyBytes bounds a request, which is a batch of records.
+	DefaultMaxBodyBytes = 16 << 20
+	shutdownTimeout     = 5 * time.Second
+	// anyAgent in agents of a token allows every agent.
+	anyAgent = "*"
+)
+
+// Token authenticates monitors, which may submit records of Agents only.
+type Token struct {
+	Token  string   `yaml:"token"`
+	Agents []string `yaml:"agents"`
+}
+
+// Backend writes records and runs queries on behalf of monitors.
+// Errors wrapping ErrRejected are reported as permanent, others as temporary so that monitors retry.
+type Backend struct {
+	Write func(ctx context.Context, record Record) error
+	Query func(ctx context.Context, name string, args json.RawMessage) (any, error)
+	Ping  func(ctx context.Context) error
+}
+
+// Serve serves Handler on the address until ctx is done. It serves https when certFile and keyFile are given.
+func Serve(ctx context.Context, address, certFile, keyFile string, handler http.Handler) error {
+	server := &http.Server{Addr: address, Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	var err error
+	if certFile != "" && keyFile != "" {
+		log.Info("[collector] serving https on " + address)
+		err = server.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		log.Warn("[collector] serving plain http on " + address + ". tokens are sent in clear text unless tls is terminated in front of it")
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Handler serves the collector api.
+func Handler(tokens []Token, backend Backend, maxBodyBytes int64) http.Handler {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	h := &handler{tokens: tokens, backend: backend, maxBodyBytes: maxBodyBytes}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := backend.Ping(r.Context()); err != nil {
+			http.Error(w, "database is unreachable: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc(recordsPath, h.authenticated(h.serveRecords))
+	mux.HandleFunc(queriesPath, h.authenticated(h.serveQuery))
+	return mux
+}
+
+type handler struct {
+	tokens       []Token
+	backend      Backend
+	maxBodyBytes int64
+}
+
+// authenticated passes agents of the request's token to next.
+func (h *handler) authenticated(next func(w http.ResponseWriter, r *http.Request, agents []string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || bearer == "" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		for _, token := range h.tokens {
+			if subtle.ConstantTimeCompare([]byte(token.Token), []byte(bearer)) == 1 {
+				r.Body = http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+				next(w, r, token.Agents)
+				return
+			}
+		}
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}
+}
+
+func (h *handler) serveRecords(w http.ResponseWriter, r *http.Request, agents []string) {
+	var request recordsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, statusOfBodyError(err), recordsResponse{Error: err.Error()})
+		return
+	}
+
+	// The whole batch is refused when any record is of another agent.
+	for i, record := range request.Records {
+		if err := authorize(agents, record.Payload); err != nil {
+			log.Warn(fmt.Sprintf("[collector] refused a batch from %s. record[%d](%s): %v", r.RemoteAddr, i, record.Name, err))
+			writeJSON(w, http.StatusForbidden, recordsResponse{Error: fmt.Sprintf("record[%d](%s): %v", i, record.Name, err)})
+			return
+		}
+	}
+
+	for i, record := range request.Records {
+		err := h.backend.Write(r.Context(), record)
+		if err != nil {
+			writeJSON(w, statusOf(err), recordsResponse{Written: i, Error: fmt.Sprintf("record[%d](%s): %v", i, record.Name, err)})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, recordsResponse{Written: len(request.Records)})
+}
+
+func (h *handler) serveQuery(w http.ResponseWriter, r *http.Request, agents []string) {
+	var request queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, statusOfBodyError(err), queryResponse{Error: err.Error()})
+		return
+	}
+	if err := authorize(agents, request.Args); err != nil {
+		writeJSON(w, http.StatusForbidden, queryResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.backend.Query(r.Context(), request.Name, request.Args)
+	if err != nil {
+		writeJSON(w, statusOf(err), queryResponse{Error: err.Error()})
+		return
+	}
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, queryResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, queryResponse{Result: resultBytes})
+}
+
+// authorize checks the document names an agent, and only the agents allowed.
+func authorize(agents []string, document json.RawMessage) error {
+	names, err := agentNamesOf(document)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.New("no agent named")
+	}
+	for _, name := range names {
+		if !allowed(agents, name) {
+			return errors.New("agent not allowed for the token: " + name)
+		}
+	}
+	return nil
+}
+
+func allowed(agents []string, name string) bool {
+	for _, agent := range agents {
+		if agent == anyAgent || agent == name {
+			return true
+		}
+	}
+	return false
+}
+
+func statusOf(err error) int {
+	if errors.Is(err, ErrRejected) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusServiceUnavailable
+}
+
+func statusOfBodyError(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
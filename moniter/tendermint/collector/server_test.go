@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func statusRecord(agentName string, height int) Record {
+	return Record{Name: "status", Payload: json.RawMessage(fmt.Sprintf(`{"Event":{"AgentName":%q},"Height":%d}`, agentName, height))}
+}
+
+func TestCollector(t *testing.T) {
+	var (
+		written  []string
+		failures int
+	)
+	backend := Backend{
+		Write: func(ctx context.Context, record Record) error {
+			var payload struct{ Height int }
+			json.Unmarshal(record.Payload, &payload)
+			switch {
+			case payload.Height < 0:
+				return fmt.Errorf("%w: duplicated", ErrRejected)
+			case payload.Height == 0 && failures > 0:
+				failures--
+				return errors.New("database is unreachable")
+			}
+			written = append(written, fmt.Sprintf("%s/%d", record.Name, payload.Height))
+			return nil
+		},
+		Query: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+			if name != "highest_commit_height" {
+				return nil, fmt.Errorf("%w: unknown query %s", ErrRejected, name)
+			}
+			return uint64(42), nil
+		},
+		Ping: func(ctx context.Context) error { return nil },
+	}
+	server := httptest.NewServer(Handler([]Token{{Token: "secret-a", Agents: []string{"node-a"}}}, backend, 0))
+	defer server.Close()
+
+	newClient := func(token string) *Client {
+		client, err := NewClient(server.URL, token, time.Second, 2, "")
+		assert.NoError(t, err)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("records are written in order", func(t *testing.T) {
+		written = nil
+		n, err := newClient("secret-a").Write(ctx, []Record{statusRecord("node-a", 1), statusRecord("node-a", 2)})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"status/1", "status/2"}, written)
+	})
+
+	t.Run("temporary failures are retried from the failed record", func(t *testing.T) {
+		written, failures = nil, 1
+		n, err := newClient("secret-a").Write(ctx, []Record{statusRecord("node-a", 1), statusRecord("node-a", 0), statusRecord("node-a", 3)})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []string{"status/1", "status/0", "status/3"}, written)
+	})
+
+	t.Run("rejected records aren't retried", func(t *testing.T) {
+		written = nil
+		n, err := newClient("secret-a").Write(ctx, []Record{statusRecord("node-a", 1), statusRecord("node-a", -1), statusRecord("node-a", 3)})
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"status/1"}, written)
+	})
+
+	t.Run("tokens are limited to their agents", func(t *testing.T) {
+		written = nil
+		_, err := newClient("secret-b").Write(ctx, []Record{statusRecord("node-a", 1)})
+		assert.ErrorContains(t, err, "401")
+		assert.NotErrorIs(t, err, ErrRejected)
+
+		n, err := newClient("secret-a").Write(ctx, []Record{statusRecord("node-a", 1), statusRecord("node-b", 2)})
+		assert.ErrorContains(t, err, "agent not allowed for the token: node-b")
+		assert.Equal(t, 0, n)
+		assert.Empty(t, written)
+
+		_, err = newClient("secret-a").Write(ctx, []Record{{Name: "status", Payload: json.RawMessage(`{"Height":1}`)}})
+		assert.ErrorContains(t, err, "no agent named")
+	})
+
+	t.Run("agent names are matched the way they're decoded", func(t *testing.T) {
+		written = nil
+		// encoding/json matches keys case-insensitively, the last one winning, so this would be stored as of victim.
+		n, err := newClient("secret-a").Write(ctx, []Record{{Name: "status", Payload: json.RawMessage(`{"Event":{"AgentName":"node-a","agentname":"victim"},"Height":1}`)}})
+		assert.ErrorContains(t, err, "agent not allowed for the token: victim")
+		assert.Equal(t, 0, n)
+		assert.Empty(t, written)
+
+		_, err = newClient("secret-a").Write(ctx, []Record{{Name: "status", Payload: json.RawMessage(`{"Event":{"AgentName":"node-a","AgentName":"victim"},"Height":1}`)}})
+		assert.ErrorContains(t, err, "agent not allowed for the token: victim")
+
+		_, err = newClient("secret-a").Write(ctx, []Record{{Name: "status", Payload: json.RawMessage(`{"Event":{"AgentName":"node-a","AGENTNAME":{"a":1}},"Height":1}`)}})
+		assert.ErrorContains(t, err, "AGENTNAME is not a string")
+
+		var height uint64
+		err = newClient("secret-a").Query(ctx, "highest_commit_height", json.RawMessage(`{"AgentName":"node-a","agentName":"victim"}`), &height)
+		assert.ErrorContains(t, err, "403")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var height uint64
+		client := newClient("secret-a")
+		assert.NoError(t, client.Query(ctx, "highest_commit_height", map[string]string{"AgentName": "node-a"}, &height))
+		assert.Equal(t, uint64(42), height)
+
+		assert.ErrorContains(t, client.Query(ctx, "highest_commit_height", map[string]string{"AgentName": "node-b"}, &height), "403")
+		assert.ErrorIs(t, client.Query(ctx, "unknown", map[string]string{"AgentName": "node-a"}, &height), ErrRejected)
+		assert.NoError(t, client.Ping(ctx))
+	})
+
+}
@@ -0,0 +1,153 @@
+// Package geoip locates ip addresses with local MaxMind databases. (e.g. GeoLite2-City, GeoLite2-ASN)
+package geoip
+
+import (
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config is paths of the databases. Either of them may be empty.
+type Config struct {
+	// CityDatabase is a GeoLite2-City or GeoIP2-City database, which gives the country and city.
+	CityDatabase string `yaml:"cityDatabase"`
+	// ASNDatabase is a GeoLite2-ASN database, which gives the autonomous system.
+	ASNDatabase string `yaml:"asnDatabase"`
+}
+
+func (c Config) Enabled() bool {
+	return c.CityDatabase != "" || c.ASNDatabase != ""
+}
+
+// Location is where an ip address is. Fields the databases don't know are left empty.
+type Location struct {
+	CountryCode    string
+	Country        string
+	City           string
+	ASN            uint
+	ASOrganization string
+}
+
+// Locator looks up locations of ip addresses.
+// A database is read again once its file is modified, so updates of geoipupdate apply without a restart.
+type Locator struct {
+	city *database
+	asn  *database
+}
+
+// NewLocator opens the databases of cfg.
+func NewLocator(cfg Config) (*Locator, error) {
+	l := &Locator{}
+	if cfg.CityDatabase != "" {
+		l.city = &database{path: cfg.CityDatabase}
+		if _, err := l.city.get(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ASNDatabase != "" {
+		l.asn = &database{path: cfg.ASNDatabase}
+		if _, err := l.asn.get(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Locate returns the location of ip. An address the databases don't contain, such as a private one, gives an empty location.
+func (l *Locator) Locate(ip string) (Location, error) {
+	var location Location
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return location, fmt.Errorf("invalid ip address: %s", ip)
+	}
+
+	if l.city != nil {
+		record, err := l.city.lookup(parsed)
+		if err != nil {
+			return location, err
+		}
+		country := field(record, "country")
+		if country == nil {
+			country = field(record, "registered_country")
+		}
+		location.CountryCode, _ = field(country, "iso_code").(string)
+		location.Country, _ = field(country, "names", "en").(string)
+		location.City, _ = field(record, "city", "names", "en").(string)
+	}
+
+	if l.asn != nil {
+		record, err := l.asn.lookup(parsed)
+		if err != nil {
+			return location, err
+		}
+		asn, _ := field(record, "autonomous_system_number").(uint64)
+		location.ASN = uint(asn)
+		location.ASOrganization, _ = field(record, "autonomous_system_organization").(string)
+	}
+	return location, nil
+}
+
+// field returns the value of the nested keys of the record. nil if any of them is absent.
+func field(record any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := record.(map[string]any)
+		if !ok {
+			return nil
+		}
+		record = m[key]
+	}
+	return record
+}
+
+type database struct {
+	path string
+
+	mu      sync.Mutex
+	reader  *Reader
+	modTime time.Time
+}
+
+// get returns the reader of the database, opening it again when the file has been modified.
+// When it fails to be opened again, the previous one keeps being used until the file is modified again.
+func (d *database) get() (*Reader, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stat, err := os.Stat(d.path)
+	if err != nil {
+		// File may be in the middle of being replaced.
+		if d.reader != nil {
+			return d.reader, nil
+		}
+		return nil, err
+	}
+	if d.reader != nil && stat.ModTime().Equal(d.modTime) {
+		return d.reader, nil
+	}
+
+	reader, err := Open(d.path)
+	d.modTime = stat.ModTime()
+	if err != nil {
+		if d.reader == nil {
+			return nil, err
+		}
+		log.Warn(fmt.Sprintf("[geoip] keeping the previous %s since it failed to be reloaded: %v", d.path, err))
+		return d.reader, nil
+	}
+	if d.reader != nil {
+		log.Info(fmt.Sprintf("[geoip] reloaded %s(%s)", d.path, reader.DatabaseType()))
+	}
+	d.reader = reader
+	return reader, nil
+}
+
+func (d *database) lookup(ip net.IP) (any, error) {
+	reader, err := d.get()
+	if err != nil {
+		return nil, err
+	}
+	return reader.Lookup(ip)
+}
@@ -0,0 +1,242 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testNode is a node of the search tree built by writeTestDatabase.
+type testNode struct {
+	children [2]*testNode
+	// data is the offset of the record in the data section, -1 when the network is empty.
+	data [2]int
+}
+
+// writeTestDatabase builds a MaxMind DB whose networks point to offsets of data.
+func writeTestDatabase(t testing.TB, ipVersion, recordSize int, networks map[string]int, data []byte) []byte {
+	root := &testNode{data: [2]int{-1, -1}}
+	for cidr, offset := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		ones, _ := network.Mask.Size()
+		ip := network.IP
+		// IPv4 networks are at ::/96 of an IPv6 database.
+		if ip4 := ip.To4(); ip4 != nil && ipVersion == 6 {
+			ip = append(make(net.IP, 12), ip4...)
+			ones += 96
+		}
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := ip[i/8] >> (7 - i%8) & 1
+			if i == ones-1 {
+				node.data[bit] = offset
+				break
+			}
+			if node.children[bit] == nil {
+				node.children[bit] = &testNode{data: [2]int{-1, -1}}
+			}
+			node = node.children[bit]
+		}
+	}
+
+	var nodes []*testNode
+	index := make(map[*testNode]int)
+	for queue := []*testNode{root}; len(queue) > 0; queue = queue[1:] {
+		index[queue[0]] = len(nodes)
+		nodes = append(nodes, queue[0])
+		for _, child := range queue[0].children {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	nodeCount := len(nodes)
+	for _, node := range nodes {
+		var records [2]uint32
+		for bit := range records {
+			switch {
+			case node.children[bit] != nil:
+				records[bit] = uint32(index[node.children[bit]])
+			case node.data[bit] >= 0:
+				records[bit] = uint32(nodeCount + dataSectionSeparator + node.data[bit])
+			default:
+				records[bit] = uint32(nodeCount)
+			}
+		}
+		switch recordSize {
+		case 24:
+			b.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0]), byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		case 28:
+			b.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0]), byte(records[0]>>24)<<4 | byte(records[1]>>24), byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		case 32:
+			binary.Write(&b, binary.BigEndian, records)
+		}
+	}
+	b.Write(make([]byte, dataSectionSeparator))
+	b.Write(data)
+	b.Write(metadataMarker)
+	b.Write(encodeMap(
+		encodeString("node_count"), encodeUint(typeUint32, uint64(nodeCount)),
+		encodeString("record_size"), encodeUint(typeUint16, uint64(recordSize)),
+		encodeString("ip_version"), encodeUint(typeUint16, uint64(ipVersion)),
+		encodeString("database_type"), encodeString("Test"),
+	))
+	return b.Bytes()
+}
+
+// encodeString encodes s shorter than 285 bytes.
+func encodeString(s string) []byte {
+	if len(s) < 29 {
+		return append([]byte{typeString<<5 | byte(len(s))}, s...)
+	}
+	return append([]byte{typeString<<5 | 29, byte(len(s) - 29)}, s...)
+}
+
+func encodeUint(dataType byte, n uint64) []byte {
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{dataType<<5 | byte(len(b))}, b...)
+}
+
+func encodeMap(pairs ...[]byte) []byte {
+	return append([]byte{typeMap<<5 | byte(len(pairs)/2)}, bytes.Join(pairs, nil)...)
+}
+
+func encodePointer(offset int) []byte {
+	return []byte{typePointer<<5 | byte(offset>>8&0x7), byte(offset)}
+}
+
+func writeFile(t *testing.T, path string, b []byte, modTime time.Time) {
+	assert.NoError(t, os.WriteFile(path, b, 0600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestLocator(t *testing.T) {
+	var (
+		country = encodeMap(encodeString("iso_code"), encodeString("KR"), encodeString("names"), encodeMap(encodeString("en"), encodeString("South Korea")))
+		seoul   = encodeMap(encodeString("country"), country, encodeString("city"), encodeMap(encodeString("names"), encodeMap(encodeString("en"), encodeString("Seoul"))))
+		// registered country of the second record points to the country of the first one.
+		countryOffset = 1 + len(encodeString("country"))
+		registered    = encodeMap(encodeString("registered_country"), encodePointer(countryOffset))
+		cityData      = append(seoul, registered...)
+		cityNetworks  = map[string]int{"1.2.3.0/24": 0, "2001:db8::/32": len(seoul)}
+	)
+	asnData := func(organization string) []byte {
+		return encodeMap(encodeString("autonomous_system_number"), encodeUint(typeUint32, 4766), encodeString("autonomous_system_organization"), encodeString(organization))
+	}
+
+	t.Run("locates country, city and asn", func(t *testing.T) {
+		dir := t.TempDir()
+		cityPath, asnPath := filepath.Join(dir, "city.mmdb"), filepath.Join(dir, "asn.mmdb")
+		writeFile(t, cityPath, writeTestDatabase(t, 6, 28, cityNetworks, cityData), time.Now())
+		writeFile(t, asnPath, writeTestDatabase(t, 4, 24, map[string]int{"1.2.0.0/16": 0}, asnData("Korea Telecom")), time.Now())
+
+		locator, err := NewLocator(Config{CityDatabase: cityPath, ASNDatabase: asnPath})
+		assert.NoError(t, err)
+
+		location, err := locator.Locate("1.2.3.4")
+		assert.NoError(t, err)
+		assert.Equal(t, Location{CountryCode: "KR", Country: "South Korea", City: "Seoul", ASN: 4766, ASOrganization: "Korea Telecom"}, location)
+
+		location, err = locator.Locate("1.2.9.9")
+		assert.NoError(t, err)
+		assert.Equal(t, Location{ASN: 4766, ASOrganization: "Korea Telecom"}, location)
+
+		location, err = locator.Locate("10.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, Location{}, location)
+
+		_, err = locator.Locate("not-an-ip")
+		assert.Error(t, err)
+
+		cityOnly, err := NewLocator(Config{CityDatabase: cityPath})
+		assert.NoError(t, err)
+		location, err = cityOnly.Locate("2001:db8::1")
+		assert.NoError(t, err)
+		assert.Equal(t, Location{CountryCode: "KR", Country: "South Korea"}, location)
+	})
+
+	t.Run("reads every record size", func(t *testing.T) {
+		for _, recordSize := range []int{24, 28, 32} {
+			for _, ipVersion := range []int{4, 6} {
+				reader, err := NewReader(writeTestDatabase(t, ipVersion, recordSize, map[string]int{"1.2.0.0/16": 0, "5.6.7.8/32": 0}, asnData("Korea Telecom")))
+				assert.NoError(t, err)
+
+				for _, ip := range []string{"1.2.200.1", "5.6.7.8"} {
+					record, err := reader.Lookup(net.ParseIP(ip))
+					assert.NoError(t, err)
+					assert.Equal(t, "Korea Telecom", field(record, "autonomous_system_organization"), "record size: %d, ip version: %d, ip: %s", recordSize, ipVersion, ip)
+				}
+				record, err := reader.Lookup(net.ParseIP("5.6.7.9"))
+				assert.NoError(t, err)
+				assert.Nil(t, record)
+			}
+		}
+	})
+
+	t.Run("reloads modified database", func(t *testing.T) {
+		asnPath := filepath.Join(t.TempDir(), "asn.mmdb")
+		writeFile(t, asnPath, writeTestDatabase(t, 4, 24, map[string]int{"1.2.0.0/16": 0}, asnData("Korea Telecom")), time.Now().Add(-time.Hour))
+
+		locator, err := NewLocator(Config{ASNDatabase: asnPath})
+		assert.NoError(t, err)
+		location, err := locator.Locate("1.2.3.4")
+		assert.NoError(t, err)
+		assert.Equal(t, "Korea Telecom", location.ASOrganization)
+
+		writeFile(t, asnPath, writeTestDatabase(t, 4, 24, map[string]int{"1.2.0.0/16": 0}, asnData("KT Corp")), time.Now())
+		location, err = locator.Locate("1.2.3.4")
+		assert.NoError(t, err)
+		assert.Equal(t, "KT Corp", location.ASOrganization)
+
+		// Broken update keeps the previous database.
+		writeFile(t, asnPath, []byte("broken"), time.Now().Add(time.Hour))
+		location, err = locator.Locate("1.2.3.4")
+		assert.NoError(t, err)
+		assert.Equal(t, "KT Corp", location.ASOrganization)
+	})
+
+	t.Run("rejects a file which isn't a maxmind db", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "city.mmdb")
+		writeFile(t, path, []byte("not a database"), time.Now())
+		_, err := NewLocator(Config{CityDatabase: path})
+		assert.ErrorContains(t, err, "metadata not found")
+	})
+
+}
+
+func TestNewReader(t *testing.T) {
+	t.Run("rejects a size larger than the data", func(t *testing.T) {
+		// The largest size follows the control byte, and the type byte for an extended type.
+		for _, header := range [][]byte{{typeMap<<5 | 31}, {typeExtended<<5 | 31, typeArray - 7}} {
+			b := append(append(append([]byte{}, metadataMarker...), header...), 0xFF, 0xFF, 0xFF)
+			_, err := NewReader(b)
+			assert.ErrorContains(t, err, "exceeds the data")
+		}
+	})
+}
+
+func FuzzNewReader(f *testing.F) {
+	f.Add(writeTestDatabase(f, 4, 24, map[string]int{"1.2.3.0/24": 0}, encodeMap(encodeString("city"), encodeString("Seoul"))))
+	f.Add(writeTestDatabase(f, 6, 28, map[string]int{"2001:db8::/32": 0}, encodeMap(encodeString("country"), encodePointer(0))))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := NewReader(b)
+		if err != nil {
+			return
+		}
+		_, _ = r.Lookup(net.ParseIP("1.2.3.4"))
+		_, _ = r.Lookup(net.ParseIP("2001:db8::1"))
+	})
+}
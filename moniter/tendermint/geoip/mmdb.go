@@ -0,0 +1,355 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// metadataMarker precedes the metadata at the end of the file.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const (
+	// dataSectionSeparator is the zeroed bytes between the search tree and the data section.
+	dataSectionSeparator = 16
+	// maxDecodeDepth guards against a corrupted file nesting maps and arrays endlessly.
+	maxDecodeDepth = 32
+)
+
+// Data types of the MaxMind DB format.
+const (
+	typeExtended = 0
+	typePointer  = 1
+	typeString   = 2
+	typeDouble   = 3
+	typeBytes    = 4
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeInt32    = 8
+	typeUint64   = 9
+	typeUint128  = 10
+	typeArray    = 11
+	typeBool     = 14
+	typeFloat    = 15
+)
+
+// Reader reads a MaxMind DB(.mmdb) file. https://maxmind.github.io/MaxMind-DB/
+// The whole file is loaded into memory, so a lookup doesn't touch the disk.
+type Reader struct {
+	tree         []byte
+	data         decoder
+	nodeCount    uint
+	recordSize   uint
+	ipVersion    uint
+	databaseType string
+	// ipv4Start is the node of ::/96 in an IPv6 database, where IPv4 addresses are looked up from.
+	ipv4Start uint
+}
+
+// Open reads the database of the path.
+func Open(path string) (*Reader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// NewReader reads the database of b.
+func NewReader(b []byte) (*Reader, error) {
+	markerIndex := bytes.LastIndex(b, metadataMarker)
+	if markerIndex < 0 {
+		return nil, errors.New("not a maxmind db. metadata not found")
+	}
+
+	metadata, _, err := decoder{buffer: b[markerIndex+len(metadataMarker):]}.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	fields, ok := metadata.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid metadata: not a map")
+	}
+
+	r := &Reader{
+		nodeCount:  uintOf(fields["node_count"]),
+		recordSize: uintOf(fields["record_size"]),
+		ipVersion:  uintOf(fields["ip_version"]),
+	}
+	r.databaseType, _ = fields["database_type"].(string)
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size: %d", r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported ip version: %d", r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+dataSectionSeparator > uint(markerIndex) {
+		return nil, errors.New("search tree is larger than the file")
+	}
+	r.tree = b[:treeSize]
+	r.data = decoder{buffer: b[treeSize+dataSectionSeparator : markerIndex]}
+
+	if r.ipVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.readRecord(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// DatabaseType is the type of the database. (e.g. `GeoLite2-City`, `GeoLite2-ASN`)
+func (r *Reader) DatabaseType() string {
+	return r.databaseType
+}
+
+// Lookup returns the record of the network which contains ip, decoded into maps, slices and scalars.
+// It returns nil when ip isn't in the database.
+func (r *Reader) Lookup(ip net.IP) (any, error) {
+	node := uint(0)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if ip = ip.To16(); ip == nil {
+		return nil, errors.New("invalid ip address")
+	} else if r.ipVersion == 4 {
+		return nil, errors.New("ipv6 address can't be looked up from an ipv4 database")
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-i%8)) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return nil, nil
+	case node < r.nodeCount:
+		return nil, errors.New("invalid search tree. ran out of address bits")
+	case node < r.nodeCount+dataSectionSeparator:
+		return nil, errors.New("invalid search tree. record points into the separator")
+	}
+
+	offset := node - r.nodeCount - dataSectionSeparator
+	record, _, err := r.data.decode(offset, 0)
+	return record, err
+}
+
+// readRecord returns the left(bit 0) or right(bit 1) record of the node.
+func (r *Reader) readRecord(node, bit uint) uint {
+	b := r.tree[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// decoder decodes the data of a section. Pointers in the section are offsets from its beginning.
+type decoder struct {
+	buffer []byte
+}
+
+// decode returns the value at offset and the offset right after it.
+func (d decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, errors.New("data is nested too deep")
+	}
+	ctrl, offset, err := d.bytes(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dataType := uint(ctrl[0] >> 5)
+	if dataType == typeExtended {
+		var extended []byte
+		extended, offset, err = d.bytes(offset, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Extended types are numbered from 8.
+		dataType = 7 + uint(extended[0])
+	}
+
+	if dataType == typePointer {
+		pointer, next, err := d.pointer(ctrl[0], offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	size, offset, err := d.size(ctrl[0], offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Every entry takes a byte at least, so a size beyond the rest of the buffer is corrupt.
+	// It's checked before the size is allocated.
+	remaining := uint(len(d.buffer)) - offset
+	switch dataType {
+	case typeMap:
+		if size > remaining/2 {
+			return nil, 0, fmt.Errorf("map size %d at %d exceeds the data", size, offset)
+		}
+		m := make(map[string]any, size)
+		for i := uint(0); i < size; i++ {
+			var key, value any
+			key, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("map key at %d is not a string", offset)
+			}
+			value, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[keyString] = value
+		}
+		return m, offset, nil
+	case typeArray:
+		if size > remaining {
+			return nil, 0, fmt.Errorf("array size %d at %d exceeds the data", size, offset)
+		}
+		a := make([]any, 0, size)
+		for i := uint(0); i < size; i++ {
+			var value any
+			value, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	b, next, err := d.bytes(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch dataType {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return bytes.Clone(b), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid size of double: %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid size of float: %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid size of uint: %d", size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid size of int32: %d", size)
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int64(int32(n)), next, nil
+	case typeUint128:
+		return new(big.Int).SetBytes(b), next, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type: %d", dataType)
+	}
+}
+
+// size returns the payload size given by the control byte, which may continue in the next bytes.
+func (d decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1F)
+	if size < 29 {
+		return size, offset, nil
+	}
+
+	b, next, err := d.bytes(offset, size-28)
+	if err != nil {
+		return 0, 0, err
+	}
+	var n uint
+	for _, c := range b {
+		n = n<<8 | uint(c)
+	}
+	switch size {
+	case 29:
+		return 29 + n, next, nil
+	case 30:
+		return 285 + n, next, nil
+	default:
+		return 65821 + n, next, nil
+	}
+}
+
+// pointer returns the offset the pointer points to, and the offset right after the pointer.
+func (d decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	pointerSize := uint(ctrl>>3)&0x3 + 1
+	b, next, err := d.bytes(offset, pointerSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var pointer uint
+	if pointerSize != 4 {
+		pointer = uint(ctrl & 0x7)
+	}
+	for _, c := range b {
+		pointer = pointer<<8 | uint(c)
+	}
+	switch pointerSize {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, next, nil
+}
+
+func (d decoder) bytes(offset, size uint) ([]byte, uint, error) {
+	if offset > uint(len(d.buffer)) || size > uint(len(d.buffer))-offset {
+		return nil, 0, fmt.Errorf("data at %d exceeds the section", offset)
+	}
+	return d.buffer[offset : offset+size], offset + size, nil
+}
+
+func uintOf(v any) uint {
+	n, _ := v.(uint64)
+	return uint(n)
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// DBWriteStats is accumulated stats of a writer's database writes since the process started.
+type DBWriteStats struct {
+	Writes   uint64
+	Failures uint64
+	// Records is the sum of batch sizes of the writes.
+	Records       uint64
+	LastBatchSize int
+	// Duration is the sum of write latencies.
+	Duration     time.Duration
+	LastDuration time.Duration
+}
+
+// DBWriteRecorder accumulates stats of database writes by writer.
+type DBWriteRecorder struct {
+	mu    sync.Mutex
+	stats map[string]*DBWriteStats
+}
+
+func NewDBWriteRecorder() *DBWriteRecorder {
+	return &DBWriteRecorder{stats: make(map[string]*DBWriteStats)}
+}
+
+// Observe records a database write of the writer.
+func (r *DBWriteRecorder) Observe(writer string, batchSize int, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, exists := r.stats[writer]
+	if !exists {
+		stats = &DBWriteStats{}
+		r.stats[writer] = stats
+	}
+	stats.Writes++
+	if err != nil {
+		stats.Failures++
+	}
+	stats.Records += uint64(batchSize)
+	stats.LastBatchSize = batchSize
+	stats.Duration += duration
+	stats.LastDuration = duration
+}
+
+// Snapshot returns a copy of every writer's stats by name.
+func (r *DBWriteRecorder) Snapshot() map[string]DBWriteStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]DBWriteStats, len(r.stats))
+	for writer, stats := range r.stats {
+		result[writer] = *stats
+	}
+	return result
+}
+
+// dbWrites is the recorder of the process's writers.
+var dbWrites = NewDBWriteRecorder()
+
+// ObserveDBWrite records a database write of the writer.
+func ObserveDBWrite(writer string, batchSize int, duration time.Duration, err error) {
+	dbWrites.Observe(writer, batchSize, duration, err)
+}
+
+// DBWrites returns a snapshot of every writer's stats by name.
+func DBWrites() map[string]DBWriteStats {
+	return dbWrites.Snapshot()
+}
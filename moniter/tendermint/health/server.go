@@ -0,0 +1,240 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/scheduler"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/wal"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// pingTimeout bounds the database ping of /readyz.
+	pingTimeout     = 2 * time.Second
+	shutdownTimeout = 5 * time.Second
+)
+
+// Source is where the endpoint reads the monitor's state from.
+type Source struct {
+	// Jobs returns scheduling stats of every job by name.
+	Jobs func() map[string]scheduler.Stats
+	// Endpoints returns rpc endpoint status of every agent by agent name.
+	Endpoints func() map[string][]types.EndpointStatus
+	PingDB    func(ctx context.Context) error
+	// DBWrites returns stats of database writes by writer, e.g. DBWrites.
+	DBWrites func() map[string]DBWriteStats
+	// WAL returns nil when wal is disabled.
+	WAL func() *wal.Stats
+}
+
+// Serve serves Handler on the address until ctx is done.
+func Serve(ctx context.Context, address string, source Source) error {
+	server := &http.Server{Addr: address, Handler: Handler(source), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("[health] serving /healthz, /readyz, /metrics on " + address)
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Handler serves the endpoints.
+//   - /healthz responds 200 while the process is serving.
+//   - /readyz responds 200 when the database is reachable and every agent has a healthy rpc endpoint, 503 with reasons otherwise.
+//   - /metrics exposes the monitor's own metrics in prometheus text format.
+func Handler(source Source) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		reasons := source.notReadyReasons(r.Context())
+		if len(reasons) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, strings.Join(reasons, "\n")+"\n")
+			return
+		}
+		io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		source.writeMetrics(w)
+	})
+	return mux
+}
+
+func (s Source) notReadyReasons(ctx context.Context) []string {
+	var reasons []string
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := s.PingDB(ctx); err != nil {
+		reasons = append(reasons, "database is unreachable: "+err.Error())
+	}
+
+	endpoints := s.Endpoints()
+	for _, agentName := range sortedKeys(endpoints) {
+		healthy := false
+		for _, endpoint := range endpoints[agentName] {
+			healthy = healthy || endpoint.Healthy
+		}
+		if !healthy {
+			reasons = append(reasons, "no healthy rpc endpoint of agent "+agentName)
+		}
+	}
+	return reasons
+}
+
+func (s Source) writeMetrics(w io.Writer) {
+	m := metricWriter{w: w}
+
+	jobs := s.Jobs()
+	jobNames := sortedKeys(jobs)
+	m.family("harvestmon_monitor_runs_total", "counter", "Runs of the monitor.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_runs_total", float64(jobs[name].Runs), "monitor", name)
+	}
+	m.family("harvestmon_monitor_failures_total", "counter", "Runs of the monitor which returned an error.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_failures_total", float64(jobs[name].Failures), "monitor", name)
+	}
+	m.family("harvestmon_monitor_skipped_total", "counter", "Runs skipped because the previous run was still running.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_skipped_total", float64(jobs[name].Skipped), "monitor", name)
+	}
+	m.family("harvestmon_monitor_last_success_timestamp_seconds", "gauge", "Unix time the latest successful run finished. 0 if it has never succeeded.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_last_success_timestamp_seconds", unixSeconds(jobs[name].LastSuccess), "monitor", name)
+	}
+	m.family("harvestmon_monitor_last_duration_seconds", "gauge", "Duration of the latest finished run.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_last_duration_seconds", jobs[name].LastDuration.Seconds(), "monitor", name)
+	}
+	m.family("harvestmon_monitor_last_lateness_seconds", "gauge", "How late the latest run started compared to its schedule.")
+	for _, name := range jobNames {
+		m.sample("harvestmon_monitor_last_lateness_seconds", jobs[name].LastLateness.Seconds(), "monitor", name)
+	}
+
+	endpoints := s.Endpoints()
+	agentNames := sortedKeys(endpoints)
+	m.family("harvestmon_rpc_requests_total", "counter", "Requests to the rpc endpoint.")
+	for _, agentName := range agentNames {
+		for _, endpoint := range endpoints[agentName] {
+			m.sample("harvestmon_rpc_requests_total", float64(endpoint.Requests), "agent", agentName, "endpoint", endpoint.Endpoint)
+		}
+	}
+	m.family("harvestmon_rpc_errors_total", "counter", "Failed requests to the rpc endpoint.")
+	for _, agentName := range agentNames {
+		for _, endpoint := range endpoints[agentName] {
+			m.sample("harvestmon_rpc_errors_total", float64(endpoint.Errors), "agent", agentName, "endpoint", endpoint.Endpoint)
+		}
+	}
+	m.family("harvestmon_rpc_endpoint_healthy", "gauge", "1 if the rpc endpoint is healthy.")
+	for _, agentName := range agentNames {
+		for _, endpoint := range endpoints[agentName] {
+			m.sample("harvestmon_rpc_endpoint_healthy", boolValue(endpoint.Healthy), "agent", agentName, "endpoint", endpoint.Endpoint)
+		}
+	}
+
+	dbWrites := s.DBWrites()
+	writers := sortedKeys(dbWrites)
+	m.family("harvestmon_db_writes_total", "counter", "Database writes of the writer.")
+	for _, writer := range writers {
+		m.sample("harvestmon_db_writes_total", float64(dbWrites[writer].Writes), "writer", writer)
+	}
+	m.family("harvestmon_db_write_failures_total", "counter", "Failed database writes of the writer.")
+	for _, writer := range writers {
+		m.sample("harvestmon_db_write_failures_total", float64(dbWrites[writer].Failures), "writer", writer)
+	}
+	m.family("harvestmon_db_write_duration_seconds", "summary", "Latency of database writes.")
+	for _, writer := range writers {
+		m.sample("harvestmon_db_write_duration_seconds_sum", dbWrites[writer].Duration.Seconds(), "writer", writer)
+		m.sample("harvestmon_db_write_duration_seconds_count", float64(dbWrites[writer].Writes), "writer", writer)
+	}
+	m.family("harvestmon_db_write_batch_size", "summary", "Records per database write.")
+	for _, writer := range writers {
+		m.sample("harvestmon_db_write_batch_size_sum", float64(dbWrites[writer].Records), "writer", writer)
+		m.sample("harvestmon_db_write_batch_size_count", float64(dbWrites[writer].Writes), "writer", writer)
+	}
+	m.family("harvestmon_db_write_last_batch_size", "gauge", "Records of the latest database write.")
+	for _, writer := range writers {
+		m.sample("harvestmon_db_write_last_batch_size", float64(dbWrites[writer].LastBatchSize), "writer", writer)
+	}
+
+	if walStats := s.WAL(); walStats != nil {
+		m.family("harvestmon_wal_pending_records", "gauge", "Records buffered in the wal waiting for replay.")
+		m.sample("harvestmon_wal_pending_records", float64(walStats.PendingRecords))
+		m.family("harvestmon_wal_pending_bytes", "gauge", "Bytes buffered in the wal waiting for replay.")
+		m.sample("harvestmon_wal_pending_bytes", float64(walStats.PendingBytes))
+		m.family("harvestmon_wal_appended_total", "counter", "Records buffered into the wal.")
+		m.sample("harvestmon_wal_appended_total", float64(walStats.Appended))
+		m.family("harvestmon_wal_replayed_total", "counter", "Records replayed from the wal.")
+		m.sample("harvestmon_wal_replayed_total", float64(walStats.Replayed))
+		m.family("harvestmon_wal_dropped_total", "counter", "Records dropped because the wal was full.")
+		m.sample("harvestmon_wal_dropped_total", float64(walStats.Dropped))
+	}
+}
+
+// metricWriter writes samples in prometheus text exposition format.
+type metricWriter struct {
+	w io.Writer
+}
+
+func (m metricWriter) family(name, metricType, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sample writes a sample with labels given as name, value pairs.
+func (m metricWriter) sample(name string, value float64, labels ...string) {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], escapeLabelValue(labels[i+1])))
+	}
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(m.w, "%s %v\n", name, value)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
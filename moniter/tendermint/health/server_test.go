@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/scheduler"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/wal"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, server *httptest.Server, path string) (int, string) {
+	res, err := http.Get(server.URL + path)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	var (
+		pingErr   error
+		endpoints = map[string][]types.EndpointStatus{
+			"node-a": {{Endpoint: "10.0.0.1:26657", Healthy: true, Requests: 10, Errors: 2}},
+		}
+		dbWrites = NewDBWriteRecorder()
+	)
+	source := Source{
+		Jobs: func() map[string]scheduler.Stats {
+			return map[string]scheduler.Stats{
+				"node-a/status": {Runs: 3, Failures: 1, LastSuccess: time.Unix(1700000000, 0), LastDuration: 1500 * time.Millisecond},
+			}
+		},
+		Endpoints: func() map[string][]types.EndpointStatus { return endpoints },
+		PingDB:    func(ctx context.Context) error { return pingErr },
+		DBWrites:  dbWrites.Snapshot,
+		WAL:       func() *wal.Stats { return &wal.Stats{PendingRecords: 4} },
+	}
+	server := httptest.NewServer(Handler(source))
+	defer server.Close()
+
+	t.Run("healthz", func(t *testing.T) {
+		code, body := get(t, server, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok\n", body)
+	})
+
+	t.Run("readyz reports reasons", func(t *testing.T) {
+		code, _ := get(t, server, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+
+		pingErr = errors.New("connection refused")
+		endpoints["node-b"] = []types.EndpointStatus{{Endpoint: "10.0.0.2:26657", Healthy: false}}
+		defer func() {
+			pingErr = nil
+			delete(endpoints, "node-b")
+		}()
+
+		code, body := get(t, server, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "database is unreachable: connection refused")
+		assert.Contains(t, body, "no healthy rpc endpoint of agent node-b")
+		assert.NotContains(t, body, "node-a")
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		dbWrites.Observe("block_commit", 20, 100*time.Millisecond, nil)
+		dbWrites.Observe("block_commit", 10, 300*time.Millisecond, errors.New("timeout"))
+
+		code, body := get(t, server, "/metrics")
+		assert.Equal(t, http.StatusOK, code)
+		for _, line := range []string{
+			"# TYPE harvestmon_monitor_runs_total counter",
+			`harvestmon_monitor_runs_total{monitor="node-a/status"} 3`,
+			`harvestmon_monitor_failures_total{monitor="node-a/status"} 1`,
+			`harvestmon_monitor_last_success_timestamp_seconds{monitor="node-a/status"} 1.7e+09`,
+			`harvestmon_monitor_last_duration_seconds{monitor="node-a/status"} 1.5`,
+			`harvestmon_rpc_requests_total{agent="node-a",endpoint="10.0.0.1:26657"} 10`,
+			`harvestmon_rpc_errors_total{agent="node-a",endpoint="10.0.0.1:26657"} 2`,
+			`harvestmon_db_writes_total{writer="block_commit"} 2`,
+			`harvestmon_db_write_failures_total{writer="block_commit"} 1`,
+			`harvestmon_db_write_duration_seconds_sum{writer="block_commit"} 0.4`,
+			`harvestmon_db_write_batch_size_sum{writer="block_commit"} 30`,
+			`harvestmon_db_write_last_batch_size{writer="block_commit"} 10`,
+			"harvestmon_wal_pending_records 4",
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+
+}
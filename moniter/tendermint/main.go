@@ -1,37 +1,43 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
 	_const "github.com/b-harvest/Harvestmon/const"
 	log "github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/health"
 	"github.com/b-harvest/Harvestmon/moniter/tendermint/monitor"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/scheduler"
 	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/wal"
 	"github.com/rs/zerolog"
-	"gopkg.in/yaml.v3"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
 var (
-	err     error
-	client  *types.MonitorClient
-	mConfig = types.MonitorConfig{}
+	err            error
+	client         *types.MonitorClient
+	mConfig        = types.MonitorConfig{}
+	configFilePath string
+	// agentClients are clients of the running agents by name. A reload reuses them while the agent's connection is unchanged.
+	// It's replaced in main goroutine only, and read by the health endpoint under agentClientsMu.
+	agentClients   = map[string]agentClient{}
+	agentClientsMu sync.RWMutex
 )
 
 func init() {
-	types.MonitorRegistry = map[string]types.Func{
-		"net_info":     {monitor.NetInfoMonitor, nil},
-		"block_commit": {monitor.BlockCommitMonitor, nil},
-		"status":       {monitor.CometBFTStatusMonitor, nil},
-	}
-
-	var configBytes []byte
-
-	configFilePath := os.Getenv(types.EnvConfigFilePath)
+	// Monitors register themselves in init() of package monitor.
+	configFilePath = os.Getenv(types.EnvConfigFilePath)
 	if configFilePath == "" {
 		configFilePath = "resources/config.yaml"
 	}
@@ -41,22 +47,14 @@ func init() {
 		configFilePath = filepath.Join(pwd, configFilePath)
 	}
 
-	configBytes, err = os.ReadFile(configFilePath)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = yaml.Unmarshal(configBytes, &mConfig)
+	cfg, err := loadConfig(configFilePath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	mConfig = *cfg
 
-	err = mConfig.ApplyConfigFromEnvAndDefault()
-	if err != nil {
-		log.Fatal(errors.New("Error occurred while parsing env. " + err.Error()))
-	}
-
-	client = types.NewMonitorClient(&mConfig, &http.Client{Timeout: *mConfig.Agent.Timeout}, configFilePath)
+	// Every agent shares the http client. Requests are bounded by each agent's own timeout.
+	client = types.NewMonitorClient(&mConfig, &http.Client{}, configFilePath)
 
 	logLevelDebug := flag.Bool("debug", false, "allow showing debug log")
 
@@ -71,35 +69,230 @@ func init() {
 }
 
 func main() {
-	log.Info("Starting... Agent: " + mConfig.Agent.AgentName + ", Service: " + _const.HARVESTMON_TENDERMINT_SERVICE_NAME + ", CommitId: " + mConfig.Agent.CommitId)
-
-	var (
-		wg   sync.WaitGroup
-		svcs = mConfig.Agent.Monitors
-	)
-
-	ticker := time.NewTicker(*mConfig.Agent.PushInterval)
-	done := make(chan bool)
-	for _, mon := range svcs {
-		wg.Add(1)
-		if mon.Interval != nil && *mon.Interval > 0 {
-			ticker = time.NewTicker(*mon.Interval)
+	if flag.Arg(0) == backfillCommand {
+		err = backfill(flag.Args()[1:])
+		if err != nil {
+			log.Fatal(err)
 		}
-		go func(monitor types.Monitor) {
-			monitor.Run(&mConfig, client)
-			defer wg.Done()
-			for {
-				select {
-				case <-done:
-					return
-				case <-ticker.C:
-					monitor.Run(&mConfig, client)
-				}
+		return
+	}
+	if flag.Arg(0) == collectCommand {
+		err = collect(flag.Args()[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	sched := scheduler.New()
+	jobs, clients, err := newJobs(&mConfig, agentClients)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, job := range jobs {
+		err = sched.Add(job)
+		if err != nil {
+			closeJobs(jobs)
+			log.Fatal(err)
+		}
+	}
+	agentClientsMu.Lock()
+	agentClients = clients
+	agentClientsMu.Unlock()
+	for _, agent := range mConfig.MonitoringAgents() {
+		log.Info("Starting... Agent: " + agent.AgentName + ", Service: " + _const.HARVESTMON_TENDERMINT_SERVICE_NAME + ", CommitId: " + agent.CommitId)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	sched.Start(ctx)
+	if mConfig.Health.Address != "" {
+		go func() {
+			err := health.Serve(ctx, mConfig.Health.Address, healthSource(sched))
+			if err != nil {
+				log.Error(errors.New("Could not serve health endpoint. err: " + err.Error()))
 			}
-		}(mon)
+		}()
 	}
-	wg.Wait()
-	ticker.Stop()
+	// Blocks until shutdown, reloading config meanwhile.
+	watchConfig(ctx, sched)
+
+	log.Info(fmt.Sprintf("Shutting down... waiting up to %v for monitors to flush pending records", *mConfig.DrainTimeout))
+	err = sched.Stop(*mConfig.DrainTimeout)
+	if err != nil {
+		log.Error(err)
+	}
+	deregisterAgents(&mConfig, agentClients)
+	if client.WAL() != nil {
+		err = client.WAL().Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}
+	log.Info("Shutdown complete. Agents: " + strings.Join(agentNamesOf(&mConfig), ", "))
 
 	return
 }
+
+// agentRegistrationInterval is how often agents are registered again, which refreshes their metadata.
+const agentRegistrationInterval = 10 * time.Minute
+
+type agentClient struct {
+	agent  types.MonitoringAgent
+	client *types.MonitorClient
+}
+
+// newJobs returns jobs of every monitor of every agent and the wal replay. Monitors of the jobs are Init-ed,
+// and they're closed by the scheduler once unscheduled. Use closeJobs when the jobs aren't handed to the scheduler.
+// A client in clients is reused when the agent's connection settings are unchanged.
+func newJobs(cfg *types.MonitorConfig, clients map[string]agentClient) (jobs []scheduler.Job, newClients map[string]agentClient, err error) {
+	agents := cfg.MonitoringAgents()
+	newClients = make(map[string]agentClient, len(agents))
+	defer func() {
+		if err != nil {
+			closeJobs(jobs)
+		}
+	}()
+
+	for _, agent := range agents {
+		agentConfig := cfg.ForAgent(agent)
+
+		prev, exists := clients[agent.AgentName]
+		if !exists || !sameConnection(prev.agent, agentConfig.Agent) {
+			c, err := client.ForAgent(&agentConfig.Agent)
+			if err != nil {
+				return jobs, nil, err
+			}
+			prev = agentClient{client: c}
+		}
+		monitorClient := prev.client
+		newClients[agent.AgentName] = agentClient{agent: agentConfig.Agent, client: monitorClient}
+
+		registrationName := "agent_registration"
+		if len(agents) > 1 {
+			registrationName = agent.AgentName + "/" + registrationName
+		}
+		jobs = append(jobs, scheduler.Job{
+			Name:     registrationName,
+			Interval: agentRegistrationInterval,
+			Run: func(ctx context.Context) error {
+				return monitor.RegisterAgent(ctx, agentConfig, monitorClient)
+			},
+		})
+
+		// Every monitor of every agent is a job of its own, so a dead node only delays its own monitors.
+		for _, spec := range agent.Monitors {
+			interval := *agent.PushInterval
+			if spec.Interval != nil && *spec.Interval > 0 {
+				interval = *spec.Interval
+			}
+			jitter := *agent.Jitter
+			if spec.Jitter != nil {
+				jitter = *spec.Jitter
+			}
+
+			name := spec.Name
+			if len(agents) > 1 {
+				name = agent.AgentName + "/" + spec.Name
+			}
+
+			mon, err := types.NewMonitor(spec)
+			if err != nil {
+				return jobs, nil, err
+			}
+			err = mon.Init(context.Background(), agentConfig, monitorClient)
+			if err != nil {
+				return jobs, nil, fmt.Errorf("could not init monitor(%s): %w", name, err)
+			}
+			jobs = append(jobs, scheduler.Job{
+				Name:     name,
+				Interval: interval,
+				Jitter:   jitter,
+				Run:      mon.Run,
+				Close:    mon.Close,
+			})
+		}
+	}
+
+	// Records buffered while the database was unreachable are replayed by a job of their own.
+	if client.WAL() != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "wal_replay",
+			Interval: *cfg.Wal.ReplayInterval,
+			Run: func(ctx context.Context) error {
+				return monitor.ReplayWAL(ctx, cfg, client)
+			},
+		})
+	}
+
+	return jobs, newClients, nil
+}
+
+// closeJobs closes monitors of the jobs which never got to the scheduler.
+func closeJobs(jobs []scheduler.Job) {
+	for _, job := range jobs {
+		if job.Close == nil {
+			continue
+		}
+		err := job.Close()
+		if err != nil {
+			log.Error(errors.New("Could not close monitor " + job.Name + ". err: " + err.Error()))
+		}
+	}
+}
+
+// sameConnection reports whether the agents query their node the same way, so that a client can be shared.
+func sameConnection(a, b types.MonitoringAgent) bool {
+	return reflect.DeepEqual(a.Endpoints, b.Endpoints) &&
+		reflect.DeepEqual(a.Timeout, b.Timeout) &&
+		a.BlockCommitMaxConcurrency == b.BlockCommitMaxConcurrency
+}
+
+func healthSource(sched *scheduler.Scheduler) health.Source {
+	pingDB := client.DB.PingContext
+	if client.Sink() != nil {
+		pingDB = client.Sink().Ping
+	}
+
+	return health.Source{
+		Jobs: sched.AllStats,
+		Endpoints: func() map[string][]types.EndpointStatus {
+			agentClientsMu.RLock()
+			defer agentClientsMu.RUnlock()
+
+			result := make(map[string][]types.EndpointStatus, len(agentClients))
+			for name, c := range agentClients {
+				result[name] = c.client.EndpointStatus()
+			}
+			return result
+		},
+		PingDB:   pingDB,
+		DBWrites: health.DBWrites,
+		WAL: func() *wal.Stats {
+			if client.WAL() == nil {
+				return nil
+			}
+			stats := client.WAL().Stats()
+			return &stats
+		},
+	}
+}
+
+// deregisterAgents marks the agents inactive.
+func deregisterAgents(cfg *types.MonitorConfig, clients map[string]agentClient) {
+	for _, c := range clients {
+		err := monitor.DeregisterAgent(cfg.ForAgent(c.agent), c.client)
+		if err != nil {
+			log.Error(errors.New("Could not deregister agent " + c.agent.AgentName + ". err: " + err.Error()))
+		}
+	}
+}
+
+func agentNamesOf(cfg *types.MonitorConfig) []string {
+	var names []string
+	for _, agent := range cfg.MonitoringAgents() {
+		names = append(names, agent.AgentName)
+	}
+	return names
+}
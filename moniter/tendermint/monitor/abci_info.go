@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitorFunc("abci_info", AbciInfoMonitor)
+}
+
+func AbciInfoMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	abciInfo, err := client.GetAbciInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		appVersion      uint64
+		lastBlockHeight uint64
+	)
+	if abciInfo.Result.Response.AppVersion != "" {
+		appVersion, err = strconv.ParseUint(abciInfo.Result.Response.AppVersion, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+	if abciInfo.Result.Response.LastBlockHeight != "" {
+		lastBlockHeight, err = strconv.ParseUint(abciInfo.Result.Response.LastBlockHeight, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	err = abciInfoWriter.Save(ctx, c, client, repository.TendermintAbciInfo{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_ABCI_INFO_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: abciInfo.Endpoint,
+		},
+		AppName:         abciInfo.Result.Response.Data,
+		Version:         abciInfo.Result.Response.Version,
+		AppVersion:      appVersion,
+		LastBlockHeight: lastBlockHeight,
+		// Stored as upper hex like the other hashes.
+		LastBlockAppHash: strings.ToUpper(hex.EncodeToString(abciInfo.Result.Response.LastBlockAppHash)),
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[abci_info] app: %s, version: %s, app_version: %d, last_block_height: %d",
+		abciInfo.Result.Response.Data, abciInfo.Result.Response.Version, appVersion, lastBlockHeight))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"context"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+)
+
+// RegisterAgent upserts the agent with its service link and commit record, which events of the agent refer to.
+// It's run periodically, so metadata changes are refreshed and a registration which failed is retried.
+func RegisterAgent(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	// The primary endpoint represents the agent.
+	var agent = repository.Agent{
+		AgentName: c.Agent.AgentName,
+		Host:      c.Agent.Host,
+		Port:      c.Agent.Port,
+		Platform:  c.Agent.Platform,
+		Location:  c.Agent.Location,
+	}
+	if len(c.Agent.Endpoints) > 0 {
+		agent.Host = c.Agent.Endpoints[0].Host
+		agent.Port = c.Agent.Endpoints[0].Port
+	}
+
+	// It isn't buffered in the wal, since it's retried periodically anyway.
+	err := agentWriter.saveUnbuffered(ctx, c, client, agentRecord{Agent: agent, ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME, CommitId: c.Agent.CommitId})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
+
+// DeregisterAgent marks the agent inactive when its monitoring stops. (e.g. shutdown, removed from config)
+func DeregisterAgent(c *types.MonitorConfig, client *types.MonitorClient) error {
+	// It isn't buffered in the wal, or it could deactivate the agent registered again on the next start.
+	// The monitors have been stopped already, so it isn't bound to their context.
+	err := agentDeactivationWriter.saveUnbuffered(context.Background(), c, client, agentDeactivationRecord{AgentName: c.Agent.AgentName, CommitId: c.Agent.CommitId})
+	if err != nil {
+		return err
+	}
+
+	log.Info("[agent] deregistered " + c.Agent.AgentName)
+	return nil
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"strconv"
+)
+
+// BackfillBlockCommits stores commits of [startHeight, endHeight] which the agent hasn't stored yet, chunkSize heights at a time.
+// Progress is saved after every chunk, so running it again with the same range resumes from the first unfinished chunk.
+func BackfillBlockCommits(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, startHeight, endHeight uint64, chunkSize uint64) error {
+	if startHeight == 0 || endHeight < startHeight {
+		return errors.New(fmt.Sprintf("invalid height range: [%d, %d]", startHeight, endHeight))
+	}
+	if chunkSize == 0 {
+		return errors.New("chunk size must be greater than 0")
+	}
+	if client.Sink() != nil {
+		return errors.New("backfill needs the database. run it where the database is reachable instead of in sink mode")
+	}
+
+	var (
+		db                 = client.GetDatabase(c.DbBatchSize)
+		commitRepository   = repository.CommitRepository{BaseRepository: repository.BaseRepository{DB: *db, CommitId: c.Agent.CommitId}}
+		backfillRepository = repository.BackfillRepository{BaseRepository: repository.BaseRepository{DB: *db, CommitId: c.Agent.CommitId}}
+	)
+
+	status, err := client.GetCometBFTStatus(ctx)
+	if err != nil {
+		return err
+	}
+	latestHeight, err := strconv.ParseUint(status.Result.SyncInfo.LatestBlockHeight, 0, 64)
+	if err != nil {
+		return err
+	}
+	// Commit of the latest height can still change, so it's left to BlockCommitMonitor.
+	if endHeight >= latestHeight {
+		return errors.New(fmt.Sprintf("end height %d must be lower than the latest height %d", endHeight, latestHeight))
+	}
+
+	nextHeight := startHeight
+	progress, err := backfillRepository.FindBlockCommitBackfill(c.Agent.AgentName, startHeight, endHeight)
+	if err != nil {
+		return err
+	}
+	if progress != nil && progress.NextHeight > startHeight {
+		nextHeight = progress.NextHeight
+		log.Info(fmt.Sprintf("[backfill] resuming [%d, %d] from height %d", startHeight, endHeight, nextHeight))
+	}
+
+	var fetched, skipped int
+	for nextHeight <= endHeight {
+		if ctx.Err() != nil {
+			log.Info(fmt.Sprintf("[backfill] interrupted. run again with the same range to resume from height %d", nextHeight))
+			return nil
+		}
+
+		chunkEnd := min(nextHeight+chunkSize-1, endHeight)
+
+		storedHeights, err := commitRepository.FindStoredHeights(c.Agent.AgentName, nextHeight, chunkEnd)
+		if err != nil {
+			return err
+		}
+		missingHeights := missingHeightsOf(nextHeight, chunkEnd, storedHeights)
+		skipped += int(chunkEnd-nextHeight+1) - len(missingHeights)
+
+		tcRecords := fetchCommitsOfHeights(ctx, c, client, missingHeights)
+		if len(tcRecords) > 0 {
+			err = commitRepository.CreateBatch(tcRecords)
+			if err != nil {
+				return err
+			}
+		}
+		fetched += len(tcRecords)
+
+		// Heights which failed to be fetched are stored on the next run, since stored heights are skipped.
+		if len(tcRecords) < len(missingHeights) {
+			if ctx.Err() != nil {
+				continue
+			}
+			return errors.New(fmt.Sprintf("[backfill] failed to fetch %d of %d heights in [%d, %d]. run again with the same range to retry", len(missingHeights)-len(tcRecords), len(missingHeights), nextHeight, chunkEnd))
+		}
+
+		nextHeight = chunkEnd + 1
+		err = backfillRepository.SaveBlockCommitBackfill(c.Agent.AgentName, startHeight, endHeight, nextHeight)
+		if err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("[backfill] stored until height %d of %d. fetched: %d, skipped: %d", chunkEnd, endHeight, fetched, skipped))
+	}
+
+	log.Info(fmt.Sprintf("[backfill] completed [%d, %d]. fetched: %d, skipped: %d", startHeight, endHeight, fetched, skipped))
+	return nil
+}
+
+// missingHeightsOf returns heights of [startHeight, endHeight] which aren't in storedHeights.
+func missingHeightsOf(startHeight, endHeight uint64, storedHeights []uint64) []uint64 {
+	stored := make(map[uint64]struct{}, len(storedHeights))
+	for _, height := range storedHeights {
+		stored[height] = struct{}{}
+	}
+
+	var missing []uint64
+	for height := startHeight; height <= endHeight; height++ {
+		if _, exists := stored[height]; !exists {
+			missing = append(missing, height)
+		}
+	}
+	return missing
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	_const "github.com/b-harvest/Harvestmon/const"
@@ -10,26 +11,49 @@ import (
 	"github.com/b-harvest/Harvestmon/util"
 	"github.com/google/uuid"
 	"strconv"
-	"sync"
 	"time"
 )
 
-func BlockCommitMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
+func init() {
+	types.RegisterMonitorFunc("block_commit", BlockCommitMonitor)
+}
+
+func BlockCommitMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
 	_, _, fn := util.TraceFirst()
 	log.Debug("Starting monitor: " + fn)
 
-	commitMonitorRepository := repository.CommitRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(c.DbBatchSize)}}
+	// Polling also fills the gap since the latest stored height before subscribing.
+	latestHeight, err := pollBlockCommits(ctx, c, client)
+	if err != nil {
+		return err
+	}
+
+	if c.Agent.BlockCommitMode == types.BlockCommitModeWebsocket && ctx.Err() == nil {
+		// Runs until shutdown or disconnection. Scheduler will skip this monitor meanwhile,
+		// and the next scheduled run after disconnection reconnects it.
+		err = streamBlockCommits(ctx, c, client, latestHeight-1)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
 
-	status, err := client.GetCometBFTStatus()
+// pollBlockCommits stores commits from the height after latest stored one to the latest height - 1.
+// It returns the latest height of the node, or the height after latest stored one when the node is behind it.
+func pollBlockCommits(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) (uint64, error) {
+	status, err := client.GetCometBFTStatus(ctx)
 	if err != nil {
-		log.Error(err)
+		return 0, err
 	}
-	latestHeight, err := strconv.ParseUint(status.SyncInfo.LatestBlockHeight, 0, 64)
+	latestHeight, err := strconv.ParseUint(status.Result.SyncInfo.LatestBlockHeight, 0, 64)
 	if err != nil {
-		log.Error(err)
+		return 0, err
 	}
 
-	startHeight, err := commitMonitorRepository.FetchHighestHeight(c.Agent.AgentName, c.Agent.CommitId)
+	startHeight, err := highestCommitHeightQuery.Do(ctx, c, client, agentArgs{AgentName: c.Agent.AgentName, CommitId: c.Agent.CommitId})
 	if err != nil {
 		log.Debug(err.Error())
 		startHeight = latestHeight - 1
@@ -38,62 +62,125 @@ func BlockCommitMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
 		startHeight++
 	}
 
+	// A lagging endpoint, e.g. a backup one after failover, may report a height lower than the stored one.
+	if latestHeight <= startHeight {
+		log.Debug(fmt.Sprintf("[block_commit] latest height %d of %s is not after the stored one. nothing to fetch", latestHeight, status.Endpoint))
+		return startHeight, nil
+	}
+
 	if (latestHeight - startHeight) > (uint64(c.Agent.PushInterval.Seconds()) * 200) {
 		startHeight = latestHeight - (uint64(c.Agent.PushInterval.Seconds()) * 200)
 		log.Info(fmt.Sprintf("[block_commit] distance from startHeight to latestHeight is too large. automatically set startHeight as %d", startHeight))
 	}
 
-	var (
-		wg         sync.WaitGroup
-		recordChan = make(chan repository.TendermintCommit, latestHeight-startHeight)
-	)
-	semaphore := make(chan struct{}, c.Agent.BlockCommitMaxConcurrency)
+	tcRecords := fetchCommits(ctx, c, client, startHeight, latestHeight)
+	if len(tcRecords) == 0 {
+		return latestHeight, nil
+	}
 
-	for i := startHeight; i < latestHeight; i++ {
-		wg.Add(1)
-		go processHeight(i, client, recordChan, c, &wg, semaphore)
+	err = blockCommitWriter.Save(ctx, c, client, tcRecords)
+	if err != nil {
+		return 0, err
 	}
 
-	go func() {
-		wg.Wait()
-		close(recordChan)
-	}()
+	return latestHeight, nil
+}
 
-	var tcRecords []repository.TendermintCommit
-	for record := range recordChan {
-		tcRecords = append(tcRecords, record)
-	}
+// streamBlockCommits stores commits as NewBlock events arrive through websocket.
+// Block H carries the commit of H-1, so the commit is built with the header of H-1 from the previous event.
+// Heights which can't be built from events (e.g. first event, missed events) are fetched over HTTP.
+func streamBlockCommits(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, storedHeight uint64) error {
+	var prevHeader *types.Header
 
-	err = commitMonitorRepository.CreateBatch(tcRecords)
-	if err != nil {
-		log.Error(err)
+	return client.SubscribeNewBlock(ctx, func(event *types.NewBlockEvent) error {
+		height, err := strconv.ParseUint(event.Block.Header.Height, 0, 64)
+		if err != nil {
+			return err
+		}
+		header := event.Block.Header
+		defer func() { prevHeader = &header }()
+
+		commitHeight := height - 1
+		if commitHeight <= storedHeight {
+			return nil
+		}
+
+		var tcRecords []repository.TendermintCommit
+		if prevHeader != nil && prevHeader.Height == strconv.FormatUint(commitHeight, 10) && event.Block.LastCommit != nil {
+			if commitHeight > storedHeight+1 {
+				tcRecords = fetchCommits(ctx, c, client, storedHeight+1, commitHeight)
+			}
+			record, err := newTendermintCommit(c, prevHeader, event.Block.LastCommit, event.Endpoint)
+			if err != nil {
+				return err
+			}
+			tcRecords = append(tcRecords, record)
+			log.Info(fmt.Sprintf("[block_commit] height: %v, signature count: %d", commitHeight, len(record.Signatures)))
+		} else {
+			tcRecords = fetchCommits(ctx, c, client, storedHeight+1, commitHeight+1)
+		}
+
+		if len(tcRecords) == 0 {
+			return nil
+		}
+		err = blockCommitWriter.Save(ctx, c, client, tcRecords)
+		if err != nil {
+			return err
+		}
+		storedHeight = commitHeight
+		return nil
+	})
+}
+
+// fetchCommits fetches commits of [startHeight, endHeight) over HTTP concurrently.
+func fetchCommits(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, startHeight, endHeight uint64) []repository.TendermintCommit {
+	if endHeight <= startHeight {
+		return nil
 	}
 
-	log.Debug("Complete monitor: " + fn)
+	heights := make([]uint64, 0, endHeight-startHeight)
+	for i := startHeight; i < endHeight; i++ {
+		heights = append(heights, i)
+	}
+	return fetchCommitsOfHeights(ctx, c, client, heights)
 }
 
-func processHeight(i uint64, client *types.MonitorClient, recordChan chan repository.TendermintCommit, c *types.MonitorConfig, wg *sync.WaitGroup, semaphore chan struct{}) {
-	defer wg.Done()
-	semaphore <- struct{}{}        // Acquire a spot in the semaphore
-	defer func() { <-semaphore }() // Release the spot in the semaphore when done
+// fetchCommitsOfHeights fetches commits of the heights. Heights which failed to be fetched are logged and left out.
+func fetchCommitsOfHeights(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, heights []uint64) []repository.TendermintCommit {
+	if len(heights) == 0 {
+		return nil
+	}
 
-	commit, err := client.GetCommitWithHeight(i)
-	if err != nil {
-		log.Error(errors.New(fmt.Sprintf("Error fetching commit: %v", err)))
-		return
+	var tcRecords []repository.TendermintCommit
+	for _, commit := range client.GetCommitsOfHeights(ctx, heights) {
+		record, err := newTendermintCommit(c, commit.Result.Header, commit.Result.Commit, commit.Endpoint)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		tcRecords = append(tcRecords, record)
+
+		log.Info(fmt.Sprintf("[block_commit] height: %v, signature count: %d", record.Height, len(record.Signatures)))
 	}
 
+	// Records which are already fetched will be flushed by caller.
+	if ctx.Err() != nil {
+		log.Info(fmt.Sprintf("[block_commit] shutting down. fetched %d of %d commits", len(tcRecords), len(heights)))
+	}
+	return tcRecords
+}
+
+func newTendermintCommit(c *types.MonitorConfig, header *types.Header, commit *types.Commit, endpoint string) (repository.TendermintCommit, error) {
 	eventUUID, err := uuid.NewUUID()
 	if err != nil {
-		log.Error(errors.New(fmt.Sprintf("Error generating UUID: %v", err)))
-		return
+		return repository.TendermintCommit{}, errors.New(fmt.Sprintf("Error generating UUID: %v", err))
 	}
 
 	createdAt := time.Now().UTC()
 
 	var signatures []repository.TendermintCommitSignature
 
-	for _, signature := range commit.Result.SignedHeader.Commit.Signatures {
+	for _, signature := range commit.Signatures {
 		if signature.ValidatorAddress == "" {
 			continue
 		}
@@ -107,7 +194,7 @@ func processHeight(i uint64, client *types.MonitorClient, recordChan chan reposi
 		})
 	}
 
-	result := repository.TendermintCommit{
+	return repository.TendermintCommit{
 		CreatedAt: createdAt,
 		EventUUID: eventUUID.String(),
 		Event: repository.Event{
@@ -117,29 +204,23 @@ func processHeight(i uint64, client *types.MonitorClient, recordChan chan reposi
 			CommitID:    c.Agent.CommitId,
 			EventType:   _const.TM_COMMIT_EVENT_TYPE,
 			CreatedAt:   createdAt,
+			RpcEndpoint: endpoint,
 		},
-		ChainID:            commit.Result.ChainID,
-		Height:             commit.Result.Height,
-		Time:               commit.Result.Time,
-		LastBlockIdHash:    commit.Result.LastBlockID.Hash,
-		LastCommitHash:     commit.Result.LastCommitHash,
-		DataHash:           commit.Result.DataHash,
-		ValidatorsHash:     commit.Result.ValidatorsHash,
-		NextValidatorsHash: commit.Result.NextValidatorsHash,
-		ConsensusHash:      commit.Result.ConsensusHash,
-		AppHash:            commit.Result.AppHash,
-		LastResultsHash:    commit.Result.LastResultsHash,
-		EvidenceHash:       commit.Result.EvidenceHash,
-		ProposerAddress:    commit.Result.ProposerAddress,
-		Round:              commit.Result.Commit.Round,
-		CommitBlockIdHash:  commit.Result.Commit.BlockID.Hash,
+		ChainID:            header.ChainID,
+		Height:             header.Height,
+		Time:               header.Time,
+		LastBlockIdHash:    header.LastBlockID.Hash,
+		LastCommitHash:     header.LastCommitHash,
+		DataHash:           header.DataHash,
+		ValidatorsHash:     header.ValidatorsHash,
+		NextValidatorsHash: header.NextValidatorsHash,
+		ConsensusHash:      header.ConsensusHash,
+		AppHash:            header.AppHash,
+		LastResultsHash:    header.LastResultsHash,
+		EvidenceHash:       header.EvidenceHash,
+		ProposerAddress:    header.ProposerAddress,
+		Round:              commit.Round,
+		CommitBlockIdHash:  commit.BlockID.Hash,
 		Signatures:         signatures,
-	}
-
-	recordChan <- result
-
-	log.Info(fmt.Sprintf("[block_commit] height: %v, signature count: %d", i, len(signatures)))
-	if err != nil {
-		log.Error(errors.New(fmt.Sprintf("Error saving commit: %v", err)))
-	}
+	}, nil
 }
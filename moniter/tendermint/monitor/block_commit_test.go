@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPollBlockCommits(t *testing.T) {
+
+	// newNode serves /status of the latest height, and records every other path requested.
+	newNode := func(t *testing.T, latestHeight uint64) (types.MonitoringAgent, *[]string) {
+		var (
+			mu        sync.Mutex
+			requested []string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/status" {
+				fmt.Fprintf(w, `{"result":{"sync_info":{"latest_block_height":"%d"}}}`, latestHeight)
+				return
+			}
+			mu.Lock()
+			requested = append(requested, r.URL.Path)
+			mu.Unlock()
+			http.NotFound(w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		host, port, _ := strings.Cut(strings.TrimPrefix(server.URL, "http://"), ":")
+		portNumber, err := strconv.Atoi(port)
+		assert.NoError(t, err)
+		return types.MonitoringAgent{AgentName: "node-a", Host: host, Port: portNumber, CommitId: "19ge4rgndfifji"}, &requested
+	}
+
+	// storedHeight is served as the highest stored commit height.
+	backendOf := func(storedHeight uint64, written *int) collector.Backend {
+		return collector.Backend{
+			Query: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+				return storedHeight, nil
+			},
+			Write: func(ctx context.Context, record collector.Record) error {
+				*written++
+				return nil
+			},
+		}
+	}
+
+	t.Run("endpoint behind the stored height", func(t *testing.T) {
+		agent, requested := newNode(t, 100)
+		var written int
+		c, client := newSinkClient(t, agent, http.DefaultClient, backendOf(120, &written))
+
+		height, err := pollBlockCommits(context.Background(), c, client)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(121), height)
+		assert.Empty(t, *requested)
+		assert.Equal(t, 0, written)
+	})
+
+	t.Run("endpoint at the stored height", func(t *testing.T) {
+		agent, requested := newNode(t, 121)
+		var written int
+		c, client := newSinkClient(t, agent, http.DefaultClient, backendOf(120, &written))
+
+		height, err := pollBlockCommits(context.Background(), c, client)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(121), height)
+		assert.Empty(t, *requested)
+		assert.Equal(t, 0, written)
+	})
+
+}
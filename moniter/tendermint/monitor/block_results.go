@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// blockEventTypes are the block-level events stored along with block results.
+var blockEventTypes = map[string]bool{
+	"slash":    true,
+	"jail":     true,
+	"liveness": true,
+}
+
+func init() {
+	types.RegisterMonitorFunc("block_results", BlockResultsMonitor)
+}
+
+// BlockResultsMonitor stores block results of the heights which block_commit monitor has already stored.
+func BlockResultsMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	args := agentArgs{AgentName: c.Agent.AgentName, CommitId: c.Agent.CommitId}
+
+	endHeight, err := highestCommitHeightQuery.Do(ctx, c, client, args)
+	if err != nil || endHeight == 0 {
+		log.Debug(fmt.Sprintf("[block_results] no commit has been stored yet. err: %v", err))
+		log.Debug("Complete monitor: " + fn)
+		return nil
+	}
+
+	startHeight, err := highestBlockResultsHeightQuery.Do(ctx, c, client, args)
+	if err != nil || startHeight == 0 {
+		startHeight = endHeight
+	} else {
+		// Start after latest stored block results height.
+		startHeight++
+	}
+
+	if endHeight >= startHeight && (endHeight-startHeight) > (uint64(c.Agent.PushInterval.Seconds())*200) {
+		startHeight = endHeight - (uint64(c.Agent.PushInterval.Seconds()) * 200)
+		log.Info(fmt.Sprintf("[block_results] distance from startHeight to endHeight is too large. automatically set startHeight as %d", startHeight))
+	}
+
+	var (
+		wg         sync.WaitGroup
+		recordChan = make(chan repository.TendermintBlockResults, endHeight-startHeight+1)
+	)
+	semaphore := make(chan struct{}, c.Agent.BlockCommitMaxConcurrency)
+
+	for i := startHeight; i <= endHeight; i++ {
+		// Stop spawning new fetches on shutdown. Records which are already fetched will be flushed below.
+		if ctx.Err() != nil {
+			log.Info(fmt.Sprintf("[block_results] shutting down. stop fetching at height %d", i))
+			break
+		}
+		wg.Add(1)
+		go processBlockResults(ctx, i, client, recordChan, c, &wg, semaphore)
+	}
+
+	go func() {
+		wg.Wait()
+		close(recordChan)
+	}()
+
+	var records []repository.TendermintBlockResults
+	for record := range recordChan {
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		log.Debug("Complete monitor: " + fn)
+		return nil
+	}
+
+	err = blockResultsWriter.Save(ctx, c, client, records)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
+
+func processBlockResults(ctx context.Context, i uint64, client *types.MonitorClient, recordChan chan repository.TendermintBlockResults, c *types.MonitorConfig, wg *sync.WaitGroup, semaphore chan struct{}) {
+	defer wg.Done()
+	semaphore <- struct{}{}        // Acquire a spot in the semaphore
+	defer func() { <-semaphore }() // Release the spot in the semaphore when done
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	blockResults, err := client.GetBlockResultsWithHeight(ctx, i)
+	if err != nil {
+		log.Error(errors.New(fmt.Sprintf("Error fetching block results: %v", err)))
+		return
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		log.Error(errors.New(fmt.Sprintf("Error generating UUID: %v", err)))
+		return
+	}
+
+	createdAt := time.Now().UTC()
+
+	var (
+		failedTxCount      int
+		gasWanted, gasUsed int64
+	)
+	for _, txResult := range blockResults.Result.TxsResults {
+		if txResult.Code != 0 {
+			failedTxCount++
+		}
+		// gas could be empty for txs rejected before execution.
+		wanted, _ := strconv.ParseInt(txResult.GasWanted, 10, 64)
+		used, _ := strconv.ParseInt(txResult.GasUsed, 10, 64)
+		gasWanted += wanted
+		gasUsed += used
+	}
+
+	var blockEvents []repository.TendermintBlockEvent
+	for _, stage := range []struct {
+		name   string
+		events []types.ABCIEvent
+	}{
+		{"begin_block", blockResults.Result.BeginBlockEvents},
+		{"end_block", blockResults.Result.EndBlockEvents},
+		{"finalize_block", blockResults.Result.FinalizeBlockEvents},
+	} {
+		for _, event := range stage.events {
+			if !blockEventTypes[event.Type] {
+				continue
+			}
+			attributes, err := json.Marshal(event.Attributes)
+			if err != nil {
+				log.Error(errors.New(fmt.Sprintf("Error marshaling event attributes: %v", err)))
+				continue
+			}
+			blockEvents = append(blockEvents, repository.TendermintBlockEvent{
+				TendermintBlockResultsCreatedAt: createdAt,
+				EventUUID:                       eventUUID.String(),
+				EventIndex:                      len(blockEvents),
+				Stage:                           stage.name,
+				EventType:                       event.Type,
+				Attributes:                      string(attributes),
+			})
+		}
+	}
+
+	recordChan <- repository.TendermintBlockResults{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_BLOCK_RESULTS_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: blockResults.Endpoint,
+		},
+		Height:        i,
+		TxCount:       len(blockResults.Result.TxsResults),
+		FailedTxCount: failedTxCount,
+		GasWanted:     gasWanted,
+		GasUsed:       gasUsed,
+		BlockEvents:   blockEvents,
+	}
+
+	log.Info(fmt.Sprintf("[block_results] height: %v, tx count: %d, failed: %d, gas used: %d, events: %d", i, len(blockResults.Result.TxsResults), failedTxCount, gasUsed, len(blockEvents)))
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	_const "github.com/b-harvest/Harvestmon/const"
@@ -13,35 +14,37 @@ import (
 	"time"
 )
 
-func CometBFTStatusMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
+func init() {
+	types.RegisterMonitorFunc("status", CometBFTStatusMonitor)
+}
+
+func CometBFTStatusMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
 	_, _, fn := util.TraceFirst()
 	log.Debug("Starting monitor: " + fn)
 
-	statusMonitorRepository := repository.StatusRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(c.DbBatchSize)}}
-
-	cometBFTStatus, err := client.GetCometBFTStatus()
+	cometBFTStatus, err := client.GetCometBFTStatus(ctx)
 	if err != nil {
-		log.Error(err)
+		return err
 	}
 
 	eventUUID, err := uuid.NewUUID()
 	if err != nil {
-		log.Error(err)
+		return err
 	}
 	nodeInfoUUID, err := uuid.NewUUID()
 	if err != nil {
-		log.Error(err)
+		return err
 	}
 
 	createdAt := time.Now().UTC()
 
-	latestBlockHeight, err := strconv.ParseUint(cometBFTStatus.SyncInfo.LatestBlockHeight, 0, 64)
-	earliestBlockHeight, err := strconv.ParseUint(cometBFTStatus.SyncInfo.EarliestBlockHeight, 0, 64)
+	latestBlockHeight, err := strconv.ParseUint(cometBFTStatus.Result.SyncInfo.LatestBlockHeight, 0, 64)
+	earliestBlockHeight, err := strconv.ParseUint(cometBFTStatus.Result.SyncInfo.EarliestBlockHeight, 0, 64)
 	if err != nil {
-		log.Error(errors.New("Parsing error: " + cometBFTStatus.SyncInfo.LatestBlockHeight + ", " + cometBFTStatus.SyncInfo.EarliestBlockHeight + ". err: " + err.Error()))
+		log.Error(errors.New("Parsing error: " + cometBFTStatus.Result.SyncInfo.LatestBlockHeight + ", " + cometBFTStatus.Result.SyncInfo.EarliestBlockHeight + ". err: " + err.Error()))
 	}
 
-	err = statusMonitorRepository.Save(
+	err = statusWriter.Save(ctx, c, client,
 		repository.TendermintStatus{
 			CreatedAt: createdAt,
 			EventUUID: eventUUID.String(),
@@ -52,30 +55,33 @@ func CometBFTStatusMonitor(c *types.MonitorConfig, client *types.MonitorClient)
 				CommitID:    c.Agent.CommitId,
 				EventType:   _const.TM_STATUS_EVENT_TYPE,
 				CreatedAt:   createdAt,
+				RpcEndpoint: cometBFTStatus.Endpoint,
 			},
 			TendermintNodeInfoUUID: nodeInfoUUID.String(),
 			TendermintNodeInfo: repository.TendermintNodeInfo{
 				TendermintNodeInfoUUID: nodeInfoUUID.String(),
-				NodeId:                 string(cometBFTStatus.NodeInfo.DefaultNodeID),
-				ListenAddr:             cometBFTStatus.NodeInfo.ListenAddr,
-				ChainId:                cometBFTStatus.NodeInfo.Network,
-				Moniker:                cometBFTStatus.NodeInfo.Moniker,
+				NodeId:                 string(cometBFTStatus.Result.NodeInfo.DefaultNodeID),
+				ListenAddr:             cometBFTStatus.Result.NodeInfo.ListenAddr,
+				ChainId:                cometBFTStatus.Result.NodeInfo.Network,
+				Moniker:                cometBFTStatus.Result.NodeInfo.Moniker,
+				Version:                cometBFTStatus.Result.NodeInfo.Version,
 			},
-			LatestBlockHash:     string(cometBFTStatus.SyncInfo.LatestBlockHash),
-			LatestAppHash:       string(cometBFTStatus.SyncInfo.LatestAppHash),
+			LatestBlockHash:     string(cometBFTStatus.Result.SyncInfo.LatestBlockHash),
+			LatestAppHash:       string(cometBFTStatus.Result.SyncInfo.LatestAppHash),
 			LatestBlockHeight:   latestBlockHeight,
-			LatestBlockTime:     cometBFTStatus.SyncInfo.LatestBlockTime,
-			EarliestBlockHash:   string(cometBFTStatus.SyncInfo.EarliestBlockHash),
-			EarliestAppHash:     string(cometBFTStatus.SyncInfo.EarliestAppHash),
+			LatestBlockTime:     cometBFTStatus.Result.SyncInfo.LatestBlockTime,
+			EarliestBlockHash:   string(cometBFTStatus.Result.SyncInfo.EarliestBlockHash),
+			EarliestAppHash:     string(cometBFTStatus.Result.SyncInfo.EarliestAppHash),
 			EarliestBlockHeight: earliestBlockHeight,
-			EarliestBlockTime:   cometBFTStatus.SyncInfo.EarliestBlockTime,
-			CatchingUp:          cometBFTStatus.SyncInfo.CatchingUp,
+			EarliestBlockTime:   cometBFTStatus.Result.SyncInfo.EarliestBlockTime,
+			CatchingUp:          cometBFTStatus.Result.SyncInfo.CatchingUp,
 		})
 	if err != nil {
 		log.Warn(err.Error())
 	}
 
-	log.Info(fmt.Sprintf("[cometbft_status] catching_up: %t", cometBFTStatus.SyncInfo.CatchingUp))
+	log.Info(fmt.Sprintf("[cometbft_status] catching_up: %t", cometBFTStatus.Result.SyncInfo.CatchingUp))
 
 	log.Debug("Complete monitor: " + fn)
+	return nil
 }
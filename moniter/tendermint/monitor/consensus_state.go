@@ -0,0 +1,186 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &consensusStateMonitor{}
+	})
+}
+
+// consensusStateConfig is the `config` block of consensus_state.
+//
+//	monitors:
+//	  - name: consensus_state
+//	    config:
+//	      validatorAddress: "2A2B..."
+//	      dumpConsensusState: true
+type consensusStateConfig struct {
+	// ValidatorAddress is the hex address of the validator whose votes are looked for.
+	// When it's empty, the node's own validator(/status validator_info) will be used.
+	ValidatorAddress string `yaml:"validatorAddress"`
+	// DumpConsensusState makes the monitor use /dump_consensus_state,
+	// which matches votes by the validator's index rather than by address fingerprint, but is much heavier.
+	DumpConsensusState bool `yaml:"dumpConsensusState"`
+}
+
+type consensusStateMonitor struct {
+	config consensusStateConfig
+	c      *types.MonitorConfig
+	client *types.MonitorClient
+}
+
+func (m *consensusStateMonitor) Name() string {
+	return "consensus_state"
+}
+
+func (m *consensusStateMonitor) Config() any {
+	return &m.config
+}
+
+func (m *consensusStateMonitor) Init(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	m.c, m.client = c, client
+
+	if m.config.ValidatorAddress == "" {
+		v := os.Getenv(types.EnvValidatorAddress)
+		if v != "" {
+			m.config.ValidatorAddress = v
+			log.Debug("validatorAddress set as ENV: " + m.config.ValidatorAddress)
+		}
+	} else {
+		log.Debug("validatorAddress set as " + m.config.ValidatorAddress)
+	}
+
+	if !m.config.DumpConsensusState {
+		v := os.Getenv(types.EnvDumpConsensusState)
+		if v != "" {
+			dumpConsensusState, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("could not parse '%s' into a bool: %w", v, err)
+			}
+			m.config.DumpConsensusState = dumpConsensusState
+			log.Debug("dumpConsensusState set as ENV: " + strconv.FormatBool(m.config.DumpConsensusState))
+		}
+	}
+	return nil
+}
+
+func (m *consensusStateMonitor) Close() error {
+	return nil
+}
+
+func (m *consensusStateMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	// Votes are looked up only when the validator is in the active set.
+	validatorAddress := m.config.ValidatorAddress
+	if validatorAddress == "" {
+		status, err := client.GetCometBFTStatus(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Result.ValidatorInfo.VotingPower != "" && status.Result.ValidatorInfo.VotingPower != "0" {
+			validatorAddress = string(status.Result.ValidatorInfo.Address)
+		}
+	}
+
+	var (
+		consensusState repository.TendermintConsensusState
+		voteSets       []types.RoundVoteSet
+		validatorIndex = -1
+		endpoint       string
+	)
+	if m.config.DumpConsensusState {
+		dump, err := client.GetDumpConsensusState(ctx)
+		if err != nil {
+			return err
+		}
+		height, err := strconv.ParseUint(dump.Result.RoundState.Height, 0, 64)
+		if err != nil {
+			return err
+		}
+		consensusState.Height = height
+		consensusState.Round = dump.Result.RoundState.Round
+		consensusState.Step = dump.Result.RoundState.Step
+		consensusState.StartTime = dump.Result.RoundState.StartTime
+		voteSets = dump.Result.RoundState.Votes
+		endpoint = dump.Endpoint
+
+		for i, validator := range dump.Result.RoundState.Validators.Validators {
+			if strings.EqualFold(validator.Address, validatorAddress) {
+				validatorIndex = i
+				break
+			}
+		}
+		// The dump tells the exact set, so a configured validator out of the set is ignored too.
+		if validatorIndex < 0 {
+			validatorAddress = ""
+		}
+	} else {
+		state, err := client.GetConsensusState(ctx)
+		if err != nil {
+			return err
+		}
+		consensusState.Height, consensusState.Round, consensusState.Step, err = types.ParseHeightRoundStep(state.Result.RoundState.HeightRoundStep)
+		if err != nil {
+			return err
+		}
+		consensusState.StartTime = state.Result.RoundState.StartTime
+		voteSets = state.Result.RoundState.HeightVoteSet
+		endpoint = state.Endpoint
+	}
+
+	if voteSet, exists := types.VoteSetOf(voteSets, consensusState.Round); exists {
+		consensusState.PrevotesRatio = types.ParseVoteRatio(voteSet.PrevotesBitArray)
+		consensusState.PrecommitsRatio = types.ParseVoteRatio(voteSet.PrecommitsBitArray)
+		if validatorAddress != "" {
+			consensusState.Prevoted = types.HasVote(voteSet.Prevotes, validatorAddress, validatorIndex)
+			consensusState.Precommitted = types.HasVote(voteSet.Precommits, validatorAddress, validatorIndex)
+		}
+	}
+	consensusState.ValidatorAddress = validatorAddress
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	consensusState.CreatedAt = createdAt
+	consensusState.EventUUID = eventUUID.String()
+	consensusState.Event = repository.Event{
+		EventUUID:   eventUUID.String(),
+		AgentName:   c.Agent.AgentName,
+		ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+		CommitID:    c.Agent.CommitId,
+		EventType:   _const.TM_CONSENSUS_STATE_EVENT_TYPE,
+		CreatedAt:   createdAt,
+		RpcEndpoint: endpoint,
+	}
+
+	err = consensusStateWriter.Save(ctx, c, client, consensusState)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[consensus_state] height: %d, round: %d, step: %d, prevoted: %t, precommitted: %t",
+		consensusState.Height, consensusState.Round, consensusState.Step, consensusState.Prevoted, consensusState.Precommitted))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+)
+
+// cosmosMonitor is embedded by monitors querying the chain's Cosmos SDK API. Its `config` block is types.CosmosConfig.
+type cosmosMonitor struct {
+	// requiresValoper fails Init of a monitor looking for our validator when no valoper address is configured.
+	requiresValoper bool
+
+	config types.CosmosConfig
+	c      *types.MonitorConfig
+	client *types.MonitorClient
+	cosmos types.CosmosQueryClient
+}
+
+func (m *cosmosMonitor) Config() any {
+	return &m.config
+}
+
+func (m *cosmosMonitor) Init(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	m.c, m.client = c, client
+
+	err := m.config.ApplyConfigFromEnvAndDefault(c.Agent.Host)
+	if err != nil {
+		return err
+	}
+	if m.requiresValoper && m.config.ValoperAddress == "" {
+		return errors.New("valoperAddress is not configured. please set it through `config` of the monitor or env($VALOPER_ADDRESS)")
+	}
+
+	m.cosmos, err = client.NewCosmosClient(&m.config)
+	if err != nil {
+		return fmt.Errorf("cosmos: %w", err)
+	}
+	return nil
+}
+
+func (m *cosmosMonitor) Close() error {
+	if m.cosmos == nil {
+		return nil
+	}
+	return m.cosmos.Close()
+}
@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &cosmosValidatorMonitor{cosmosMonitor{requiresValoper: true}}
+	})
+}
+
+// cosmosValidatorMonitor stores our validator's staking and slashing state from the Cosmos SDK API.
+type cosmosValidatorMonitor struct {
+	cosmosMonitor
+}
+
+func (m *cosmosValidatorMonitor) Name() string {
+	return "cosmos_validator"
+}
+
+// EnabledByDefault reports whether our validator is set by env, without which the monitor has nothing to look for.
+func (m *cosmosValidatorMonitor) EnabledByDefault() bool {
+	return os.Getenv(types.EnvValoperAddress) != ""
+}
+
+func (m *cosmosValidatorMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	validator, err := m.cosmos.GetValidator(ctx, m.config.ValoperAddress)
+	if err != nil {
+		return err
+	}
+
+	consensusAddress, err := validator.ConsensusAddress()
+	if err != nil {
+		return err
+	}
+
+	signingInfo, err := m.cosmos.GetSigningInfo(ctx, consensusAddress)
+	if err != nil {
+		return err
+	}
+
+	params, err := m.cosmos.GetSlashingParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	err = cosmosValidatorWriter.Save(ctx, c, client, repository.CosmosValidator{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_COSMOS_VALIDATOR_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: m.config.Address,
+		},
+		OperatorAddress:         validator.OperatorAddress,
+		ConsensusAddress:        consensusAddress,
+		Status:                  validator.Status,
+		Jailed:                  validator.Jailed,
+		Tombstoned:              signingInfo.Tombstoned,
+		JailedUntil:             signingInfo.JailedUntil,
+		Tokens:                  validator.Tokens,
+		DelegatorShares:         validator.DelegatorShares,
+		CommissionRate:          validator.CommissionRate,
+		CommissionMaxRate:       validator.CommissionMaxRate,
+		CommissionMaxChangeRate: validator.CommissionMaxChangeRate,
+		StartHeight:             signingInfo.StartHeight,
+		IndexOffset:             signingInfo.IndexOffset,
+		MissedBlocksCounter:     signingInfo.MissedBlocksCounter,
+		SignedBlocksWindow:      params.SignedBlocksWindow,
+		MinSignedPerWindow:      params.MinSignedPerWindow,
+		DowntimeJailDuration:    int64(params.DowntimeJailDuration.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[cosmos_validator] status: %s, jailed: %t, tombstoned: %t, missed: %d/%d",
+		validator.Status, validator.Jailed, signingInfo.Tombstoned, signingInfo.MissedBlocksCounter, params.SignedBlocksWindow))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
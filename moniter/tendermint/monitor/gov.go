@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &govMonitor{cosmosMonitor{requiresValoper: true}}
+	})
+}
+
+// govMonitor stores proposals in voting period and whether our validator has voted on them.
+type govMonitor struct {
+	cosmosMonitor
+}
+
+func (m *govMonitor) Name() string {
+	return "gov"
+}
+
+// EnabledByDefault reports whether our validator is set by env, without which there is no vote to look for.
+func (m *govMonitor) EnabledByDefault() bool {
+	return os.Getenv(types.EnvValoperAddress) != ""
+}
+
+func (m *govMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	voter, err := m.config.AccountAddress()
+	if err != nil {
+		return err
+	}
+
+	proposals, err := m.cosmos.GetVotingProposals(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	var (
+		cosmosProposals []repository.CosmosProposal
+		notVoted        int
+	)
+	for _, proposal := range proposals {
+		vote, err := m.cosmos.GetVote(ctx, proposal.ProposalId, voter)
+		if err != nil {
+			return err
+		}
+
+		cosmosProposal := repository.CosmosProposal{
+			CreatedAt:       createdAt,
+			EventUUID:       eventUUID.String(),
+			ProposalId:      proposal.ProposalId,
+			Title:           proposal.Title,
+			Status:          proposal.Status,
+			VotingStartTime: proposal.VotingStartTime.UTC(),
+			VotingEndTime:   proposal.VotingEndTime.UTC(),
+			Voter:           voter,
+		}
+		if vote != nil {
+			cosmosProposal.Voted = true
+			cosmosProposal.VoteOption = strings.Join(vote.Options, ",")
+		} else {
+			notVoted++
+		}
+		cosmosProposals = append(cosmosProposals, cosmosProposal)
+	}
+
+	err = govWriter.Save(ctx, c, client, govRecord{
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_GOV_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: m.config.Address,
+		},
+		Proposals: cosmosProposals,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[gov] proposals in voting period: %d, not voted: %d", len(cosmosProposals), notVoted))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
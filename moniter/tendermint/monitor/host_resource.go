@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &hostResourceMonitor{}
+	})
+}
+
+// hostResourceConfig is the `config` block of host_resource.
+//
+//	monitors:
+//	  - name: host_resource
+//	    config:
+//	      procPath: /proc
+//	      diskPaths:
+//	        - /root/.gaia/data
+type hostResourceConfig struct {
+	// ProcPath is the mount point of procfs. Set it when the host's /proc is mounted elsewhere. (e.g. `/host/proc` in a container)
+	ProcPath string `yaml:"procPath"`
+	// DiskPaths are the paths whose filesystem usage is collected. (e.g. node's data dir)
+	// When it's empty, every mounted disk filesystem will be collected.
+	DiskPaths []string `yaml:"diskPaths"`
+}
+
+// hostResourceMonitor collects resources of the host which the monitor runs on.
+// It's meaningful only when the monitor runs on the node host.
+type hostResourceMonitor struct {
+	config hostResourceConfig
+	c      *types.MonitorConfig
+	client *types.MonitorClient
+}
+
+func (m *hostResourceMonitor) Name() string {
+	return "host_resource"
+}
+
+func (m *hostResourceMonitor) Config() any {
+	return &m.config
+}
+
+func (m *hostResourceMonitor) Init(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	m.c, m.client = c, client
+
+	if m.config.ProcPath == "" {
+		v := os.Getenv(types.EnvHostProcPath)
+		if v == "" {
+			m.config.ProcPath = types.DefaultHostProcPath
+			log.Debug("host proc path set as default: " + m.config.ProcPath)
+		} else {
+			m.config.ProcPath = v
+			log.Debug("host proc path set as ENV: " + m.config.ProcPath)
+		}
+	} else {
+		log.Debug("host proc path set as " + m.config.ProcPath)
+	}
+	if len(m.config.DiskPaths) == 0 {
+		v := os.Getenv(types.EnvHostDiskPaths)
+		if v != "" {
+			m.config.DiskPaths = strings.Split(v, ",")
+			log.Debug("host disk paths set as ENV: " + v)
+		}
+	} else {
+		log.Debug("host disk paths set as " + strings.Join(m.config.DiskPaths, ","))
+	}
+	return nil
+}
+
+func (m *hostResourceMonitor) Close() error {
+	return nil
+}
+
+func (m *hostResourceMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	hostStat, err := types.ReadHostStat(m.config.ProcPath)
+	if err != nil {
+		return err
+	}
+
+	diskStats, err := readDiskStats(m.config)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	var diskUsages []repository.DiskUsage
+	for _, diskStat := range diskStats {
+		diskUsages = append(diskUsages, repository.DiskUsage{
+			CreatedAt:   createdAt,
+			EventUUID:   eventUUID.String(),
+			Path:        diskStat.Path,
+			MountPoint:  diskStat.Mount.MountPoint,
+			Device:      diskStat.Mount.Device,
+			FsType:      diskStat.Mount.FsType,
+			TotalBytes:  diskStat.TotalBytes,
+			UsedBytes:   diskStat.UsedBytes,
+			AvailBytes:  diskStat.AvailBytes,
+			TotalInodes: diskStat.TotalInodes,
+			FreeInodes:  diskStat.FreeInodes,
+		})
+	}
+
+	hostResource := repository.HostResource{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_HOST_RESOURCE_EVENT_TYPE,
+			CreatedAt:   createdAt,
+		},
+		MemTotalBytes:  hostStat.MemTotalBytes,
+		MemAvailBytes:  hostStat.MemAvailBytes,
+		SwapTotalBytes: hostStat.SwapTotalBytes,
+		SwapFreeBytes:  hostStat.SwapFreeBytes,
+		Load1:          hostStat.Load1,
+		Load5:          hostStat.Load5,
+		Load15:         hostStat.Load15,
+		NumCPU:         hostStat.NumCPU,
+		OpenFds:        hostStat.OpenFds,
+		MaxFds:         hostStat.MaxFds,
+		DiskUsages:     diskUsages,
+	}
+
+	err = hostResourceWriter.Save(ctx, c, client, hostResource)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[host_resource] mem: %.2f, load1: %.2f, fds: %d/%d, disks: %d",
+		hostResource.MemUsageRatio(), hostStat.Load1, hostStat.OpenFds, hostStat.MaxFds, len(diskUsages)))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
+
+// readDiskStats reads usages of the configured paths, or every mounted disk when no path is configured.
+func readDiskStats(cfg hostResourceConfig) ([]types.DiskStat, error) {
+	var diskStats []types.DiskStat
+
+	mounts, err := types.ReadMounts(cfg.ProcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := cfg.DiskPaths
+	if len(paths) == 0 {
+		for _, mount := range mounts {
+			paths = append(paths, mount.MountPoint)
+		}
+	}
+
+	for _, path := range paths {
+		mount, ok := types.FindMount(mounts, path)
+		if !ok {
+			mount = types.Mount{MountPoint: path}
+		}
+
+		diskStat, err := types.ReadDiskStat(path, mount)
+		if err != nil {
+			return nil, err
+		}
+		diskStats = append(diskStats, *diskStat)
+	}
+
+	return diskStats, nil
+}
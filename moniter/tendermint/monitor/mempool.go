@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"strconv"
+	"time"
+)
+
+func init() {
+	types.RegisterMonitorFunc("mempool", MempoolMonitor)
+}
+
+func MempoolMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	unconfirmedTxs, err := client.GetNumUnconfirmedTxs(ctx)
+	if err != nil {
+		return err
+	}
+
+	nTxs, err := strconv.Atoi(unconfirmedTxs.Result.Count)
+	if err != nil {
+		return err
+	}
+	total, err := strconv.Atoi(unconfirmedTxs.Result.Total)
+	if err != nil {
+		return err
+	}
+	totalBytes, err := strconv.ParseInt(unconfirmedTxs.Result.TotalBytes, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	err = mempoolWriter.Save(ctx, c, client, repository.TendermintMempool{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_MEMPOOL_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: unconfirmedTxs.Endpoint,
+		},
+		NTxs:       nTxs,
+		Total:      total,
+		TotalBytes: totalBytes,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[mempool] n_txs: %d, total_bytes: %d", nTxs, totalBytes))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	_const "github.com/b-harvest/Harvestmon/const"
-	"github.com/b-harvest/Harvestmon/moniter/node/types"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
 	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/gorm"
+	"gopkg.in/yaml.v3"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -452,9 +457,9 @@ func Test(t *testing.T) {
 		assert.NoError(t, err)
 
 		expectedResponse := recorder.Result()
-		client := types.NewMonitorClient(&cfg, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}})
+		_, client := newSinkClient(t, cfg.Agent, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}}, collector.Backend{})
 
-		netInfo, err := client.GetNetInfo()
+		netInfo, err := client.GetNetInfo(context.Background())
 		assert.NoError(t, err)
 
 		assert.Equal(t,
@@ -965,9 +970,9 @@ func Test(t *testing.T) {
 		_, err := recorder.WriteString(json)
 		assert.NoError(t, err)
 		expectedResponse := recorder.Result()
-		client := types.NewMonitorClient(&cfg, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}})
+		_, client := newSinkClient(t, cfg.Agent, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}}, collector.Backend{})
 
-		commit, err := client.GetCommit()
+		commit, err := client.GetCommit(context.Background())
 		assert.NoError(t, err)
 
 		assert.Equal(t,
@@ -1026,14 +1031,14 @@ func Test(t *testing.T) {
 		_, err := recorder.WriteString(json)
 		assert.NoError(t, err)
 		expectedResponse := recorder.Result()
-		client := types.NewMonitorClient(&cfg, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}})
+		_, client := newSinkClient(t, cfg.Agent, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}}, collector.Backend{})
 
-		cometBFTStatus, err := client.GetCometBFTStatus()
+		cometBFTStatus, err := client.GetCometBFTStatus(context.Background())
 		assert.NoError(t, err)
 
 		assert.Equal(t,
 			types.HexBytes("2E172FCAA29C843A518A2A9950763A68351A9075"),
-			cometBFTStatus.ValidatorInfo.Address)
+			cometBFTStatus.Result.ValidatorInfo.Address)
 	})
 
 	t.Run("save status", func(t *testing.T) {
@@ -1044,11 +1049,16 @@ func Test(t *testing.T) {
 			return
 		}
 		expectedResponse := recorder.Result()
-		client := types.NewMonitorClient(&cfg, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}})
+		// Without a database, the record is pushed to a collector in sink mode.
+		var written []collector.Record
+		c, client := newSinkClient(t, agent, &http.Client{Transport: &mockRoundTripper{response: expectedResponse}}, collector.Backend{
+			Write: func(ctx context.Context, record collector.Record) error {
+				written = append(written, record)
+				return nil
+			},
+		})
 
-		statusMonitorRepository := repository.StatusRepository{BaseRepository: repository.BaseRepository{DB: gorm.DB{}}}
-
-		cometBFTStatus, err := client.GetCometBFTStatus()
+		cometBFTStatus, err := client.GetCometBFTStatus(context.Background())
 		assert.NoError(t, err)
 
 		eventUUID, err := uuid.NewUUID()
@@ -1059,11 +1069,11 @@ func Test(t *testing.T) {
 
 		createdAt := time.Now().UTC()
 
-		latestBlockHeight, err := strconv.ParseUint(cometBFTStatus.SyncInfo.LatestBlockHeight, 0, 64)
-		earliestBlockHeight, err := strconv.ParseUint(cometBFTStatus.SyncInfo.EarliestBlockHeight, 0, 64)
+		latestBlockHeight, err := strconv.ParseUint(cometBFTStatus.Result.SyncInfo.LatestBlockHeight, 0, 64)
+		earliestBlockHeight, err := strconv.ParseUint(cometBFTStatus.Result.SyncInfo.EarliestBlockHeight, 0, 64)
 		assert.NoError(t, err)
 
-		err = statusMonitorRepository.Save(
+		err = statusWriter.Save(context.Background(), c, client,
 			repository.TendermintStatus{
 				CreatedAt: createdAt,
 				EventUUID: eventUUID.String(),
@@ -1078,21 +1088,254 @@ func Test(t *testing.T) {
 				TendermintNodeInfoUUID: nodeInfoUUID.String(),
 				TendermintNodeInfo: repository.TendermintNodeInfo{
 					TendermintNodeInfoUUID: nodeInfoUUID.String(),
-					NodeId:                 string(cometBFTStatus.NodeInfo.DefaultNodeID),
-					ListenAddr:             cometBFTStatus.NodeInfo.ListenAddr,
-					ChainId:                cometBFTStatus.NodeInfo.Network,
-					Moniker:                cometBFTStatus.NodeInfo.Moniker,
+					NodeId:                 string(cometBFTStatus.Result.NodeInfo.DefaultNodeID),
+					ListenAddr:             cometBFTStatus.Result.NodeInfo.ListenAddr,
+					ChainId:                cometBFTStatus.Result.NodeInfo.Network,
+					Moniker:                cometBFTStatus.Result.NodeInfo.Moniker,
 				},
-				LatestBlockHash:     string(cometBFTStatus.SyncInfo.LatestBlockHash),
-				LatestAppHash:       string(cometBFTStatus.SyncInfo.LatestAppHash),
+				LatestBlockHash:     string(cometBFTStatus.Result.SyncInfo.LatestBlockHash),
+				LatestAppHash:       string(cometBFTStatus.Result.SyncInfo.LatestAppHash),
 				LatestBlockHeight:   latestBlockHeight,
-				LatestBlockTime:     cometBFTStatus.SyncInfo.LatestBlockTime,
-				EarliestBlockHash:   string(cometBFTStatus.SyncInfo.EarliestBlockHash),
-				EarliestAppHash:     string(cometBFTStatus.SyncInfo.EarliestAppHash),
+				LatestBlockTime:     cometBFTStatus.Result.SyncInfo.LatestBlockTime,
+				EarliestBlockHash:   string(cometBFTStatus.Result.SyncInfo.EarliestBlockHash),
+				EarliestAppHash:     string(cometBFTStatus.Result.SyncInfo.EarliestAppHash),
 				EarliestBlockHeight: earliestBlockHeight,
-				EarliestBlockTime:   cometBFTStatus.SyncInfo.EarliestBlockTime,
-				CatchingUp:          cometBFTStatus.SyncInfo.CatchingUp,
+				EarliestBlockTime:   cometBFTStatus.Result.SyncInfo.EarliestBlockTime,
+				CatchingUp:          cometBFTStatus.Result.SyncInfo.CatchingUp,
 			})
 		assert.NoError(t, err)
+		assert.Len(t, written, 1)
+		assert.Equal(t, "status", written[0].Name)
+	})
+
+	t.Run("records flushed after the run is cancelled are pushed", func(t *testing.T) {
+		var written []collector.Record
+		c, client := newSinkClient(t, agent, http.DefaultClient, collector.Backend{
+			Write: func(ctx context.Context, record collector.Record) error {
+				written = append(written, record)
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := statusWriter.Save(ctx, c, client, repository.TendermintStatus{Event: repository.Event{AgentName: agent.AgentName}})
+		assert.NoError(t, err)
+		assert.Len(t, written, 1)
+	})
+
+	t.Run("flush is bounded by the drain timeout", func(t *testing.T) {
+		c, client := newSinkClient(t, agent, http.DefaultClient, collector.Backend{
+			Write: func(ctx context.Context, record collector.Record) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+		drainTimeout := 50 * time.Millisecond
+		c.DrainTimeout = &drainTimeout
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := statusWriter.Save(ctx, c, client, repository.TendermintStatus{Event: repository.Event{AgentName: agent.AgentName}})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMonitorConfig(t *testing.T) {
+	agent := types.MonitoringAgent{AgentName: "node-a", Host: "10.0.0.1", CommitId: "19ge4rgndfifji"}
+	c, client := newSinkClient(t, agent, http.DefaultClient, collector.Backend{})
+
+	newMonitor := func(t *testing.T, spec string) types.Monitor {
+		var monitorSpec types.MonitorSpec
+		assert.NoError(t, yaml.Unmarshal([]byte(spec), &monitorSpec))
+		monitor, err := types.NewMonitor(monitorSpec)
+		assert.NoError(t, err)
+		return monitor
+	}
+
+	t.Run("prometheus defaults to the metrics endpoint of the host", func(t *testing.T) {
+		t.Setenv(types.EnvPrometheusAddress, "")
+
+		monitor := newMonitor(t, "name: prometheus\nconfig:\n  metrics: [p2p_peers]\n")
+		assert.NoError(t, monitor.Init(context.Background(), c, client))
+		assert.Equal(t, prometheusConfig{Address: "http://10.0.0.1:26660/metrics", Metrics: []string{"p2p_peers"}}, monitor.(*prometheusMonitor).config)
+	})
+
+	t.Run("prometheus is enabled by default only with its address", func(t *testing.T) {
+		t.Setenv(types.EnvPrometheusAddress, "")
+		assert.NotContains(t, types.DefaultMonitors(), "prometheus")
+
+		t.Setenv(types.EnvPrometheusAddress, "http://10.0.0.1:26660/metrics")
+		assert.Contains(t, types.DefaultMonitors(), "prometheus")
+	})
+
+	t.Run("cosmos_validator is enabled by default only with our validator", func(t *testing.T) {
+		t.Setenv(types.EnvValoperAddress, "")
+		assert.NotContains(t, types.DefaultMonitors(), "cosmos_validator")
+
+		monitor := newMonitor(t, "name: cosmos_validator\n")
+		assert.ErrorContains(t, monitor.Init(context.Background(), c, client), "valoperAddress is not configured")
+
+		t.Setenv(types.EnvValoperAddress, "cosmosvaloper1abc")
+		assert.Contains(t, types.DefaultMonitors(), "cosmos_validator")
+		assert.NoError(t, monitor.Init(context.Background(), c, client))
+		assert.NoError(t, monitor.Close())
+	})
+
+	t.Run("gov is enabled by default only with our validator", func(t *testing.T) {
+		t.Setenv(types.EnvValoperAddress, "")
+		assert.NotContains(t, types.DefaultMonitors(), "gov")
+
+		monitor := newMonitor(t, "name: gov\n")
+		assert.ErrorContains(t, monitor.Init(context.Background(), c, client), "valoperAddress is not configured")
+
+		t.Setenv(types.EnvValoperAddress, "cosmosvaloper1abc")
+		assert.Contains(t, types.DefaultMonitors(), "gov")
+	})
+
+	t.Run("upgrade is enabled by default only with cosmos api", func(t *testing.T) {
+		t.Setenv(types.EnvCosmosAddress, "")
+		t.Setenv(types.EnvValoperAddress, "")
+		assert.NotContains(t, types.DefaultMonitors(), "upgrade")
+
+		t.Setenv(types.EnvCosmosAddress, "http://10.0.0.1:1317")
+		assert.Contains(t, types.DefaultMonitors(), "upgrade")
+	})
+
+	t.Run("host_resource reads env", func(t *testing.T) {
+		t.Setenv(types.EnvHostProcPath, "/host/proc")
+
+		monitor := newMonitor(t, "name: host_resource\n")
+		assert.NoError(t, monitor.Init(context.Background(), c, client))
+		assert.Equal(t, "/host/proc", monitor.(*hostResourceMonitor).config.ProcPath)
+	})
+
+	t.Run("cosmos monitors make their own client", func(t *testing.T) {
+		monitor := newMonitor(t, "name: upgrade\nconfig:\n  protocol: grpc\n")
+		assert.NoError(t, monitor.Init(context.Background(), c, client))
+		assert.Equal(t, "10.0.0.1:9090", monitor.(*upgradeMonitor).config.Address)
+		assert.NotNil(t, monitor.(*upgradeMonitor).cosmos)
+		assert.NoError(t, monitor.Close())
+
+		monitor = newMonitor(t, "name: gov\nconfig:\n  protocol: websocket\n")
+		assert.Error(t, monitor.Init(context.Background(), c, client))
+	})
+}
+
+func TestCommitIdOfAgent(t *testing.T) {
+	agent := types.MonitoringAgent{AgentName: "node-a", Host: "10.0.0.1", CommitId: "19ge4rgndfifji"}
+
+	t.Run("deactivation is of the agent's commit", func(t *testing.T) {
+		var written []collector.Record
+		c, client := newSinkClient(t, agent, http.DefaultClient, collector.Backend{
+			Write: func(ctx context.Context, record collector.Record) error {
+				written = append(written, record)
+				return nil
+			},
+		})
+
+		assert.NoError(t, DeregisterAgent(c, client))
+		assert.Len(t, written, 1)
+
+		var record agentDeactivationRecord
+		assert.NoError(t, json.Unmarshal(written[0].Payload, &record))
+		assert.Equal(t, agentDeactivationRecord{AgentName: "node-a", CommitId: "19ge4rgndfifji"}, record)
+	})
+
+	t.Run("average block time is of the agent's commit", func(t *testing.T) {
+		var queried averageBlockTimeArgs
+		c, client := newSinkClient(t, agent, http.DefaultClient, collector.Backend{
+			Query: func(ctx context.Context, name string, args json.RawMessage) (any, error) {
+				assert.Equal(t, "average_block_time", name)
+				return time.Second, json.Unmarshal(args, &queried)
+			},
+		})
+
+		averageBlockTime, err := averageBlockTimeQuery.Do(context.Background(), c, client, averageBlockTimeArgs{AgentName: agent.AgentName, CommitId: agent.CommitId, Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, time.Second, averageBlockTime)
+		assert.Equal(t, "19ge4rgndfifji", queried.CommitId)
 	})
 }
+
+func TestNetInfoMonitor(t *testing.T) {
+	agent := types.MonitoringAgent{AgentName: "node-a", Host: "10.0.0.1", CommitId: "19ge4rgndfifji"}
+
+	t.Run("peer whose connection status can't be parsed is stored without quality", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		recorder.Header().Add("Content-Type", "application/json")
+		_, err := recorder.WriteString(`{"result": {"n_peers": "2", "peers": [
+  {"node_info": {"id": "a"}, "remote_ip": "10.0.0.2",
+   "connection_status": {"Duration": "90000000000", "SendMonitor": {"Bytes": "1", "CurRate": "2"}, "RecvMonitor": {"Bytes": "3", "CurRate": "4"}, "Channels": []}},
+  {"node_info": {"id": "b"}, "remote_ip": "10.0.0.3",
+   "connection_status": {"Duration": "unknown", "SendMonitor": {"Bytes": "1", "CurRate": "2"}, "RecvMonitor": {"Bytes": "3", "CurRate": "4"}, "Channels": []}}
+]}}`)
+		assert.NoError(t, err)
+
+		var written []collector.Record
+		c, client := newSinkClient(t, agent, &http.Client{Transport: &mockRoundTripper{response: recorder.Result()}}, collector.Backend{
+			Write: func(ctx context.Context, record collector.Record) error {
+				written = append(written, record)
+				return nil
+			},
+		})
+
+		monitor := &netInfoMonitor{}
+		assert.NoError(t, monitor.Init(context.Background(), c, client))
+		assert.NoError(t, monitor.Run(context.Background()))
+		assert.Len(t, written, 1)
+
+		var netInfo repository.TendermintNetInfo
+		assert.NoError(t, json.Unmarshal(written[0].Payload, &netInfo))
+		assert.Len(t, netInfo.TendermintPeerInfos, 2)
+		assert.Equal(t, 90*time.Second, *netInfo.TendermintPeerInfos[0].ConnectionDuration)
+		assert.Nil(t, netInfo.TendermintPeerInfos[1].ConnectionDuration)
+		assert.Nil(t, netInfo.TendermintPeerInfos[1].SendRate)
+	})
+}
+
+func TestClassifyDBError(t *testing.T) {
+	for _, err := range []error{
+		&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+		fmt.Errorf("save: %w", &mysql.MySQLError{Number: 1406, Message: "Data too long"}),
+		fmt.Errorf("%w: invalid character", collector.ErrRejected),
+	} {
+		assert.ErrorIs(t, classifyDBError(err), collector.ErrRejected, err.Error())
+	}
+
+	// They may succeed later, so they aren't rejected.
+	for _, err := range []error{
+		&mysql.MySQLError{Number: 1213, Message: "Deadlock found"},
+		&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"},
+		&mysql.MySQLError{Number: 1040, Message: "Too many connections"},
+		&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"},
+		errors.New("invalid connection"),
+	} {
+		assert.NotErrorIs(t, classifyDBError(err), collector.ErrRejected, err.Error())
+	}
+
+	t.Run("undecodable record is rejected", func(t *testing.T) {
+		assert.ErrorIs(t, statusWriter.replay(nil, json.RawMessage(`{`)), collector.ErrRejected)
+	})
+}
+
+// newSinkClient returns the config and the client of the agent in sink mode, whose records and queries are served by backend.
+// Monitors can be tested that way without a database.
+func newSinkClient(t *testing.T, agent types.MonitoringAgent, httpClient types.HttpClient, backend collector.Backend) (*types.MonitorConfig, *types.MonitorClient) {
+	collectorServer := httptest.NewServer(collector.Handler([]collector.Token{{Token: "token", Agents: []string{"*"}}}, backend, 0))
+	t.Cleanup(collectorServer.Close)
+
+	retries := 0
+	cfg := &types.MonitorConfig{
+		Agent: agent,
+		Wal:   &types.WalConfig{Disabled: true},
+		Sink:  &types.SinkConfig{Address: collectorServer.URL, Token: "token", Retries: &retries},
+	}
+	assert.NoError(t, cfg.ApplyConfigFromEnvAndDefault())
+
+	client, err := types.NewMonitorClient(cfg, httpClient, "").ForAgent(&cfg.Agent)
+	assert.NoError(t, err)
+	return cfg, client
+}
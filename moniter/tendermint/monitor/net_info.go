@@ -1,31 +1,93 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	_const "github.com/b-harvest/Harvestmon/const"
 	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/geoip"
 	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
 	"github.com/b-harvest/Harvestmon/repository"
 	"github.com/b-harvest/Harvestmon/util"
 	"github.com/google/uuid"
+	"os"
 	"strconv"
 	"time"
 )
 
-func NetInfoMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &netInfoMonitor{}
+	})
+}
+
+// netInfoConfig is the `config` block of net_info.
+//
+//	monitors:
+//	  - name: net_info
+//	    config:
+//	      geoip:
+//	        cityDatabase: /usr/share/GeoIP/GeoLite2-City.mmdb
+//	        asnDatabase: /usr/share/GeoIP/GeoLite2-ASN.mmdb
+type netInfoConfig struct {
+	// GeoIP locates peers by their remote ip. Peers are stored without location when no database is set.
+	GeoIP geoip.Config `yaml:"geoip"`
+}
+
+type netInfoMonitor struct {
+	config  netInfoConfig
+	c       *types.MonitorConfig
+	client  *types.MonitorClient
+	locator *geoip.Locator
+}
+
+func (m *netInfoMonitor) Name() string {
+	return "net_info"
+}
+
+func (m *netInfoMonitor) Config() any {
+	return &m.config
+}
+
+func (m *netInfoMonitor) Init(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	m.c, m.client = c, client
+
+	if m.config.GeoIP.CityDatabase == "" {
+		m.config.GeoIP.CityDatabase = os.Getenv(types.EnvGeoIPCityDatabase)
+	}
+	if m.config.GeoIP.ASNDatabase == "" {
+		m.config.GeoIP.ASNDatabase = os.Getenv(types.EnvGeoIPASNDatabase)
+	}
+	if !m.config.GeoIP.Enabled() {
+		return nil
+	}
+
+	locator, err := geoip.NewLocator(m.config.GeoIP)
+	if err != nil {
+		return fmt.Errorf("[net_info] geoip: %w", err)
+	}
+	m.locator = locator
+	log.Debug(fmt.Sprintf("[net_info] peers of %s are located with city database: %s, asn database: %s", c.Agent.AgentName, m.config.GeoIP.CityDatabase, m.config.GeoIP.ASNDatabase))
+	return nil
+}
+
+func (m *netInfoMonitor) Close() error {
+	return nil
+}
+
+func (m *netInfoMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
 	_, _, fn := util.TraceFirst()
 	log.Debug("Starting monitor: " + fn)
 
-	netInfoMonitorRepository := repository.NetInfoRepository{BaseRepository: repository.BaseRepository{DB: *client.GetDatabase(c.DbBatchSize)}}
-
-	netInfo, err := client.GetNetInfo()
+	netInfo, err := client.GetNetInfo(ctx)
 	if err != nil {
-		log.Error(err)
+		return err
 	}
 
 	eventUUID, err := uuid.NewUUID()
 	if err != nil {
-		log.Error(err)
+		return err
 	}
 
 	createdAt := time.Now().UTC()
@@ -40,24 +102,52 @@ func NetInfoMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
 		if err != nil {
 			log.Error(err)
 		}
+		// Peer is stored without connection quality(NULL) when its status can't be parsed,
+		// so a zero duration isn't mistaken for a reconnect.
+		quality, qualityErr := peer.ConnectionStatus.Quality()
+		if qualityErr != nil {
+			log.Warn(fmt.Sprintf("[net_info] peer %s: %v", peer.NodeInfo.DefaultNodeID, qualityErr))
+		}
+		var location geoip.Location
+		if m.locator != nil {
+			location, err = m.locator.Locate(peer.RemoteIP)
+			if err != nil {
+				log.Warn(fmt.Sprintf("[net_info] failed to locate peer %s(%s): %v", peer.NodeInfo.DefaultNodeID, peer.RemoteIP, err))
+			}
+		}
 
-		tendermintPeerInfos = append(tendermintPeerInfos,
-			repository.TendermintPeerInfo{
-				TendermintPeerInfoUUID:     tendermintPeerUUID.String(),
-				TendermintNetInfoCreatedAt: createdAt,
-				EventUUID:                  eventUUID.String(),
-				IsOutbound:                 peer.IsOutbound,
-				TendermintNodeInfoUUID:     tendermintNodeUUID.String(),
-				TendermintNodeInfo: repository.TendermintNodeInfo{
-					TendermintNodeInfoUUID: tendermintNodeUUID.String(),
-					NodeId:                 string(peer.NodeInfo.DefaultNodeID),
-					ListenAddr:             peer.NodeInfo.ListenAddr,
-					ChainId:                peer.NodeInfo.Network,
-					Moniker:                peer.NodeInfo.Moniker,
-				},
-				RemoteIP: peer.RemoteIP,
-			})
-
+		peerInfo := repository.TendermintPeerInfo{
+			TendermintPeerInfoUUID:     tendermintPeerUUID.String(),
+			TendermintNetInfoCreatedAt: createdAt,
+			EventUUID:                  eventUUID.String(),
+			IsOutbound:                 peer.IsOutbound,
+			TendermintNodeInfoUUID:     tendermintNodeUUID.String(),
+			TendermintNodeInfo: repository.TendermintNodeInfo{
+				TendermintNodeInfoUUID: tendermintNodeUUID.String(),
+				NodeId:                 string(peer.NodeInfo.DefaultNodeID),
+				ListenAddr:             peer.NodeInfo.ListenAddr,
+				ChainId:                peer.NodeInfo.Network,
+				Moniker:                peer.NodeInfo.Moniker,
+				Version:                peer.NodeInfo.Version,
+			},
+			RemoteIP:       peer.RemoteIP,
+			CountryCode:    location.CountryCode,
+			Country:        location.Country,
+			City:           location.City,
+			ASN:            location.ASN,
+			ASOrganization: location.ASOrganization,
+		}
+		if qualityErr == nil {
+			peerInfo.ConnectionDuration = &quality.Duration
+			peerInfo.SendBytes = &quality.SendBytes
+			peerInfo.RecvBytes = &quality.RecvBytes
+			peerInfo.SendRate = &quality.SendRate
+			peerInfo.RecvRate = &quality.RecvRate
+			peerInfo.SendQueueSize = &quality.SendQueueSize
+			peerInfo.SendQueueCapacity = &quality.SendQueueCapacity
+			peerInfo.SendQueueSaturation = &quality.SendQueueSaturation
+		}
+		tendermintPeerInfos = append(tendermintPeerInfos, peerInfo)
 	}
 
 	nPeers, err := strconv.Atoi(netInfo.Result.NPeers)
@@ -65,7 +155,7 @@ func NetInfoMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
 		log.Error(err)
 	}
 
-	err = netInfoMonitorRepository.Save(
+	err = netInfoWriter.Save(ctx, c, client,
 		repository.TendermintNetInfo{
 			CreatedAt: createdAt,
 			EventUUID: eventUUID.String(),
@@ -76,6 +166,7 @@ func NetInfoMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
 				CommitID:    c.Agent.CommitId,
 				EventType:   _const.TM_NET_INFO_EVENT_TYPE,
 				CreatedAt:   createdAt,
+				RpcEndpoint: netInfo.Endpoint,
 			},
 			TendermintPeerInfos: tendermintPeerInfos,
 			NPeers:              nPeers,
@@ -87,4 +178,5 @@ func NetInfoMonitor(c *types.MonitorConfig, client *types.MonitorClient) {
 	log.Info(fmt.Sprintf("[net_info] peer_count: %d", nPeers))
 
 	log.Debug("Complete monitor: " + fn)
+	return nil
 }
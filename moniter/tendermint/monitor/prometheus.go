@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"strings"
+	"time"
+)
+
+// metricNamespaces are the prefixes of the node's metrics. Allowed metrics can be configured without them.
+var metricNamespaces = []string{"cometbft_", "tendermint_"}
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &prometheusMonitor{}
+	})
+}
+
+// prometheusConfig is the `config` block of prometheus.
+//
+//	monitors:
+//	  - name: prometheus
+//	    config:
+//	      address: "http://127.0.0.1:26660/metrics"
+//	      metrics:
+//	        - consensus_height
+//	        - p2p_peers
+type prometheusConfig struct {
+	// Address of the metrics endpoint. (e.g. `http://127.0.0.1:26660/metrics`)
+	Address string `yaml:"address"`
+	// Metrics is the allow-list of series to store.
+	// Namespace prefix of the series(`cometbft_`, `tendermint_`) may be omitted.
+	Metrics []string `yaml:"metrics"`
+}
+
+type prometheusMonitor struct {
+	config prometheusConfig
+	c      *types.MonitorConfig
+	client *types.MonitorClient
+}
+
+func (m *prometheusMonitor) Name() string {
+	return "prometheus"
+}
+
+func (m *prometheusMonitor) Config() any {
+	return &m.config
+}
+
+// EnabledByDefault reports whether the metrics endpoint is set by env, since the node doesn't expose it by default.
+func (m *prometheusMonitor) EnabledByDefault() bool {
+	return os.Getenv(types.EnvPrometheusAddress) != ""
+}
+
+func (m *prometheusMonitor) Init(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	m.c, m.client = c, client
+
+	if m.config.Address == "" {
+		v := os.Getenv(types.EnvPrometheusAddress)
+		if v == "" {
+			m.config.Address = fmt.Sprintf("http://%s:%d/metrics", c.Agent.Host, types.DefaultPrometheusPort)
+			log.Debug("prometheus address set as default: " + m.config.Address)
+		} else {
+			m.config.Address = v
+			log.Debug("prometheus address set as ENV: " + m.config.Address)
+		}
+	} else {
+		log.Debug("prometheus address set as " + m.config.Address)
+	}
+	if len(m.config.Metrics) == 0 {
+		v := os.Getenv(types.EnvPrometheusMetrics)
+		if v == "" {
+			m.config.Metrics = types.DefaultPrometheusMetrics
+			log.Debug("prometheus metrics set as default: " + strings.Join(m.config.Metrics, ","))
+		} else {
+			m.config.Metrics = strings.Split(v, ",")
+			log.Debug("prometheus metrics set as ENV: " + v)
+		}
+	} else {
+		log.Debug("prometheus metrics set as " + strings.Join(m.config.Metrics, ","))
+	}
+	return nil
+}
+
+func (m *prometheusMonitor) Close() error {
+	return nil
+}
+
+func (m *prometheusMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	samples, err := client.GetPrometheusMetrics(ctx, m.config.Address)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	var metrics []repository.Metric
+	for _, sample := range samples {
+		if !isAllowedMetric(sample.Name, m.config.Metrics) {
+			continue
+		}
+		// NaN and Inf can't be stored as double.
+		if !sample.IsFinite() {
+			log.Debug("Skipping non-finite sample: " + sample.Name)
+			continue
+		}
+
+		labels, err := json.Marshal(sample.Labels)
+		if err != nil {
+			return err
+		}
+
+		metrics = append(metrics, repository.Metric{
+			CreatedAt:   createdAt,
+			EventUUID:   eventUUID.String(),
+			MetricIndex: len(metrics),
+			Name:        sample.Name,
+			Labels:      string(labels),
+			Value:       sample.Value,
+		})
+	}
+
+	err = metricWriter.Save(ctx, c, client, metricRecord{
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_METRIC_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: m.config.Address,
+		},
+		Metrics: metrics,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[prometheus] scraped: %d, stored: %d", len(samples), len(metrics)))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
+
+// isAllowedMetric reports whether name is in allowed, with or without the node's namespace.
+func isAllowedMetric(name string, allowed []string) bool {
+	for _, a := range allowed {
+		if name == a {
+			return true
+		}
+		for _, namespace := range metricNamespaces {
+			if strings.TrimPrefix(name, namespace) == a {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"gorm.io/gorm"
+	"time"
+)
+
+// queries are registered by name, so a collector can run them for monitors in sink mode.
+var queries = map[string]func(db *gorm.DB, args json.RawMessage) (any, error){}
+
+// query reads what a monitor needs from the database, or from the collector in sink mode.
+type query[A, R any] struct {
+	name string
+	run  func(r repository.BaseRepository, args A) (R, error)
+}
+
+func newQuery[A, R any](name string, run func(r repository.BaseRepository, args A) (R, error)) *query[A, R] {
+	if _, exists := queries[name]; exists {
+		panic("query already registered: " + name)
+	}
+	q := &query[A, R]{name: name, run: run}
+	queries[name] = q.runJSON
+	return q
+}
+
+func (q *query[A, R]) Do(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, args A) (R, error) {
+	if client.Sink() != nil {
+		var result R
+		err := client.Sink().Query(ctx, q.name, args, &result)
+		return result, err
+	}
+	return q.run(repository.BaseRepository{DB: *client.GetDatabase(c.DbBatchSize)}, args)
+}
+
+func (q *query[A, R]) runJSON(db *gorm.DB, args json.RawMessage) (any, error) {
+	var a A
+	err := json.Unmarshal(args, &a)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", collector.ErrRejected, err)
+	}
+	return q.run(repository.BaseRepository{DB: *db}, a)
+}
+
+// RunQuery runs the named query on the database for a collector.
+// The error wraps collector.ErrRejected when the query can never succeed, e.g. unknown query, undecodable args.
+func RunQuery(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, name string, args json.RawMessage) (any, error) {
+	run, exists := queries[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown query %s", collector.ErrRejected, name)
+	}
+
+	result, err := run(client.GetDatabase(c.DbBatchSize), args)
+	if err != nil {
+		return nil, classifyDBError(err)
+	}
+	return result, nil
+}
+
+type agentArgs struct {
+	AgentName string
+	CommitId  string
+}
+
+type averageBlockTimeArgs struct {
+	AgentName string
+	CommitId  string
+	Limit     int
+}
+
+var (
+	highestCommitHeightQuery = newQuery("highest_commit_height", func(r repository.BaseRepository, args agentArgs) (uint64, error) {
+		return (&repository.CommitRepository{BaseRepository: r}).FetchHighestHeight(args.AgentName, args.CommitId)
+	})
+	highestBlockResultsHeightQuery = newQuery("highest_block_results_height", func(r repository.BaseRepository, args agentArgs) (uint64, error) {
+		return (&repository.BlockResultsRepository{BaseRepository: r}).FetchHighestHeight(args.AgentName, args.CommitId)
+	})
+	latestValidatorsHashQuery = newQuery("latest_validators_hash", func(r repository.BaseRepository, args agentArgs) (string, error) {
+		return (&repository.ValidatorRepository{BaseRepository: r}).FetchLatestValidatorsHash(args.AgentName, args.CommitId)
+	})
+	averageBlockTimeQuery = newQuery("average_block_time", func(r repository.BaseRepository, args averageBlockTimeArgs) (time.Duration, error) {
+		r.CommitId = args.CommitId
+		return (&repository.CommitRepository{BaseRepository: r}).FindAverageBlockTime(args.AgentName, args.Limit)
+	})
+)
@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"os"
+	"strconv"
+	"time"
+)
+
+// averageBlockTimeCommits is how many latest commits are used to get the average block time.
+const averageBlockTimeCommits = 100
+
+func init() {
+	types.RegisterMonitor(func() types.Monitor {
+		return &upgradeMonitor{}
+	})
+}
+
+// upgradeMonitor stores the scheduled upgrade plan and when the chain is estimated to reach its height.
+// The average block time comes from commits stored by block_commit monitor.
+type upgradeMonitor struct {
+	cosmosMonitor
+}
+
+func (m *upgradeMonitor) Name() string {
+	return "upgrade"
+}
+
+// EnabledByDefault reports whether the Cosmos SDK API is set by env, either by its address or by our validator on the chain.
+// Nodes which aren't of a Cosmos SDK chain, or don't expose the API, are left alone.
+func (m *upgradeMonitor) EnabledByDefault() bool {
+	return os.Getenv(types.EnvCosmosAddress) != "" || os.Getenv(types.EnvValoperAddress) != ""
+}
+
+func (m *upgradeMonitor) Run(ctx context.Context) error {
+	c, client := m.c, m.client
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	plan, err := m.cosmos.GetCurrentPlan(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	event := repository.Event{
+		EventUUID:   eventUUID.String(),
+		AgentName:   c.Agent.AgentName,
+		ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+		CommitID:    c.Agent.CommitId,
+		EventType:   _const.TM_UPGRADE_EVENT_TYPE,
+		CreatedAt:   createdAt,
+		RpcEndpoint: m.config.Address,
+	}
+
+	if plan == nil {
+		err = upgradeWriter.Save(ctx, c, client, upgradeRecord{Event: event})
+		if err != nil {
+			return err
+		}
+		log.Info("[upgrade] no upgrade scheduled")
+
+		log.Debug("Complete monitor: " + fn)
+		return nil
+	}
+
+	cometBFTStatus, err := client.GetCometBFTStatus(ctx)
+	if err != nil {
+		return err
+	}
+	latestBlockHeight, err := strconv.ParseUint(cometBFTStatus.Result.SyncInfo.LatestBlockHeight, 0, 64)
+	if err != nil {
+		return err
+	}
+
+	averageBlockTime, err := averageBlockTimeQuery.Do(ctx, c, client, averageBlockTimeArgs{AgentName: c.Agent.AgentName, CommitId: c.Agent.CommitId, Limit: averageBlockTimeCommits})
+	if err != nil {
+		// The plan is still worth storing without the estimation.
+		log.Warn("[upgrade] could not get average block time: " + err.Error())
+		averageBlockTime = 0
+	}
+
+	var estimatedTime *time.Time
+	if averageBlockTime > 0 {
+		t := cometBFTStatus.Result.SyncInfo.LatestBlockTime.UTC().Add(time.Duration(plan.Height-int64(latestBlockHeight)) * averageBlockTime)
+		estimatedTime = &t
+	}
+
+	err = upgradeWriter.Save(ctx, c, client, upgradeRecord{
+		Event: event,
+		Plan: &repository.CosmosUpgradePlan{
+			CreatedAt:         createdAt,
+			EventUUID:         eventUUID.String(),
+			Name:              plan.Name,
+			Height:            plan.Height,
+			Info:              plan.Info,
+			LatestBlockHeight: latestBlockHeight,
+			NodeVersion:       cometBFTStatus.Result.NodeInfo.Version,
+			AverageBlockTime:  averageBlockTime.Milliseconds(),
+			EstimatedTime:     estimatedTime,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if estimatedTime != nil {
+		log.Info(fmt.Sprintf("[upgrade] plan: %s, height: %d, current height: %d, estimated time: %s", plan.Name, plan.Height, latestBlockHeight, estimatedTime.Format(time.RFC3339)))
+	} else {
+		log.Info(fmt.Sprintf("[upgrade] plan: %s, height: %d, current height: %d, estimated time: unknown", plan.Name, plan.Height, latestBlockHeight))
+	}
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
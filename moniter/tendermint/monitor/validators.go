@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	_const "github.com/b-harvest/Harvestmon/const"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/b-harvest/Harvestmon/util"
+	"github.com/google/uuid"
+	"strconv"
+	"time"
+)
+
+// validatorsPerPage is the maximum page size of /validators.
+const validatorsPerPage = 100
+
+func init() {
+	types.RegisterMonitorFunc("validators", ValidatorsMonitor)
+}
+
+func ValidatorsMonitor(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	_, _, fn := util.TraceFirst()
+	log.Debug("Starting monitor: " + fn)
+
+	commit, err := client.GetCommit(ctx)
+	if err != nil {
+		return err
+	}
+	height, err := strconv.ParseUint(commit.Result.Height, 0, 64)
+	if err != nil {
+		return err
+	}
+
+	latestValidatorsHash, err := latestValidatorsHashQuery.Do(ctx, c, client, agentArgs{AgentName: c.Agent.AgentName, CommitId: c.Agent.CommitId})
+	if err != nil {
+		return err
+	}
+	if latestValidatorsHash == commit.Result.ValidatorsHash {
+		log.Debug(fmt.Sprintf("[validators] validator set not changed. height: %d, validators_hash: %s", height, latestValidatorsHash))
+		log.Debug("Complete monitor: " + fn)
+		return nil
+	}
+
+	var (
+		validators []types.Validator
+		total      int
+		endpoint   string
+	)
+	for page := 1; ; page++ {
+		result, err := client.GetValidatorsWithHeight(ctx, height, page, validatorsPerPage)
+		if err != nil {
+			return err
+		}
+		total, err = strconv.Atoi(result.Result.Total)
+		if err != nil {
+			return err
+		}
+		validators = append(validators, result.Result.Validators...)
+		endpoint = result.Endpoint
+
+		if len(result.Result.Validators) == 0 || len(validators) >= total {
+			break
+		}
+	}
+	if len(validators) != total {
+		return fmt.Errorf("fetched %d validators but total is %d. height: %d", len(validators), total, height)
+	}
+
+	eventUUID, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+
+	var (
+		tendermintValidators []repository.TendermintValidator
+		totalVotingPower     int64
+	)
+	for _, validator := range validators {
+		votingPower, err := strconv.ParseInt(validator.VotingPower, 10, 64)
+		if err != nil {
+			return errors.New("failed to parse voting power of " + validator.Address + ": " + err.Error())
+		}
+		proposerPriority, err := strconv.ParseInt(validator.ProposerPriority, 10, 64)
+		if err != nil {
+			return errors.New("failed to parse proposer priority of " + validator.Address + ": " + err.Error())
+		}
+		totalVotingPower += votingPower
+
+		tendermintValidators = append(tendermintValidators, repository.TendermintValidator{
+			Address:                         validator.Address,
+			TendermintValidatorSetCreatedAt: createdAt,
+			EventUUID:                       eventUUID.String(),
+			PubKeyType:                      validator.PubKey.Type,
+			PubKey:                          validator.PubKey.Value,
+			VotingPower:                     votingPower,
+			ProposerPriority:                proposerPriority,
+		})
+	}
+
+	err = validatorsWriter.Save(ctx, c, client, repository.TendermintValidatorSet{
+		CreatedAt: createdAt,
+		EventUUID: eventUUID.String(),
+		Event: repository.Event{
+			EventUUID:   eventUUID.String(),
+			AgentName:   c.Agent.AgentName,
+			ServiceName: _const.HARVESTMON_TENDERMINT_SERVICE_NAME,
+			CommitID:    c.Agent.CommitId,
+			EventType:   _const.TM_VALIDATORS_EVENT_TYPE,
+			CreatedAt:   createdAt,
+			RpcEndpoint: endpoint,
+		},
+		ChainID:          commit.Result.ChainID,
+		Height:           height,
+		ValidatorsHash:   commit.Result.ValidatorsHash,
+		Total:            total,
+		TotalVotingPower: totalVotingPower,
+		Validators:       tendermintValidators,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[validators] validator set changed. height: %d, validators_hash: %s, total: %d, total_voting_power: %d", height, commit.Result.ValidatorsHash, total, totalVotingPower))
+
+	log.Debug("Complete monitor: " + fn)
+	return nil
+}
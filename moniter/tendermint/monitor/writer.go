@@ -0,0 +1,283 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/health"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/wal"
+	"github.com/b-harvest/Harvestmon/repository"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"reflect"
+	"time"
+)
+
+// rejectedMySQLErrors are error numbers of MySQL which the same record gets again however many times it's retried.
+// A foreign key failure on insert(1452) isn't one of them, since the agent may not be registered yet. e.g. the database was down on startup
+var rejectedMySQLErrors = map[uint16]bool{
+	1048: true, // column cannot be null
+	1062: true, // duplicate entry
+	1264: true, // out of range value
+	1292: true, // incorrect value
+	1364: true, // field doesn't have a default value
+	1366: true, // incorrect value for the column
+	1406: true, // data too long
+	1451: true, // row is referenced by a foreign key
+	1586: true, // duplicate entry for the key
+	3819: true, // check constraint is violated
+}
+
+// writers are registered by name, so records buffered in the wal can be replayed by the writer which buffered them.
+var writers = map[string]func(db *gorm.DB, payload json.RawMessage) error{}
+
+// writer writes a monitor's record into the database.
+// When the write fails, the record is buffered in the wal and written again by ReplayWAL.
+type writer[T any] struct {
+	name  string
+	write func(r repository.BaseRepository, record T) error
+}
+
+func newWriter[T any](name string, write func(r repository.BaseRepository, record T) error) *writer[T] {
+	if _, exists := writers[name]; exists {
+		panic("writer already registered: " + name)
+	}
+	w := &writer[T]{name: name, write: write}
+	writers[name] = w.replay
+	return w
+}
+
+// Save writes the record, or pushes it to the collector in sink mode.
+// Once ctx is done, the push including its retries is given drainTimeout more, so records flushed on shutdown still reach the collector.
+// It returns nil when the record failed to be written but has been buffered.
+func (w *writer[T]) Save(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, record T) error {
+	err := w.saveUnbuffered(ctx, c, client, record)
+	if err == nil || client.WAL() == nil || errors.Is(err, collector.ErrRejected) {
+		return err
+	}
+
+	payload, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		return fmt.Errorf("%w (could not buffer into wal: %v)", err, marshalErr)
+	}
+	appendErr := client.WAL().Append(w.name, payload)
+	if appendErr != nil {
+		return fmt.Errorf("%w (could not buffer into wal: %v)", err, appendErr)
+	}
+
+	log.Warn(fmt.Sprintf("[wal] buffered `%s` record. pending: %d, err: %v", w.name, client.WAL().Len(), err))
+	return nil
+}
+
+// flushContext is ctx that is kept alive for drainTimeout after ctx is done.
+func flushContext(ctx context.Context, drainTimeout time.Duration) (context.Context, context.CancelFunc) {
+	flushCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-flushCtx.Done():
+		}
+	})
+	return flushCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// saveUnbuffered is Save without buffering the record when it fails.
+func (w *writer[T]) saveUnbuffered(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, record T) error {
+	start := time.Now()
+	var err error
+	if client.Sink() != nil {
+		var payload []byte
+		payload, err = json.Marshal(record)
+		if err == nil {
+			pushCtx, cancel := flushContext(ctx, *c.DrainTimeout)
+			_, err = client.Sink().Write(pushCtx, []collector.Record{{Name: w.name, Payload: payload}})
+			cancel()
+		}
+	} else {
+		err = w.write(repository.BaseRepository{DB: *client.GetDatabase(c.DbBatchSize)}, record)
+	}
+	health.ObserveDBWrite(w.name, batchSize(record), time.Since(start), err)
+	return err
+}
+
+func (w *writer[T]) replay(db *gorm.DB, payload json.RawMessage) error {
+	var record T
+	err := json.Unmarshal(payload, &record)
+	if err != nil {
+		return fmt.Errorf("%w: %w", collector.ErrRejected, err)
+	}
+	return w.write(repository.BaseRepository{DB: *db}, record)
+}
+
+// batchSize returns how many rows the record is. A slice is a batch of its elements.
+func batchSize(record any) int {
+	v := reflect.ValueOf(record)
+	if v.Kind() == reflect.Slice {
+		return v.Len()
+	}
+	return 1
+}
+
+// WriteRecord writes a record of the named writer into the database. It's how a collector writes records pushed by monitors.
+// The error wraps collector.ErrRejected when the record can never be written. e.g. unknown writer, undecodable payload, duplicated key
+func WriteRecord(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient, record collector.Record) error {
+	replay, exists := writers[record.Name]
+	if !exists {
+		return fmt.Errorf("%w: unknown writer %s", collector.ErrRejected, record.Name)
+	}
+
+	err := replay(client.GetDatabase(c.DbBatchSize), record.Payload)
+	if err != nil {
+		return classifyDBError(err)
+	}
+	return nil
+}
+
+// classifyDBError wraps err with collector.ErrRejected when retrying won't help. e.g. duplicated key, constraint violation
+// Anything else, such as a deadlock, a lock wait timeout or too many connections, may succeed later, so the record is kept.
+func classifyDBError(err error) error {
+	if errors.Is(err, collector.ErrRejected) {
+		return err
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.Is(err, gorm.ErrDuplicatedKey) || errors.As(err, &mysqlErr) && rejectedMySQLErrors[mysqlErr.Number] {
+		return fmt.Errorf("%w: %w", collector.ErrRejected, err)
+	}
+	return err
+}
+
+// ReplayWAL writes buffered records in order until the queue is empty or the database fails again.
+// In sink mode, they're pushed to the collector in batches.
+// A record which the database or the collector rejects (e.g. duplicated key) is dropped, so it doesn't block the queue forever.
+func ReplayWAL(ctx context.Context, c *types.MonitorConfig, client *types.MonitorClient) error {
+	queue := client.WAL()
+	if queue == nil || queue.Len() == 0 {
+		return nil
+	}
+
+	var (
+		replayed int
+		err      error
+	)
+	if client.Sink() != nil {
+		replayed, err = queue.ReplayBatch(ctx, c.Sink.BatchSize, func(records []wal.Record) (int, error) {
+			batch := make([]collector.Record, len(records))
+			for i, record := range records {
+				batch[i] = collector.Record{Name: record.Name, Payload: record.Payload}
+			}
+
+			written, err := client.Sink().Write(ctx, batch)
+			if errors.Is(err, collector.ErrRejected) && written < len(records) {
+				log.Error(fmt.Errorf("[wal] dropped `%s` record: %w", records[written].Name, err))
+				return written + 1, nil
+			}
+			return written, err
+		})
+	} else {
+		replayed, err = queue.Replay(ctx, func(record wal.Record) error {
+			err := WriteRecord(ctx, c, client, collector.Record{Name: record.Name, Payload: record.Payload})
+			if errors.Is(err, collector.ErrRejected) {
+				log.Error(fmt.Errorf("[wal] dropped `%s` record: %w", record.Name, err))
+				return nil
+			}
+			return err
+		})
+	}
+
+	stats := queue.Stats()
+	log.Info(fmt.Sprintf("[wal] replayed: %d, pending: %d (%d bytes), dropped: %d", replayed, stats.PendingRecords, stats.PendingBytes, stats.Dropped))
+
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("[wal] replay stopped: %w", err)
+	}
+	return nil
+}
+
+// govRecord, metricRecord and upgradeRecord are records of repositories which save an event with its rows.
+type govRecord struct {
+	Event     repository.Event
+	Proposals []repository.CosmosProposal
+}
+
+type metricRecord struct {
+	Event   repository.Event
+	Metrics []repository.Metric
+}
+
+type upgradeRecord struct {
+	Event repository.Event
+	Plan  *repository.CosmosUpgradePlan
+}
+
+// agentRecord registers the agent. CommitId is of the commit record the agent refers to.
+type agentRecord struct {
+	Agent       repository.Agent
+	ServiceName string
+	CommitId    string
+}
+
+// agentDeactivationRecord deactivates the agent registered with the commit record of CommitId.
+type agentDeactivationRecord struct {
+	AgentName string
+	CommitId  string
+}
+
+var (
+	agentWriter = newWriter("agent", func(r repository.BaseRepository, record agentRecord) error {
+		r.CommitId = record.CommitId
+		return (&repository.AgentRepository{BaseRepository: r}).Register(record.Agent, record.ServiceName)
+	})
+	agentDeactivationWriter = newWriter("agent_deactivation", func(r repository.BaseRepository, record agentDeactivationRecord) error {
+		r.CommitId = record.CommitId
+		return (&repository.AgentRepository{BaseRepository: r}).Deactivate(record.AgentName)
+	})
+	abciInfoWriter = newWriter("abci_info", func(r repository.BaseRepository, record repository.TendermintAbciInfo) error {
+		return (&repository.AbciInfoRepository{BaseRepository: r}).Save(record)
+	})
+	blockCommitWriter = newWriter("block_commit", func(r repository.BaseRepository, records []repository.TendermintCommit) error {
+		return (&repository.CommitRepository{BaseRepository: r}).CreateBatch(records)
+	})
+	blockResultsWriter = newWriter("block_results", func(r repository.BaseRepository, records []repository.TendermintBlockResults) error {
+		return (&repository.BlockResultsRepository{BaseRepository: r}).CreateBatch(records)
+	})
+	statusWriter = newWriter("status", func(r repository.BaseRepository, record repository.TendermintStatus) error {
+		return (&repository.StatusRepository{BaseRepository: r}).Save(record)
+	})
+	consensusStateWriter = newWriter("consensus_state", func(r repository.BaseRepository, record repository.TendermintConsensusState) error {
+		return (&repository.ConsensusStateRepository{BaseRepository: r}).Save(record)
+	})
+	cosmosValidatorWriter = newWriter("cosmos_validator", func(r repository.BaseRepository, record repository.CosmosValidator) error {
+		return (&repository.CosmosValidatorRepository{BaseRepository: r}).Save(record)
+	})
+	govWriter = newWriter("gov", func(r repository.BaseRepository, record govRecord) error {
+		return (&repository.GovRepository{BaseRepository: r}).Save(record.Event, record.Proposals)
+	})
+	hostResourceWriter = newWriter("host_resource", func(r repository.BaseRepository, record repository.HostResource) error {
+		return (&repository.HostResourceRepository{BaseRepository: r}).Save(record)
+	})
+	mempoolWriter = newWriter("mempool", func(r repository.BaseRepository, record repository.TendermintMempool) error {
+		return (&repository.MempoolRepository{BaseRepository: r}).Save(record)
+	})
+	netInfoWriter = newWriter("net_info", func(r repository.BaseRepository, record repository.TendermintNetInfo) error {
+		return (&repository.NetInfoRepository{BaseRepository: r}).Save(record)
+	})
+	metricWriter = newWriter("prometheus", func(r repository.BaseRepository, record metricRecord) error {
+		return (&repository.MetricRepository{BaseRepository: r}).Save(record.Event, record.Metrics)
+	})
+	upgradeWriter = newWriter("upgrade", func(r repository.BaseRepository, record upgradeRecord) error {
+		return (&repository.UpgradeRepository{BaseRepository: r}).Save(record.Event, record.Plan)
+	})
+	validatorsWriter = newWriter("validators", func(r repository.BaseRepository, record repository.TendermintValidatorSet) error {
+		return (&repository.ValidatorRepository{BaseRepository: r}).Save(record)
+	})
+)
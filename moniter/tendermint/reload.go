@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/monitor"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/scheduler"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/types"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// configWatchInterval is how often the config file is checked for changes.
+const configWatchInterval = 5 * time.Second
+
+// loadConfig reads the config file and applies env and defaults to it.
+func loadConfig(path string) (*types.MonitorConfig, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg types.MonitorConfig
+	err = yaml.Unmarshal(configBytes, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ApplyConfigFromEnvAndDefault()
+	if err != nil {
+		return nil, errors.New("Error occurred while parsing env. " + err.Error())
+	}
+	return &cfg, nil
+}
+
+// watchConfig reloads the config on SIGHUP or when the config file changes, until ctx is done.
+func watchConfig(ctx context.Context, sched *scheduler.Scheduler) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	lastHash, _ := fileHash(configFilePath)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			lastHash, _ = fileHash(configFilePath)
+			reloadConfig(sched, "SIGHUP")
+		case <-ticker.C:
+			hash, err := fileHash(configFilePath)
+			// The file may be missing for a moment while it's replaced.
+			if err != nil || hash == lastHash {
+				continue
+			}
+			lastHash = hash
+			reloadConfig(sched, "config file change")
+		}
+	}
+}
+
+// reloadConfig swaps in the schedule of the config file. Runs in flight are cancelled and awaited first,
+// so monitors of an agent removed from config have stopped writing when it's deregistered.
+// When the config is invalid, the running one is kept. Database and wal settings are applied on restart only.
+func reloadConfig(sched *scheduler.Scheduler, reason string) {
+	log.Info("[reload] reloading config by " + reason + ". file: " + configFilePath)
+
+	cfg, err := loadConfig(configFilePath)
+	if err != nil {
+		log.Error(errors.New("[reload] invalid config. keeping the running one. err: " + err.Error()))
+		return
+	}
+
+	jobs, clients, err := newJobs(cfg, agentClients)
+	if err != nil {
+		log.Error(errors.New("[reload] invalid config. keeping the running one. err: " + err.Error()))
+		return
+	}
+	err = sched.Reschedule(jobs)
+	if err != nil {
+		closeJobs(jobs)
+		log.Error(errors.New("[reload] invalid config. keeping the running one. err: " + err.Error()))
+		return
+	}
+
+	// Agents removed from config stop being monitored. Agents which remain are registered again, refreshing their metadata.
+	removed := make(map[string]agentClient)
+	for name, c := range agentClients {
+		if _, exists := clients[name]; !exists {
+			removed[name] = c
+		}
+	}
+	deregisterAgents(&mConfig, removed)
+	for _, c := range clients {
+		err = monitor.RegisterAgent(context.Background(), cfg.ForAgent(c.agent), c.client)
+		if err != nil {
+			log.Error(errors.New("[reload] could not register agent " + c.agent.AgentName + ". it'll be retried. err: " + err.Error()))
+		}
+	}
+
+	mConfig = *cfg
+	agentClientsMu.Lock()
+	agentClients = clients
+	agentClientsMu.Unlock()
+	log.Info("[reload] config reloaded. Agents: " + strings.Join(agentNamesOf(cfg), ", "))
+}
+
+func fileHash(path string) ([sha256.Size]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
@@ -0,0 +1,450 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is a single monitor registered into Scheduler.
+// Every job owns its own timer, so its Interval and Jitter never affect other jobs.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Jitter adds a random delay in [0, Jitter) to each scheduled run
+	// so that monitors sharing the same interval don't hit the RPC at the same moment.
+	Jitter time.Duration
+	// Run is given a context which is cancelled when the scheduler is stopping, or the job is removed or replaced by Reschedule.
+	// It should stop fetching new data then and flush what it has already collected.
+	Run func(ctx context.Context) error
+	// Close, if set, is called once the job stops being scheduled(removed or replaced by Reschedule, or Stop)
+	// and its run in flight, if any, has returned.
+	Close func() error
+}
+
+// Stats describes how a job has been scheduled so far.
+type Stats struct {
+	Runs    uint64
+	Skipped uint64
+	// LastLateness is how late the latest run started compared to its scheduled time.
+	LastLateness time.Duration
+	MaxLateness  time.Duration
+	LastStart    time.Time
+	LastDuration time.Duration
+	// Failures counts runs which returned an error.
+	Failures    uint64
+	LastSuccess time.Time
+}
+
+type entry struct {
+	job Job
+	// stop is closed to stop the loop scheduling this entry.
+	stop chan struct{}
+
+	mu      sync.Mutex
+	running bool
+	// gen is bumped whenever job is replaced, and runningGen is gen of the job in flight.
+	gen        uint64
+	runningGen uint64
+	// cancelRun cancels the context of the run in flight, and runDone is closed once it has returned and been closed if needed.
+	cancelRun context.CancelFunc
+	runDone   chan struct{}
+	// retired is set once the entry is removed or the scheduler is stopped. Its job is closed and never runs again.
+	retired bool
+	// skipping is set once a skip has been logged for the current run,
+	// so a long-running job(e.g. a streaming monitor) doesn't flood the log every interval.
+	skipping bool
+	stats    Stats
+}
+
+// currentRunning reports whether the current job is the one in flight. It must be called with e.mu held.
+func (e *entry) currentRunning() bool {
+	return e.running && e.runningGen == e.gen
+}
+
+// Scheduler runs each registered Job on its own schedule.
+// When a previous run of a job is still running at the next scheduled time, the run is skipped
+// instead of being stacked up, so one slow monitor can't starve the others.
+type Scheduler struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	started bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	loops   sync.WaitGroup
+	running sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{
+		entries: make(map[string]*entry),
+	}
+}
+
+func validateJob(job Job) error {
+	if job.Interval <= 0 {
+		return fmt.Errorf("interval of job(%s) must be greater than 0", job.Name)
+	}
+	if job.Jitter < 0 {
+		return fmt.Errorf("jitter of job(%s) must not be negative", job.Name)
+	}
+	return nil
+}
+
+// Add registers job. It must be called before Start.
+func (s *Scheduler) Add(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("scheduler already started. job: " + job.Name)
+	}
+	if err := validateJob(job); err != nil {
+		return err
+	}
+	if _, exists := s.entries[job.Name]; exists {
+		return errors.New("job already registered: " + job.Name)
+	}
+
+	s.entries[job.Name] = &entry{job: job, stop: make(chan struct{})}
+	return nil
+}
+
+// Reschedule replaces the registered jobs with the given ones. It can be called while the scheduler is running.
+// Runs in flight of jobs removed or replaced are cancelled, and it returns once they have returned,
+// so no run keeps going with the previous job. (e.g. a streaming monitor of an agent removed from config)
+// A job keeping its name keeps its schedule unless its Interval or Jitter has changed. When any job is invalid, nothing is changed.
+func (s *Scheduler) Reschedule(jobs []Job) error {
+	names := make(map[string]struct{}, len(jobs))
+	for _, job := range jobs {
+		if err := validateJob(job); err != nil {
+			return err
+		}
+		if _, exists := names[job.Name]; exists {
+			return errors.New("job already registered: " + job.Name)
+		}
+		names[job.Name] = struct{}{}
+	}
+
+	var (
+		toClose []Job
+		running []chan struct{}
+	)
+	defer func() {
+		closeJobs(toClose)
+		for _, done := range running {
+			<-done
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Jobs which are running are cancelled, and closed once their runs return instead.
+	for name, e := range s.entries {
+		if _, exists := names[name]; !exists {
+			close(e.stop)
+			delete(s.entries, name)
+
+			e.mu.Lock()
+			e.retired = true
+			if e.currentRunning() {
+				e.cancelRun()
+				running = append(running, e.runDone)
+			} else {
+				toClose = append(toClose, e.job)
+			}
+			e.mu.Unlock()
+		}
+	}
+
+	for _, job := range jobs {
+		e, exists := s.entries[job.Name]
+		if !exists {
+			e = &entry{job: job, stop: make(chan struct{})}
+			s.entries[job.Name] = e
+			s.startLoop(e)
+			continue
+		}
+
+		e.mu.Lock()
+		rescheduled := e.job.Interval != job.Interval || e.job.Jitter != job.Jitter
+		if e.currentRunning() {
+			e.cancelRun()
+			running = append(running, e.runDone)
+		} else {
+			toClose = append(toClose, e.job)
+		}
+		e.job = job
+		e.gen++
+		if rescheduled {
+			close(e.stop)
+			e.stop = make(chan struct{})
+		}
+		e.mu.Unlock()
+
+		if rescheduled {
+			s.startLoop(e)
+		}
+	}
+	return nil
+}
+
+// Start launches every registered job. The first run of each job starts right away (plus jitter).
+// Cancelling ctx stops scheduling, same as calling Stop.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.startLoop(e)
+	}
+}
+
+// startLoop starts scheduling the entry. It must be called with s.mu held, and does nothing before Start.
+func (s *Scheduler) startLoop(e *entry) {
+	if !s.started || s.ctx.Err() != nil {
+		return
+	}
+	e.mu.Lock()
+	var (
+		job  = e.job
+		stop = e.stop
+	)
+	e.mu.Unlock()
+
+	s.loops.Add(1)
+	go s.loop(e, job, stop)
+}
+
+// Stop stops scheduling new runs, cancels the context given to in-flight runs
+// and waits up to drainTimeout for them to flush and return. Then jobs are closed.
+// It returns an error naming the jobs that were still running when drainTimeout elapsed. They're closed whenever they return.
+func (s *Scheduler) Stop(drainTimeout time.Duration) error {
+	defer s.retireAll()
+
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.mu.Unlock()
+
+	s.loops.Wait()
+
+	finished := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-time.After(drainTimeout):
+		return fmt.Errorf("drain timeout(%v) exceeded. still running: %s", drainTimeout, strings.Join(s.runningJobs(), ", "))
+	}
+}
+
+// retireAll closes every job which isn't running. Running ones are closed by themselves once they return.
+func (s *Scheduler) retireAll() {
+	var toClose []Job
+
+	s.mu.Lock()
+	for _, e := range s.entries {
+		e.mu.Lock()
+		if !e.retired {
+			e.retired = true
+			if !e.currentRunning() {
+				toClose = append(toClose, e.job)
+			}
+		}
+		e.mu.Unlock()
+	}
+	s.mu.Unlock()
+
+	closeJobs(toClose)
+}
+
+func closeJobs(jobs []Job) {
+	for _, job := range jobs {
+		if job.Close == nil {
+			continue
+		}
+		err := job.Close()
+		if err != nil {
+			log.Error(fmt.Errorf("[scheduler] closing %s failed: %w", job.Name, err))
+		}
+	}
+}
+
+func (s *Scheduler) runningJobs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for name, e := range s.entries {
+		e.mu.Lock()
+		if e.running {
+			names = append(names, name)
+		}
+		e.mu.Unlock()
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns a snapshot of the job's scheduling stats.
+func (s *Scheduler) Stats(name string) (Stats, bool) {
+	s.mu.Lock()
+	e, exists := s.entries[name]
+	s.mu.Unlock()
+	if !exists {
+		return Stats{}, false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats, true
+}
+
+// AllStats returns a snapshot of every job's scheduling stats by name.
+func (s *Scheduler) AllStats() map[string]Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]Stats, len(s.entries))
+	for name, e := range s.entries {
+		e.mu.Lock()
+		result[name] = e.stats
+		e.mu.Unlock()
+	}
+	return result
+}
+
+// loop schedules the entry by the job's Interval and Jitter until the scheduler stops or stop is closed.
+func (s *Scheduler) loop(e *entry, job Job, stop chan struct{}) {
+	defer s.loops.Done()
+
+	var (
+		next    = time.Now()
+		planned = next.Add(jitter(job.Jitter))
+		timer   = time.NewTimer(time.Until(planned))
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-timer.C:
+			s.fire(e, planned)
+
+			// Keep the schedule anchored to the planned time rather than to the time the run finished,
+			// and skip the slots we've already missed.
+			next = next.Add(job.Interval)
+			if now := time.Now(); next.Before(now) {
+				missed := now.Sub(next)/job.Interval + 1
+				next = next.Add(missed * job.Interval)
+			}
+			planned = next.Add(jitter(job.Jitter))
+			timer.Reset(time.Until(planned))
+		}
+	}
+}
+
+func (s *Scheduler) fire(e *entry, scheduledAt time.Time) {
+	e.mu.Lock()
+	if e.retired {
+		e.mu.Unlock()
+		return
+	}
+	// Job may be replaced by Reschedule meanwhile, the run keeps the one it started with.
+	job, gen := e.job, e.gen
+	if e.running {
+		e.stats.Skipped++
+		alreadyLogged := e.skipping
+		e.skipping = true
+		e.mu.Unlock()
+		if alreadyLogged {
+			return
+		}
+		log.Warn(fmt.Sprintf("[scheduler] previous run of %s is still running. skipping this run(scheduled at %v)", job.Name, scheduledAt))
+		return
+	}
+
+	start := time.Now()
+	lateness := start.Sub(scheduledAt)
+	if lateness < 0 {
+		lateness = 0
+	}
+
+	runCtx, cancelRun := context.WithCancel(s.ctx)
+	runDone := make(chan struct{})
+	e.running = true
+	e.runningGen = gen
+	e.cancelRun = cancelRun
+	e.runDone = runDone
+	e.skipping = false
+	e.stats.Runs++
+	e.stats.LastStart = start
+	e.stats.LastLateness = lateness
+	if lateness > e.stats.MaxLateness {
+		e.stats.MaxLateness = lateness
+	}
+	e.mu.Unlock()
+
+	if lateness > job.Interval {
+		log.Warn(fmt.Sprintf("[scheduler] %s started %v late", job.Name, lateness))
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer close(runDone)
+		defer cancelRun()
+		err := job.Run(runCtx)
+
+		e.mu.Lock()
+		e.running = false
+		e.stats.LastDuration = time.Since(start)
+		if err != nil {
+			e.stats.Failures++
+		} else {
+			e.stats.LastSuccess = time.Now()
+		}
+		// The job has been unscheduled while running, nobody else closes it.
+		closing := e.retired || e.gen != gen
+		e.mu.Unlock()
+
+		if err != nil {
+			log.Error(fmt.Errorf("[scheduler] %s failed: %w", job.Name, err))
+		}
+		if closing {
+			closeJobs([]Job{job})
+		}
+	}()
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func noop(context.Context) error {
+	return nil
+}
+
+func Test(t *testing.T) {
+
+	t.Run("slow job is skipped without delaying others", func(t *testing.T) {
+		var (
+			slowRuns atomic.Int32
+			fastRuns atomic.Int32
+		)
+
+		s := New()
+		assert.NoError(t, s.Add(Job{Name: "slow", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			slowRuns.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}}))
+		assert.NoError(t, s.Add(Job{Name: "fast", Interval: 10 * time.Millisecond, Jitter: time.Millisecond, Run: func(ctx context.Context) error {
+			fastRuns.Add(1)
+			return nil
+		}}))
+
+		s.Start(context.Background())
+		time.Sleep(75 * time.Millisecond)
+		assert.NoError(t, s.Stop(time.Second))
+
+		assert.Equal(t, int32(1), slowRuns.Load())
+		assert.Greater(t, fastRuns.Load(), int32(3))
+
+		slowStats, exists := s.Stats("slow")
+		assert.True(t, exists)
+		assert.Greater(t, slowStats.Skipped, uint64(0))
+
+		fastStats, _ := s.Stats("fast")
+		assert.Equal(t, uint64(0), fastStats.Skipped)
+	})
+
+	t.Run("invalid jobs are rejected", func(t *testing.T) {
+		s := New()
+		assert.Error(t, s.Add(Job{Name: "zero", Interval: 0, Run: noop}))
+		assert.NoError(t, s.Add(Job{Name: "dup", Interval: time.Second, Run: noop}))
+		assert.Error(t, s.Add(Job{Name: "dup", Interval: time.Second, Run: noop}))
+
+		s.Start(context.Background())
+		assert.Error(t, s.Add(Job{Name: "late", Interval: time.Second, Run: noop}))
+		assert.NoError(t, s.Stop(time.Second))
+	})
+
+	t.Run("stop cancels in-flight runs and bounds the drain", func(t *testing.T) {
+		var flushed atomic.Bool
+
+		s := New()
+		assert.NoError(t, s.Add(Job{Name: "flush", Interval: time.Hour, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			flushed.Store(true)
+			return nil
+		}}))
+		assert.NoError(t, s.Add(Job{Name: "stuck", Interval: time.Hour, Run: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}}))
+
+		s.Start(context.Background())
+		time.Sleep(10 * time.Millisecond)
+
+		err := s.Stop(50 * time.Millisecond)
+		assert.ErrorContains(t, err, "stuck")
+		assert.NotContains(t, err.Error(), "flush")
+		assert.True(t, flushed.Load())
+	})
+
+	t.Run("reschedule cancels in-flight runs and replaces jobs", func(t *testing.T) {
+		var (
+			oldRuns          atomic.Int32
+			newRuns          atomic.Int32
+			addedRuns        atomic.Int32
+			cancelled        atomic.Bool
+			removedCancelled atomic.Bool
+		)
+
+		s := New()
+		assert.NoError(t, s.Add(Job{Name: "stream", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			oldRuns.Add(1)
+			<-ctx.Done()
+			// Flushing takes a while, Reschedule waits for it.
+			time.Sleep(10 * time.Millisecond)
+			cancelled.Store(true)
+			return nil
+		}}))
+		assert.NoError(t, s.Add(Job{Name: "removed", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			removedCancelled.Store(true)
+			return nil
+		}}))
+
+		s.Start(context.Background())
+		time.Sleep(20 * time.Millisecond)
+
+		// Invalid jobs change nothing.
+		assert.Error(t, s.Reschedule([]Job{{Name: "stream", Interval: 0, Run: noop}}))
+		assert.False(t, cancelled.Load())
+
+		assert.NoError(t, s.Reschedule([]Job{
+			{Name: "stream", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+				newRuns.Add(1)
+				return nil
+			}},
+			{Name: "added", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+				addedRuns.Add(1)
+				return nil
+			}},
+		}))
+		// Runs of the replaced and removed jobs have returned by then.
+		assert.True(t, cancelled.Load())
+		assert.True(t, removedCancelled.Load())
+		_, exists := s.Stats("removed")
+		assert.False(t, exists)
+
+		time.Sleep(30 * time.Millisecond)
+		assert.NoError(t, s.Stop(time.Second))
+
+		assert.Equal(t, int32(1), oldRuns.Load())
+		assert.Greater(t, newRuns.Load(), int32(0))
+		assert.Greater(t, addedRuns.Load(), int32(0))
+	})
+
+	t.Run("jobs are closed once unscheduled and their runs returned", func(t *testing.T) {
+		closed := make(map[string]*atomic.Int32)
+		closer := func(name string) func() error {
+			closed[name] = &atomic.Int32{}
+			return func() error {
+				closed[name].Add(1)
+				return nil
+			}
+		}
+
+		s := New()
+		assert.NoError(t, s.Add(Job{Name: "stream", Interval: 10 * time.Millisecond, Close: closer("old stream"), Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}}))
+		assert.NoError(t, s.Add(Job{Name: "removed", Interval: 10 * time.Millisecond, Close: closer("removed"), Run: noop}))
+		assert.NoError(t, s.Add(Job{Name: "kept", Interval: 10 * time.Millisecond, Close: closer("old kept"), Run: noop}))
+
+		s.Start(context.Background())
+		time.Sleep(20 * time.Millisecond)
+
+		assert.NoError(t, s.Reschedule([]Job{
+			{Name: "stream", Interval: 10 * time.Millisecond, Close: closer("new stream"), Run: noop},
+			{Name: "kept", Interval: 10 * time.Millisecond, Close: closer("new kept"), Run: noop},
+		}))
+		assert.Equal(t, int32(1), closed["removed"].Load())
+		assert.Equal(t, int32(1), closed["old kept"].Load())
+		// The old stream has been cancelled, and closed once it returned.
+		assert.Equal(t, int32(1), closed["old stream"].Load())
+		assert.Equal(t, int32(0), closed["new stream"].Load())
+
+		assert.NoError(t, s.Stop(time.Second))
+		for name, count := range closed {
+			assert.Equal(t, int32(1), count.Load(), name)
+		}
+	})
+
+}
@@ -8,53 +8,131 @@ import (
 	"fmt"
 	database "github.com/b-harvest/Harvestmon/database"
 	log "github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/wal"
 	gorm_mysql "gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"io"
 	"net/http"
+	"net/url"
 	"reflect"
 	"runtime"
 	"strconv"
-	"strings"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	statusEndpoint  = "/status"
-	netInfoEndpoint = "/net_info"
-	commitEndpoint  = "/commit"
+	statusEndpoint             = "/status"
+	netInfoEndpoint            = "/net_info"
+	commitEndpoint             = "/commit"
+	validatorsEndpoint         = "/validators"
+	consensusStateEndpoint     = "/consensus_state"
+	dumpConsensusStateEndpoint = "/dump_consensus_state"
+	numUnconfirmedTxsEndpoint  = "/num_unconfirmed_txs"
+	abciInfoEndpoint           = "/abci_info"
+	blockResultsEndpoint       = "/block_results"
+	blockchainEndpoint         = "/blockchain"
+	blockSearchEndpoint        = "/block_search"
 )
 
 type HttpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// MonitorClient queries an agent(node). Clients of the agents monitored by a process
+// share the http client and the database pool, while endpoints and timeout are the agent's own.
 type MonitorClient struct {
-	httpClient   HttpClient
-	hostWithPort string
-	timeout      time.Duration
-	retries      int
-	DB           *sql.DB
+	httpClient HttpClient
+	endpoints  *endpointPool
+	// transports are of the agent's endpoints by String(). An endpoint without one is requested by httpClient as it is.
+	transports map[string]*endpointTransport
+	timeout    time.Duration
+	retries    int
+	wal        *wal.Queue
+	sink       *collector.Client
+	limiter    *adaptiveLimiter
+	// DB is nil in sink mode.
+	DB *sql.DB
+
+	// blockSearchDisabledUntil is unix nano until which /block_search is avoided.
+	blockSearchDisabledUntil atomic.Int64
 }
 
+// NewMonitorClient opens the database pool, or the collector client in sink mode, and the wal queue shared by every agent.
+// Use ForAgent to query an agent.
 func NewMonitorClient(cfg *MonitorConfig, httpClient HttpClient, configFilePath string) *MonitorClient {
-	hostWithPort := fmt.Sprintf("%s:%s", cfg.Agent.Host, strconv.Itoa(cfg.Agent.Port))
+	var (
+		db   *sql.DB
+		sink *collector.Client
+		err  error
+	)
+	if cfg.Sink.Address != "" {
+		sink, err = collector.NewClient(cfg.Sink.Address, cfg.Sink.Token, *cfg.Sink.Timeout, *cfg.Sink.Retries, cfg.Sink.CAFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Info("[sink] records will be pushed to the collector at " + cfg.Sink.Address)
+	} else {
+		db, err = database.GetDatabase(configFilePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	db, err := database.GetDatabase(configFilePath)
-	if err != nil {
-		log.Fatal(err)
+	var queue *wal.Queue
+	if !cfg.Wal.Disabled {
+		queue, err = wal.Open(cfg.Wal.Dir, cfg.Wal.MaxBytes)
+		if err != nil {
+			log.Error(errors.New("Could not open wal. failed writes won't be buffered. dir: " + cfg.Wal.Dir + ", err: " + err.Error()))
+		} else if queue.Len() > 0 {
+			log.Info(fmt.Sprintf("[wal] %d buffered writes found. they'll be replayed", queue.Len()))
+		}
 	}
-	rpcClient := MonitorClient{
-		httpClient:   httpClient,
-		hostWithPort: hostWithPort,
-		timeout:      *cfg.Agent.Timeout,
-		retries:      3,
-		DB:           db,
+
+	return &MonitorClient{
+		httpClient: httpClient,
+		wal:        queue,
+		sink:       sink,
+		DB:         db,
 	}
-	return &rpcClient
 }
 
+// ForAgent returns a client of the agent sharing the http client and the database pool.
+func (r *MonitorClient) ForAgent(agent *MonitoringAgent) (*MonitorClient, error) {
+	var err error
+	transports := make(map[string]*endpointTransport, len(agent.Endpoints))
+	for _, endpoint := range agent.Endpoints {
+		transports[endpoint.String()], err = newEndpointTransport(endpoint, r.httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &MonitorClient{
+		httpClient: r.httpClient,
+		endpoints:  newEndpointPool(agent.Endpoints),
+		transports: transports,
+		timeout:    *agent.Timeout,
+		retries:    3,
+		wal:        r.wal,
+		sink:       r.sink,
+		limiter:    newAdaptiveLimiter(agent.BlockCommitMaxConcurrency),
+		DB:         r.DB,
+	}, nil
+}
+
+// WAL returns the queue buffering failed writes. It's nil when wal is disabled or couldn't be opened.
+func (r *MonitorClient) WAL() *wal.Queue {
+	return r.wal
+}
+
+// Sink returns the collector client, which records are pushed to instead of the database. It's nil unless in sink mode.
+func (r *MonitorClient) Sink() *collector.Client {
+	return r.sink
+}
+
+// GetDatabase must not be called in sink mode. Check Sink first.
 func (r *MonitorClient) GetDatabase(batchSize int) *gorm.DB {
 	if batchSize == 0 {
 		batchSize = 100
@@ -66,105 +144,220 @@ func (r *MonitorClient) GetDatabase(batchSize int) *gorm.DB {
 	return gormDB
 }
 
-func (r *MonitorClient) GetCometBFTStatus() (*ResultStatus, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
-	defer cancel()
-	req, err := requestGet(ctx, r.getAddress(statusEndpoint))
+// EndpointStatus returns health of the agent's rpc endpoints in order of priority.
+func (r *MonitorClient) EndpointStatus() []EndpointStatus {
+	return r.endpoints.status()
+}
+
+func (r *MonitorClient) GetCometBFTStatus(ctx context.Context) (*CometBFTStatusResult, error) {
+	body, endpoint, err := r.request(ctx, statusEndpoint)
 	if err != nil {
 		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCometBFTStatus).Pointer()).Name()
 		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
 	}
 
-	var (
-		body         []byte
-		statusResult CometBFTStatusResult
-	)
-	body, err = request(r.httpClient, req, r.retries)
+	var statusResult CometBFTStatusResult
+	err = json.Unmarshal(body, &statusResult)
+	if err != nil {
+		return nil, errors.New("Json marshaling error: " + err.Error())
+	}
+	statusResult.Endpoint = endpoint
+
+	return &statusResult, nil
+}
+
+func (r *MonitorClient) GetNetInfo(ctx context.Context) (*CometBFTNetInfoResult, error) {
+	body, endpoint, err := r.request(ctx, netInfoEndpoint)
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetNetInfo).Pointer()).Name()
+		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
+	}
+
+	var resultStatus CometBFTNetInfoResult
+	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
 
-	err = json.Unmarshal(body, &statusResult)
+	return &resultStatus, nil
+}
+
+func (r *MonitorClient) GetCommit(ctx context.Context) (*CometBFTCommitResult, error) {
+	body, endpoint, err := r.request(ctx, commitEndpoint)
 	if err != nil {
-		return nil, errors.New("Json marshaling error: " + err.Error())
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCommit).Pointer()).Name()
+		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
 	}
-	return &statusResult.Result, nil
+
+	var resultStatus CometBFTCommitResult
+	err = json.Unmarshal(body, &resultStatus)
+	if err != nil {
+		return nil, err
+	}
+	resultStatus.Endpoint = endpoint
+
+	return &resultStatus, nil
 }
 
-func (r *MonitorClient) GetNetInfo() (*CometBFTNetInfoResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
-	defer cancel()
-	req, err := requestGet(ctx, r.getAddress(netInfoEndpoint))
+func (r *MonitorClient) GetCommitWithHeight(ctx context.Context, height uint64) (*CometBFTCommitResult, error) {
+	body, endpoint, err := r.request(ctx, fmt.Sprintf("%s?height=%d", commitEndpoint, height))
 	if err != nil {
-		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCometBFTStatus).Pointer()).Name()
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCommitWithHeight).Pointer()).Name()
+		return nil, fmt.Errorf("Could not fetch rpc status. functionName: %s, err: %w", funcName, err)
+	}
+
+	var resultStatus CometBFTCommitResult
+	err = json.Unmarshal(body, &resultStatus)
+	if err != nil {
+		return nil, err
+	}
+	resultStatus.Endpoint = endpoint
+
+	return &resultStatus, nil
+}
+
+func (r *MonitorClient) GetValidatorsWithHeight(ctx context.Context, height uint64, page, perPage int) (*CometBFTValidatorsResult, error) {
+	body, endpoint, err := r.request(ctx, fmt.Sprintf("%s?height=%d&page=%d&per_page=%d", validatorsEndpoint, height, page, perPage))
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetValidatorsWithHeight).Pointer()).Name()
 		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
 	}
 
-	var (
-		body         []byte
-		resultStatus CometBFTNetInfoResult
-	)
-	body, err = request(r.httpClient, req, r.retries)
+	var resultStatus CometBFTValidatorsResult
+	err = json.Unmarshal(body, &resultStatus)
+	if err != nil {
+		return nil, err
+	}
+	resultStatus.Endpoint = endpoint
+
+	return &resultStatus, nil
+}
+
+func (r *MonitorClient) GetConsensusState(ctx context.Context) (*CometBFTConsensusStateResult, error) {
+	body, endpoint, err := r.request(ctx, consensusStateEndpoint)
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetConsensusState).Pointer()).Name()
+		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
+	}
+
+	var resultStatus CometBFTConsensusStateResult
+	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
 
+	return &resultStatus, nil
+}
+
+func (r *MonitorClient) GetDumpConsensusState(ctx context.Context) (*CometBFTDumpConsensusStateResult, error) {
+	body, endpoint, err := r.request(ctx, dumpConsensusStateEndpoint)
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetDumpConsensusState).Pointer()).Name()
+		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
+	}
+
+	var resultStatus CometBFTDumpConsensusStateResult
 	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
 
 	return &resultStatus, nil
 }
 
-func (r *MonitorClient) GetCommit() (*CometBFTCommitResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
-	defer cancel()
-	req, err := requestGet(ctx, r.getAddress(commitEndpoint))
+func (r *MonitorClient) GetNumUnconfirmedTxs(ctx context.Context) (*CometBFTUnconfirmedTxsResult, error) {
+	body, endpoint, err := r.request(ctx, numUnconfirmedTxsEndpoint)
 	if err != nil {
-		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCometBFTStatus).Pointer()).Name()
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetNumUnconfirmedTxs).Pointer()).Name()
 		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
 	}
 
-	var (
-		body         []byte
-		resultStatus CometBFTCommitResult
-	)
-	body, err = request(r.httpClient, req, r.retries)
+	var resultStatus CometBFTUnconfirmedTxsResult
+	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
+
+	return &resultStatus, nil
+}
+
+func (r *MonitorClient) GetAbciInfo(ctx context.Context) (*CometBFTAbciInfoResult, error) {
+	body, endpoint, err := r.request(ctx, abciInfoEndpoint)
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetAbciInfo).Pointer()).Name()
+		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
+	}
 
+	var resultStatus CometBFTAbciInfoResult
 	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
 
 	return &resultStatus, nil
 }
 
-func (r *MonitorClient) GetCommitWithHeight(height uint64) (*CometBFTCommitResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
-	defer cancel()
-	req, err := requestGet(ctx, fmt.Sprintf("%s?height=%d", r.getAddress(commitEndpoint), height))
+func (r *MonitorClient) GetBlockResultsWithHeight(ctx context.Context, height uint64) (*CometBFTBlockResultsResult, error) {
+	body, endpoint, err := r.request(ctx, fmt.Sprintf("%s?height=%d", blockResultsEndpoint, height))
 	if err != nil {
-		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetCometBFTStatus).Pointer()).Name()
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetBlockResultsWithHeight).Pointer()).Name()
 		return nil, errors.New("Could not fetch rpc status. functionName: " + funcName + ", err: " + err.Error())
 	}
 
-	var (
-		body         []byte
-		resultStatus CometBFTCommitResult
-	)
-	body, err = request(r.httpClient, req, r.retries)
+	var resultStatus CometBFTBlockResultsResult
+	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	resultStatus.Endpoint = endpoint
 
+	return &resultStatus, nil
+}
+
+// GetBlockchain returns headers of [minHeight, maxHeight]. The rpc returns at most 20 of them.
+func (r *MonitorClient) GetBlockchain(ctx context.Context, minHeight, maxHeight uint64) (*CometBFTBlockchainResult, error) {
+	body, endpoint, err := r.request(ctx, fmt.Sprintf("%s?minHeight=%d&maxHeight=%d", blockchainEndpoint, minHeight, maxHeight))
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.GetBlockchain).Pointer()).Name()
+		return nil, fmt.Errorf("Could not fetch rpc status. functionName: %s, err: %w", funcName, err)
+	}
+
+	var resultStatus CometBFTBlockchainResult
 	err = json.Unmarshal(body, &resultStatus)
 	if err != nil {
 		return nil, err
 	}
+	if resultStatus.Error != nil {
+		return nil, resultStatus.Error
+	}
+	resultStatus.Endpoint = endpoint
+
+	return &resultStatus, nil
+}
+
+// SearchBlocks returns blocks of [minHeight, maxHeight] in ascending order. It fails when the node doesn't index blocks.
+func (r *MonitorClient) SearchBlocks(ctx context.Context, minHeight, maxHeight uint64, page, perPage int) (*CometBFTBlockSearchResult, error) {
+	query := url.QueryEscape(fmt.Sprintf(`"block.height >= %d AND block.height <= %d"`, minHeight, maxHeight))
+	body, endpoint, err := r.request(ctx, fmt.Sprintf(`%s?query=%s&page=%d&per_page=%d&order_by="asc"`, blockSearchEndpoint, query, page, perPage))
+	if err != nil {
+		funcName := runtime.FuncForPC(reflect.ValueOf(r.SearchBlocks).Pointer()).Name()
+		return nil, fmt.Errorf("Could not fetch rpc status. functionName: %s, err: %w", funcName, err)
+	}
+
+	var resultStatus CometBFTBlockSearchResult
+	err = json.Unmarshal(body, &resultStatus)
+	if err != nil {
+		return nil, err
+	}
+	if resultStatus.Error != nil {
+		return nil, resultStatus.Error
+	}
+	resultStatus.Endpoint = endpoint
 
 	return &resultStatus, nil
 }
@@ -173,40 +366,88 @@ func requestGet(ctx context.Context, address string) (*http.Request, error) {
 	return http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
 }
 
-func request(c HttpClient, request *http.Request, retries int) ([]byte, error) {
-	var errMsg string
-	for i := 0; i < retries; i++ {
-		res, err := c.Do(request)
-		if err != nil {
-			errMsg = errors.New("err: " + err.Error() + ", " + runtime.FuncForPC(reflect.ValueOf(request).Pointer()).Name() + ".Retries " + strconv.Itoa(i) + "...").Error()
-			log.Warn(errMsg)
-			time.Sleep(1 * time.Second)
-			continue
+// request sends GET request to the healthiest endpoint and fails over to the next one on error.
+// It returns the response body and the endpoint which served it.
+func (r *MonitorClient) request(ctx context.Context, path string) ([]byte, string, error) {
+	var (
+		lastErr    error
+		candidates = r.endpoints.candidates()
+		attempts   = r.retries
+	)
+	if len(candidates) == 0 {
+		return nil, "", errors.New("no rpc endpoint configured")
+	}
+	if attempts < len(candidates) {
+		attempts = len(candidates)
+	}
+
+	for i := 0; i < attempts; i++ {
+		// Don't retry once the request's context is done. (e.g. shutting down)
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+		// Every endpoint has been tried once. wait a bit before trying them again.
+		if i >= len(candidates) {
+			sleepWithContext(ctx, 1*time.Second)
 		}
 
-		body, err := io.ReadAll(res.Body)
+		endpoint := candidates[i%len(candidates)]
+		body, err := r.requestEndpoint(ctx, endpoint, path)
+		if ctx.Err() != nil {
+			// Cancelled by caller, it's not the endpoint's fault.
+			return nil, "", ctx.Err()
+		}
+		r.endpoints.report(endpoint, err == nil)
 		if err != nil {
-			errMsg = errors.New("err: " + err.Error() + ", " + runtime.FuncForPC(reflect.ValueOf(request).Pointer()).Name() + ".Retries " + strconv.Itoa(i) + "...").Error()
-			log.Warn(errMsg)
-			time.Sleep(1 * time.Second)
+			lastErr = fmt.Errorf("err: %w, endpoint: %s. Retries %s...", err, endpoint.String(), strconv.Itoa(i))
+			log.Warn(lastErr.Error())
 			continue
 		}
-		defer res.Body.Close()
 
-		return body, nil
+		return body, endpoint.String(), nil
 	}
 
-	return nil, errors.New(errMsg)
+	return nil, "", lastErr
 }
 
-func (r *MonitorClient) getAddress(endpoint string) string {
-	hostName := r.hostWithPort
-	if strings.Contains(hostName, "http") {
-		return fmt.Sprintf("%s%s", hostName, endpoint)
-	} else if strings.Contains(hostName, "443") {
-		return fmt.Sprintf("https://%s%s", hostName, endpoint)
-	} else {
-		return fmt.Sprintf("http://%s%s", hostName, endpoint)
+func (r *MonitorClient) requestEndpoint(ctx context.Context, endpoint Endpoint, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := requestGet(ctx, endpoint.url(path))
+	if err != nil {
+		return nil, err
+	}
+	httpClient := r.httpClient
+	if transport, exists := r.transports[endpoint.String()]; exists {
+		header, err := transport.header()
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		httpClient = transport.httpClient
 	}
 
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError {
+		return nil, &StatusCodeError{StatusCode: res.StatusCode}
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
 }
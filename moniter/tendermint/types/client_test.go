@@ -0,0 +1,212 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testEndpoint(t *testing.T, server *httptest.Server) Endpoint {
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	assert.NoError(t, err)
+	return Endpoint{Host: u.Hostname(), Port: port}
+}
+
+func TestMonitorClient(t *testing.T) {
+
+	t.Run("fails over to next endpoint and fails back after recovery", func(t *testing.T) {
+		var primaryDown atomic.Bool
+		primaryDown.Store(true)
+
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if primaryDown.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(`{"result":{}}`))
+		}))
+		defer primary.Close()
+		secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"result":{}}`))
+		}))
+		defer secondary.Close()
+
+		primaryEndpoint, secondaryEndpoint := testEndpoint(t, primary), testEndpoint(t, secondary)
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{primaryEndpoint, secondaryEndpoint}),
+			timeout:    time.Second,
+			retries:    2,
+		}
+
+		for i := 0; i < endpointFailureThreshold; i++ {
+			status, err := client.GetCometBFTStatus(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, secondaryEndpoint.String(), status.Endpoint)
+		}
+		assert.False(t, client.EndpointStatus()[0].Healthy)
+		assert.Equal(t, []Endpoint{secondaryEndpoint, primaryEndpoint}, client.endpoints.candidates())
+
+		// Primary recovered and the probe interval has passed.
+		primaryDown.Store(false)
+		client.endpoints.healths[0].lastFailure = time.Now().Add(-endpointProbeInterval)
+
+		status, err := client.GetCometBFTStatus(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, primaryEndpoint.String(), status.Endpoint)
+		assert.True(t, client.EndpointStatus()[0].Healthy)
+	})
+
+	t.Run("returns error when every endpoint fails", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer down.Close()
+
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{testEndpoint(t, down)}),
+			timeout:    time.Second,
+			retries:    1,
+		}
+
+		_, err := client.GetNetInfo(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("subscribes new blocks through websocket", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, websocketEndpoint, r.URL.Path)
+			conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			var request websocketRequest
+			assert.NoError(t, conn.ReadJSON(&request))
+			assert.Equal(t, "subscribe", request.Method)
+			assert.Equal(t, newBlockQuery, request.Params["query"])
+
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+			for _, height := range []string{"10", "11"} {
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"result":{"query":"tm.event='NewBlock'","data":{"type":"tendermint/event/NewBlock","value":{"block":{"header":{"chain_id":"test","height":"`+height+`"},"last_commit":{"height":"9","round":0,"signatures":[]}}}}}}`))
+			}
+		}))
+		defer server.Close()
+
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{testEndpoint(t, server)}),
+			timeout:    time.Second,
+			retries:    1,
+		}
+
+		var heights []string
+		err := client.SubscribeNewBlock(context.Background(), func(event *NewBlockEvent) error {
+			heights = append(heights, event.Block.Header.Height)
+			assert.NotNil(t, event.Block.LastCommit)
+			return nil
+		})
+		// Server closes the connection after sending the blocks.
+		assert.ErrorContains(t, err, "websocket connection lost")
+		assert.Equal(t, []string{"10", "11"}, heights)
+	})
+
+	t.Run("fetches commits in batches", func(t *testing.T) {
+		var blockchainCount, blockSearchCount, commitCount atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case blockchainEndpoint:
+				blockchainCount.Add(1)
+				minHeight, _ := strconv.Atoi(r.URL.Query().Get("minHeight"))
+				maxHeight, _ := strconv.Atoi(r.URL.Query().Get("maxHeight"))
+				assert.LessOrEqual(t, maxHeight-minHeight+1, commitBatchSize)
+				var metas []string
+				for height := maxHeight; height >= minHeight; height-- {
+					metas = append(metas, `{"header":{"chain_id":"test","height":"`+strconv.Itoa(height)+`"}}`)
+				}
+				w.Write([]byte(`{"result":{"block_metas":[` + strings.Join(metas, ",") + `]}}`))
+			case blockSearchEndpoint:
+				blockSearchCount.Add(1)
+				var minHeight, maxHeight int
+				_, err := fmt.Sscanf(r.URL.Query().Get("query"), `"block.height >= %d AND block.height <= %d"`, &minHeight, &maxHeight)
+				assert.NoError(t, err)
+				var blocks []string
+				for height := minHeight; height <= maxHeight; height++ {
+					blocks = append(blocks, `{"block":{"header":{"height":"`+strconv.Itoa(height)+`"},"last_commit":{"height":"`+strconv.Itoa(height-1)+`","signatures":[{"validator_address":"A"}]}}}`)
+				}
+				w.Write([]byte(`{"result":{"blocks":[` + strings.Join(blocks, ",") + `]}}`))
+			case commitEndpoint:
+				commitCount.Add(1)
+				w.Write([]byte(`{"result":{"signed_header":{"header":{"height":"` + r.URL.Query().Get("height") + `"},"commit":{}}}}`))
+			}
+		}))
+		defer server.Close()
+
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{testEndpoint(t, server)}),
+			timeout:    time.Second,
+			retries:    1,
+			limiter:    newAdaptiveLimiter(10),
+		}
+
+		var heights []uint64
+		for height := uint64(1); height <= 45; height++ {
+			heights = append(heights, height)
+		}
+		results := client.GetCommitsOfHeights(context.Background(), heights)
+		assert.Len(t, results, 45)
+		for _, result := range results {
+			assert.Len(t, result.Result.Commit.Signatures, 1)
+			assert.Equal(t, result.Result.Header.Height, result.Result.Commit.Height)
+		}
+		assert.Equal(t, int32(3), blockchainCount.Load())
+		assert.Equal(t, int32(3), blockSearchCount.Load())
+		assert.Equal(t, int32(0), commitCount.Load())
+	})
+
+	t.Run("fetches commits one by one when block search is unavailable", func(t *testing.T) {
+		var blockSearchCount, commitCount atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case blockchainEndpoint:
+				w.Write([]byte(`{"result":{"block_metas":[{"header":{"height":"1"}},{"header":{"height":"2"}}]}}`))
+			case blockSearchEndpoint:
+				blockSearchCount.Add(1)
+				w.Write([]byte(`{"error":{"code":-32603,"message":"Internal error","data":"block indexing is disabled"}}`))
+			case commitEndpoint:
+				commitCount.Add(1)
+				w.Write([]byte(`{"result":{"signed_header":{"header":{"height":"` + r.URL.Query().Get("height") + `"},"commit":{}}}}`))
+			}
+		}))
+		defer server.Close()
+
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{testEndpoint(t, server)}),
+			timeout:    time.Second,
+			retries:    1,
+			limiter:    newAdaptiveLimiter(10),
+		}
+
+		assert.Len(t, client.GetCommitsOfHeights(context.Background(), []uint64{1, 2}), 2)
+		assert.Len(t, client.GetCommitsOfHeights(context.Background(), []uint64{1, 2}), 2)
+		// Block search isn't tried again until the retry interval passes.
+		assert.Equal(t, int32(1), blockSearchCount.Load())
+		assert.Equal(t, int32(4), commitCount.Load())
+	})
+
+}
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// commitBatchSize is how many heights a /blockchain or /block_search request covers. /blockchain returns 20 headers at most.
+	commitBatchSize = 20
+	// blockSearchRetryInterval is how long /block_search is avoided after a failure. e.g. the node doesn't index blocks
+	blockSearchRetryInterval = 10 * time.Minute
+)
+
+type heightRange struct {
+	min, max uint64
+}
+
+// batchHeights groups the heights into ranges of consecutive heights, size of each one is at most batchSize.
+func batchHeights(heights []uint64, batchSize uint64) []heightRange {
+	var batches []heightRange
+	for _, height := range heights {
+		if n := len(batches); n > 0 && batches[n-1].max+1 == height && height-batches[n-1].min < batchSize {
+			batches[n-1].max = height
+			continue
+		}
+		batches = append(batches, heightRange{min: height, max: height})
+	}
+	return batches
+}
+
+type headerResult struct {
+	header   Header
+	endpoint string
+}
+
+// GetCommitsOfHeights returns commits of the heights in no particular order. Heights which failed to be fetched are logged and left out.
+// Headers are fetched from /blockchain in batches, and signatures from last commits of the next blocks through /block_search.
+// Only heights whose signatures couldn't be found that way are fetched one by one from /commit.
+// Every request is bounded by the agent's adaptive limiter, which backs off when the rpc throttles.
+func (r *MonitorClient) GetCommitsOfHeights(ctx context.Context, heights []uint64) []CometBFTCommitResult {
+	if len(heights) == 0 {
+		return nil
+	}
+
+	var (
+		batches = batchHeights(heights, commitBatchSize)
+		headers = r.getHeaders(ctx, batches)
+		commits = r.searchLastCommits(ctx, batches)
+		results []CometBFTCommitResult
+		missing []uint64
+	)
+	for _, height := range heights {
+		header, hasHeader := headers[height]
+		commit, hasCommit := commits[height]
+		if !hasHeader || !hasCommit {
+			missing = append(missing, height)
+			continue
+		}
+		results = append(results, CometBFTCommitResult{
+			Result:   ResultCommit{SignedHeader: SignedHeader{Header: &header.header, Commit: commit}, CanonicalCommit: true},
+			Endpoint: header.endpoint,
+		})
+	}
+
+	if ctx.Err() != nil {
+		return results
+	}
+	if len(missing) > 0 {
+		log.Debug(fmt.Sprintf("[block_commit] fetching %d of %d commits one by one", len(missing), len(heights)))
+	}
+	return append(results, r.getCommitsOneByOne(ctx, missing)...)
+}
+
+func (r *MonitorClient) getHeaders(ctx context.Context, batches []heightRange) map[uint64]headerResult {
+	var (
+		mu      sync.Mutex
+		headers = make(map[uint64]headerResult)
+	)
+	r.runLimited(ctx, len(batches), func(i int) error {
+		blockchain, err := r.GetBlockchain(ctx, batches[i].min, batches[i].max)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error fetching headers of [%d, %d]: %v", batches[i].min, batches[i].max, err))
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, meta := range blockchain.Result.BlockMetas {
+			height, err := strconv.ParseUint(meta.Header.Height, 0, 64)
+			if err != nil {
+				return err
+			}
+			headers[height] = headerResult{header: meta.Header, endpoint: blockchain.Endpoint}
+		}
+		return nil
+	})
+	return headers
+}
+
+// searchLastCommits returns commits of the heights, which are the last commits of the next blocks.
+func (r *MonitorClient) searchLastCommits(ctx context.Context, batches []heightRange) map[uint64]*Commit {
+	var (
+		mu      sync.Mutex
+		commits = make(map[uint64]*Commit)
+	)
+	if time.Now().UnixNano() < r.blockSearchDisabledUntil.Load() {
+		return commits
+	}
+
+	r.runLimited(ctx, len(batches), func(i int) error {
+		if time.Now().UnixNano() < r.blockSearchDisabledUntil.Load() {
+			return nil
+		}
+
+		search, err := r.SearchBlocks(ctx, batches[i].min+1, batches[i].max+1, 1, commitBatchSize)
+		if err != nil {
+			if ctx.Err() == nil && r.blockSearchDisabledUntil.Swap(time.Now().Add(blockSearchRetryInterval).UnixNano()) < time.Now().UnixNano() {
+				log.Info(fmt.Sprintf("[block_commit] /block_search is unavailable, commits will be fetched one by one for %v. err: %v", blockSearchRetryInterval, err))
+			}
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, block := range search.Result.Blocks {
+			height, err := strconv.ParseUint(block.Block.Header.Height, 0, 64)
+			if err != nil {
+				return err
+			}
+			if block.Block.LastCommit == nil || height == 0 {
+				continue
+			}
+			commits[height-1] = block.Block.LastCommit
+		}
+		return nil
+	})
+	return commits
+}
+
+func (r *MonitorClient) getCommitsOneByOne(ctx context.Context, heights []uint64) []CometBFTCommitResult {
+	var (
+		mu      sync.Mutex
+		results []CometBFTCommitResult
+	)
+	r.runLimited(ctx, len(heights), func(i int) error {
+		commit, err := r.GetCommitWithHeight(ctx, heights[i])
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error(errors.New(fmt.Sprintf("Error fetching commit: %v", err)))
+			}
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, *commit)
+		return nil
+	})
+	return results
+}
+
+// runLimited calls fn with 0 to n-1 concurrently as the limiter allows, and waits for them.
+// It stops calling fn once ctx is done.
+func (r *MonitorClient) runLimited(ctx context.Context, n int, fn func(i int) error) {
+	limiter := r.limiter
+	if limiter == nil {
+		limiter = newAdaptiveLimiter(1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		if limiter.acquire(ctx) != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := fn(i)
+			limiter.release(err)
+		}(i)
+	}
+	wg.Wait()
+}
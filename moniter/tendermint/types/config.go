@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
+	"gopkg.in/yaml.v3"
 	"os"
 	"strconv"
 	"strings"
@@ -11,19 +13,104 @@ import (
 )
 
 type MonitorConfig struct {
-	Agent       MonitoringAgent `yaml:"agent"`
-	DbBatchSize int             `yaml:"dbBatchSize"`
+	Agent MonitoringAgent `yaml:"agent"`
+	// Agents are the nodes monitored by this process. They share the http client and the database pool.
+	// When it's empty, Agent is the only one.
+	Agents      []MonitoringAgent `yaml:"agents"`
+	DbBatchSize int               `yaml:"dbBatchSize"`
+	// DrainTimeout bounds how long monitors may take to flush pending records on shutdown.
+	DrainTimeout *time.Duration `yaml:"drainTimeout"`
+	// Wal buffers writes which failed to reach the database, and replays them once it's back.
+	Wal *WalConfig `yaml:"wal"`
+	// Health serves /healthz, /readyz and /metrics of the monitor itself.
+	Health *HealthConfig `yaml:"health"`
+	// Sink pushes records to a collector instead of writing them into the database, so the monitor needs no database credentials.
+	Sink *SinkConfig `yaml:"sink"`
+	// Collector is the config of `collect` command, which writes records pushed by monitors in sink mode.
+	Collector *CollectorConfig `yaml:"collector"`
+}
+
+type SinkConfig struct {
+	// Address of the collector. (e.g. `https://collector:8443`) Sink mode is disabled when it's empty.
+	Address string `yaml:"address"`
+	Token   string `yaml:"token"`
+	// CAFile is an additional CA to trust, e.g. of a self-signed collector.
+	CAFile  string         `yaml:"caFile"`
+	Timeout *time.Duration `yaml:"timeout"`
+	// Retries of a push failing temporarily. Records which still fail are buffered in the wal.
+	Retries   *int `yaml:"retries"`
+	BatchSize int  `yaml:"batchSize"`
+}
+
+type CollectorConfig struct {
+	// Address to listen on. (e.g. `:8443`)
+	Address     string `yaml:"address"`
+	TLSCertFile string `yaml:"tlsCertFile"`
+	TLSKeyFile  string `yaml:"tlsKeyFile"`
+	// Tokens authenticate monitors. Each one may submit records of its agents only, or of every agent with `*`.
+	Tokens       []collector.Token `yaml:"tokens"`
+	MaxBodyBytes int64             `yaml:"maxBodyBytes"`
+}
+
+type HealthConfig struct {
+	// Address to listen on. (e.g. `:8080`) The endpoint is disabled when it's empty.
+	Address string `yaml:"address"`
+}
+
+type WalConfig struct {
+	Disabled bool `yaml:"disabled"`
+	// Dir is where the queue is stored. Mount a volume on it to keep buffered writes over container restarts.
+	Dir string `yaml:"dir"`
+	// MaxBytes limits the size of buffered writes. Writes are dropped once it's exceeded.
+	MaxBytes       int64          `yaml:"maxBytes"`
+	ReplayInterval *time.Duration `yaml:"replayInterval"`
 }
 
 type MonitoringAgent struct {
-	AgentName                 string         `yaml:"name"`
-	Host                      string         `yaml:"host"`
-	Port                      int            `yaml:"port"`
-	Monitors                  []Func         `yaml:"monitors"`
+	AgentName string `yaml:"name"`
+	Host      string `yaml:"host"`
+	Port      int    `yaml:"port"`
+	// Endpoints lists the node's rpc listeners in order of priority.
+	// When it's empty, Host and Port will be used as a single endpoint.
+	Endpoints                 []Endpoint     `yaml:"endpoints"`
+	Monitors                  []MonitorSpec  `yaml:"monitors"`
 	PushInterval              *time.Duration `yaml:"pushInterval"`
-	BlockCommitMaxConcurrency int            `yaml:"blockCommitMaxConcurrency"`
-	Timeout                   *time.Duration `yaml:"timeout"`
-	CommitId                  string         `yaml:"commitId"`
+	Jitter                    *time.Duration `yaml:"jitter"`
+	BlockCommitMaxConcurrency int            `yaml:"blockCommitMaxConcurrency"` // Upper bound of the adaptive concurrency fetching commits.
+	// BlockCommitMode is how block_commit monitor gets new blocks. `poll` or `websocket`.
+	BlockCommitMode string         `yaml:"blockCommitMode"`
+	Timeout         *time.Duration `yaml:"timeout"`
+	CommitId        string         `yaml:"commitId"`
+	// Platform and Location describe where the node runs. (e.g. `aws`, `ap-northeast-2`)
+	// They're registered into `agent` table along with the primary endpoint.
+	Platform string `yaml:"platform"`
+	Location string `yaml:"location"`
+}
+
+// movedAgentKeys are keys of agent which have moved into `config` of the monitors using them.
+var movedAgentKeys = map[string]string{
+	"validatorAddress":   "consensus_state",
+	"dumpConsensusState": "consensus_state",
+	"prometheus":         "prometheus",
+	"hostResource":       "host_resource",
+	"cosmos":             "cosmos_validator, gov and upgrade",
+}
+
+// UnmarshalYAML warns about moved keys of agent, which would be ignored otherwise.
+func (a *MonitoringAgent) UnmarshalYAML(value *yaml.Node) error {
+	type plain MonitoringAgent
+	err := value.Decode((*plain)(a))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i].Value
+		if monitors, moved := movedAgentKeys[key]; moved {
+			log.Warn(fmt.Sprintf("agent(%s).%s is ignored. it has moved into `config` of %s monitor", a.AgentName, key, monitors))
+		}
+	}
+	return nil
 }
 
 var (
@@ -31,10 +118,44 @@ var (
 	EnvAgentName                 = "AGENT_NAME"
 	EnvAgentHost                 = "AGENT_HOST"
 	EnvAgentPort                 = "AGENT_PORT"
+	EnvAgentEndpoints            = "AGENT_ENDPOINTS"
+	EnvAgentPlatform             = "AGENT_PLATFORM"
+	EnvAgentLocation             = "AGENT_LOCATION"
 	EnvPushInterval              = "PUSH_INTERVAL"
+	EnvJitter                    = "JITTER"
 	EnvBlockCommitMaxConcurrency = "BLOCK_COMMIT_MAX_CONCURRENCY"
+	EnvBlockCommitMode           = "BLOCK_COMMIT_MODE"
 	EnvMonitors                  = "AGENT_MONITORS"
 	EnvCommitId                  = "COMMIT_ID"
+	EnvDrainTimeout              = "DRAIN_TIMEOUT"
+	EnvValidatorAddress          = "VALIDATOR_ADDRESS"
+	EnvDumpConsensusState        = "DUMP_CONSENSUS_STATE"
+	EnvPrometheusAddress         = "PROMETHEUS_ADDRESS"
+	EnvPrometheusMetrics         = "PROMETHEUS_METRICS"
+	EnvHostProcPath              = "HOST_PROC_PATH"
+	EnvHostDiskPaths             = "HOST_DISK_PATHS"
+	EnvCosmosProtocol            = "COSMOS_PROTOCOL"
+	EnvCosmosAddress             = "COSMOS_ADDRESS"
+	EnvValoperAddress            = "VALOPER_ADDRESS"
+	EnvWalDisabled               = "WAL_DISABLED"
+	EnvWalDir                    = "WAL_DIR"
+	EnvWalMaxBytes               = "WAL_MAX_BYTES"
+	EnvWalReplayInterval         = "WAL_REPLAY_INTERVAL"
+	EnvHealthAddress             = "HEALTH_ADDRESS"
+	EnvSinkAddress               = "SINK_ADDRESS"
+	EnvSinkToken                 = "SINK_TOKEN"
+	EnvSinkCAFile                = "SINK_CA_FILE"
+	EnvCollectorAddress          = "COLLECTOR_ADDRESS"
+	EnvRPCScheme                 = "RPC_SCHEME"
+	EnvRPCBearerToken            = "RPC_BEARER_TOKEN"
+	EnvRPCBearerTokenFile        = "RPC_BEARER_TOKEN_FILE"
+	EnvRPCUsername               = "RPC_USERNAME"
+	EnvRPCPassword               = "RPC_PASSWORD"
+	EnvRPCCAFile                 = "RPC_CA_FILE"
+	EnvRPCCertFile               = "RPC_CERT_FILE"
+	EnvRPCKeyFile                = "RPC_KEY_FILE"
+	EnvGeoIPCityDatabase         = "GEOIP_CITY_DATABASE"
+	EnvGeoIPASNDatabase          = "GEOIP_ASN_DATABASE"
 
 	EnvConfigFilePath = "CONFIG_FILE_PATH"
 )
@@ -45,167 +166,515 @@ var (
 	DefaultAgentHost                 = "127.0.0.1"
 	DefaultAgentPort                 = 26657
 	DefaultPushInterval              = 10 * time.Second
+	DefaultJitter                    = 1 * time.Second
 	DefaultBlockCommitMaxConcurrency = 100
+	DefaultBlockCommitMode           = BlockCommitModePoll
+	DefaultDrainTimeout              = 10 * time.Second
+	DefaultPrometheusPort            = 26660
+	DefaultHostProcPath              = "/proc"
+	DefaultCosmosProtocol            = CosmosProtocolRest
+	DefaultCosmosRestPort            = 1317
+	DefaultCosmosGrpcPort            = 9090
+	DefaultPrometheusMetrics         = []string{
+		"consensus_height",
+		"consensus_rounds",
+		"consensus_validator_missed_blocks",
+		"consensus_missing_validators",
+		"consensus_block_interval_seconds_sum",
+		"consensus_block_interval_seconds_count",
+		"mempool_size",
+		"p2p_peers",
+	}
+
+	DefaultWalDir                  = "wal"
+	DefaultWalMaxBytes       int64 = 256 * 1024 * 1024
+	DefaultWalReplayInterval       = 10 * time.Second
+
+	DefaultSinkTimeout      = 10 * time.Second
+	DefaultSinkRetries      = 3
+	DefaultSinkBatchSize    = 100
+	DefaultCollectorAddress = ":8443"
 )
 
-var MonitorRegistry map[string]Func
+const (
+	// BlockCommitModePoll fetches /commit for every new height on each push interval.
+	BlockCommitModePoll = "poll"
+	// BlockCommitModeWebsocket subscribes NewBlock events through /websocket.
+	BlockCommitModeWebsocket = "websocket"
+)
 
-func (f *Func) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	// Temporary structure to unmarshal the YAML into
-	var tmp struct {
-		Name     string         `yaml:"name"`
-		Interval *time.Duration `yaml:"interval"`
+// MonitoringAgents returns every agent monitored by this process.
+func (cfg *MonitorConfig) MonitoringAgents() []MonitoringAgent {
+	if len(cfg.Agents) == 0 {
+		return []MonitoringAgent{cfg.Agent}
 	}
+	return cfg.Agents
+}
 
-	// Unmarshal into the temporary struct
-	if err := unmarshal(&tmp); err != nil {
-		return err
+// ForAgent returns the config seen by the agent's monitors, whose Agent is the given agent.
+func (cfg *MonitorConfig) ForAgent(agent MonitoringAgent) *MonitorConfig {
+	agentConfig := *cfg
+	agentConfig.Agent = agent
+	agentConfig.Agents = nil
+	return &agentConfig
+}
+
+// ApplyConfigFromEnvAndDefault will read the environmental variables into a config
+// then validate it is reasonable and if there are not set in any column, set as defaults.
+func (cfg *MonitorConfig) ApplyConfigFromEnvAndDefault() error {
+
+	if len(cfg.Agents) == 0 {
+		err := cfg.Agent.ApplyConfigFromEnvAndDefault()
+		if err != nil {
+			return err
+		}
+	} else {
+		agentNames := make(map[string]bool)
+		for i := range cfg.Agents {
+			agent := &cfg.Agents[i]
+			// Every agent would get the same name from env, so it must be set explicitly.
+			if agent.AgentName == "" {
+				return fmt.Errorf("name of agents[%d] is empty", i)
+			}
+			if agentNames[agent.AgentName] {
+				return errors.New("duplicated agent name: " + agent.AgentName)
+			}
+			agentNames[agent.AgentName] = true
+
+			err := agent.ApplyConfigFromEnvAndDefault()
+			if err != nil {
+				return fmt.Errorf("agent(%s): %w", agent.AgentName, err)
+			}
+		}
 	}
 
-	// Look up the MonitorFunc based on the name
-	monitor, exists := MonitorRegistry[tmp.Name]
-	if !exists {
-		return fmt.Errorf("unknown monitor: %s", tmp.Name)
+	if cfg.DrainTimeout == nil {
+		v := os.Getenv(EnvDrainTimeout)
+		if v == "" {
+			cfg.DrainTimeout = &DefaultDrainTimeout
+			log.Debug("drainTimeout set as default: " + cfg.DrainTimeout.String())
+		} else {
+			drainTimeout, err := parseEnvDuration(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.DrainTimeout = &drainTimeout
+			log.Debug("drainTimeout set as ENV: " + cfg.DrainTimeout.String())
+		}
+	} else {
+		log.Debug("drainTimeout set as " + cfg.DrainTimeout.String())
 	}
 
-	// Assign the found MonitorFunc and Interval to the Func struct
-	f.MonitorFunc = monitor.MonitorFunc
-	f.Interval = tmp.Interval
+	if cfg.Wal == nil {
+		cfg.Wal = &WalConfig{}
+	}
+	if !cfg.Wal.Disabled {
+		v := os.Getenv(EnvWalDisabled)
+		if v != "" {
+			disabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("could not parse '%s' into a bool: %w", v, err)
+			}
+			cfg.Wal.Disabled = disabled
+			log.Debug("wal disabled set as ENV: " + strconv.FormatBool(cfg.Wal.Disabled))
+		}
+	}
+	if cfg.Wal.Dir == "" {
+		v := os.Getenv(EnvWalDir)
+		if v == "" {
+			cfg.Wal.Dir = DefaultWalDir
+			log.Debug("wal dir set as default: " + cfg.Wal.Dir)
+		} else {
+			cfg.Wal.Dir = v
+			log.Debug("wal dir set as ENV: " + cfg.Wal.Dir)
+		}
+	} else {
+		log.Debug("wal dir set as " + cfg.Wal.Dir)
+	}
+	if cfg.Wal.MaxBytes == 0 {
+		v := os.Getenv(EnvWalMaxBytes)
+		if v == "" {
+			cfg.Wal.MaxBytes = DefaultWalMaxBytes
+			log.Debug("wal maxBytes set as default: " + strconv.FormatInt(cfg.Wal.MaxBytes, 10))
+		} else {
+			maxBytes, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.Wal.MaxBytes = maxBytes
+			log.Debug("wal maxBytes set as ENV: " + strconv.FormatInt(cfg.Wal.MaxBytes, 10))
+		}
+	} else {
+		log.Debug("wal maxBytes set as " + strconv.FormatInt(cfg.Wal.MaxBytes, 10))
+	}
+	if cfg.Wal.ReplayInterval == nil {
+		v := os.Getenv(EnvWalReplayInterval)
+		if v == "" {
+			cfg.Wal.ReplayInterval = &DefaultWalReplayInterval
+			log.Debug("wal replayInterval set as default: " + cfg.Wal.ReplayInterval.String())
+		} else {
+			replayInterval, err := parseEnvDuration(v)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			cfg.Wal.ReplayInterval = &replayInterval
+			log.Debug("wal replayInterval set as ENV: " + cfg.Wal.ReplayInterval.String())
+		}
+	} else {
+		log.Debug("wal replayInterval set as " + cfg.Wal.ReplayInterval.String())
+	}
+
+	if cfg.Health == nil {
+		cfg.Health = &HealthConfig{}
+	}
+	if cfg.Health.Address == "" {
+		cfg.Health.Address = os.Getenv(EnvHealthAddress)
+		log.Debug("health address set as ENV: " + cfg.Health.Address)
+	} else {
+		log.Debug("health address set as " + cfg.Health.Address)
+	}
+
+	err := cfg.applySinkConfigFromEnvAndDefault()
+	if err != nil {
+		return err
+	}
+	cfg.applyCollectorConfigFromEnvAndDefault()
 
 	return nil
 }
 
-// ApplyConfigFromEnvAndDefault will read the environmental variables into a config
-// then validate it is reasonable and if there are not set in any column, set as defaults.
-func (cfg *MonitorConfig) ApplyConfigFromEnvAndDefault() error {
+func (cfg *MonitorConfig) applySinkConfigFromEnvAndDefault() error {
+	if cfg.Sink == nil {
+		cfg.Sink = &SinkConfig{}
+	}
+	if cfg.Sink.Address == "" {
+		cfg.Sink.Address = os.Getenv(EnvSinkAddress)
+		log.Debug("sink address set as ENV: " + cfg.Sink.Address)
+	} else {
+		log.Debug("sink address set as " + cfg.Sink.Address)
+	}
+	if cfg.Sink.Address == "" {
+		return nil
+	}
+
+	if cfg.Sink.Token == "" {
+		cfg.Sink.Token = os.Getenv(EnvSinkToken)
+	}
+	if cfg.Sink.Token == "" {
+		return errors.New("sink token is required. please set it through config.yaml or env($" + EnvSinkToken + ")")
+	}
+	if cfg.Sink.CAFile == "" {
+		cfg.Sink.CAFile = os.Getenv(EnvSinkCAFile)
+	}
+	if cfg.Sink.Timeout == nil {
+		cfg.Sink.Timeout = &DefaultSinkTimeout
+		log.Debug("sink timeout set as default: " + cfg.Sink.Timeout.String())
+	}
+	if cfg.Sink.Retries == nil {
+		cfg.Sink.Retries = &DefaultSinkRetries
+		log.Debug("sink retries set as default: " + strconv.Itoa(*cfg.Sink.Retries))
+	}
+	if cfg.Sink.BatchSize == 0 {
+		cfg.Sink.BatchSize = DefaultSinkBatchSize
+		log.Debug("sink batchSize set as default: " + strconv.Itoa(cfg.Sink.BatchSize))
+	}
+	return nil
+}
 
-	if cfg.Agent.Timeout == nil {
+func (cfg *MonitorConfig) applyCollectorConfigFromEnvAndDefault() {
+	if cfg.Collector == nil {
+		cfg.Collector = &CollectorConfig{}
+	}
+	if cfg.Collector.Address == "" {
+		v := os.Getenv(EnvCollectorAddress)
+		if v == "" {
+			cfg.Collector.Address = DefaultCollectorAddress
+			log.Debug("collector address set as default: " + cfg.Collector.Address)
+		} else {
+			cfg.Collector.Address = v
+			log.Debug("collector address set as ENV: " + cfg.Collector.Address)
+		}
+	} else {
+		log.Debug("collector address set as " + cfg.Collector.Address)
+	}
+	if cfg.Collector.MaxBodyBytes == 0 {
+		cfg.Collector.MaxBodyBytes = collector.DefaultMaxBodyBytes
+	}
+}
+
+// ApplyConfigFromEnvAndDefault applies the environmental variables and defaults to the agent.
+// With multiple agents, the environmental variables are shared defaults of every agent.
+func (agent *MonitoringAgent) ApplyConfigFromEnvAndDefault() error {
+
+	if agent.Timeout == nil {
 		v := os.Getenv(EnvTimeout)
 		if v == "" {
-			cfg.Agent.Timeout = &DefaultTimeout
-			log.Debug("timeout set as default: " + cfg.Agent.Timeout.String())
+			agent.Timeout = &DefaultTimeout
+			log.Debug("timeout set as default: " + agent.Timeout.String())
 		} else {
 			timeout, err := parseEnvDuration(v)
 			if err != nil {
 				return errors.New(err.Error())
 			}
-			cfg.Agent.Timeout = &timeout
-			log.Debug("timeout set as ENV: " + cfg.Agent.Timeout.String())
+			agent.Timeout = &timeout
+			log.Debug("timeout set as ENV: " + agent.Timeout.String())
 		}
 	} else {
-		log.Debug("timeout set as " + cfg.Agent.Timeout.String())
+		log.Debug("timeout set as " + agent.Timeout.String())
 	}
 
-	if cfg.Agent.PushInterval == nil {
+	if agent.PushInterval == nil {
 		v := os.Getenv(EnvPushInterval)
 		if v == "" {
-			cfg.Agent.PushInterval = &DefaultPushInterval
-			log.Debug("pushInterval set as default: " + cfg.Agent.PushInterval.String())
+			agent.PushInterval = &DefaultPushInterval
+			log.Debug("pushInterval set as default: " + agent.PushInterval.String())
 		} else {
 			interval, err := parseEnvDuration(v)
 			if err != nil {
 				return errors.New(err.Error())
 			}
-			cfg.Agent.PushInterval = &interval
-			log.Debug("pushInterval set as ENV: " + cfg.Agent.PushInterval.String())
+			agent.PushInterval = &interval
+			log.Debug("pushInterval set as ENV: " + agent.PushInterval.String())
 		}
 	} else {
-		log.Debug("pushInterval set as " + cfg.Agent.PushInterval.String())
+		log.Debug("pushInterval set as " + agent.PushInterval.String())
 	}
 
-	if cfg.Agent.BlockCommitMaxConcurrency == 0 {
+	if agent.Jitter == nil {
+		v := os.Getenv(EnvJitter)
+		if v == "" {
+			agent.Jitter = &DefaultJitter
+			log.Debug("jitter set as default: " + agent.Jitter.String())
+		} else {
+			jitter, err := time.ParseDuration(v)
+			if err != nil || jitter < 0 {
+				return fmt.Errorf("could not parse '%s' into a non-negative duration", v)
+			}
+			agent.Jitter = &jitter
+			log.Debug("jitter set as ENV: " + agent.Jitter.String())
+		}
+	} else {
+		log.Debug("jitter set as " + agent.Jitter.String())
+	}
+
+	if agent.BlockCommitMaxConcurrency == 0 {
 		v := os.Getenv(EnvBlockCommitMaxConcurrency)
 		if v == "" {
-			cfg.Agent.BlockCommitMaxConcurrency = DefaultBlockCommitMaxConcurrency
-			log.Debug("pushInterval set as default: " + cfg.Agent.PushInterval.String())
+			agent.BlockCommitMaxConcurrency = DefaultBlockCommitMaxConcurrency
+			log.Debug("pushInterval set as default: " + agent.PushInterval.String())
 		} else {
 			concurrency, err := strconv.Atoi(v)
 			if err != nil {
 				log.Error(errors.New("error occurred while parsing blockCommitMaxConcurrency " + err.Error()))
 				concurrency = DefaultBlockCommitMaxConcurrency
 			}
-			cfg.Agent.BlockCommitMaxConcurrency = concurrency
-			log.Debug("blockCommitMaxConcurrency set as ENV: " + strconv.Itoa(cfg.Agent.BlockCommitMaxConcurrency))
+			agent.BlockCommitMaxConcurrency = concurrency
+			log.Debug("blockCommitMaxConcurrency set as ENV: " + strconv.Itoa(agent.BlockCommitMaxConcurrency))
 		}
 
 	}
 
-	if cfg.Agent.AgentName == "" {
+	if agent.BlockCommitMode == "" {
+		v := os.Getenv(EnvBlockCommitMode)
+		if v == "" {
+			agent.BlockCommitMode = DefaultBlockCommitMode
+			log.Debug("blockCommitMode set as default: " + agent.BlockCommitMode)
+		} else {
+			agent.BlockCommitMode = v
+			log.Debug("blockCommitMode set as ENV: " + agent.BlockCommitMode)
+		}
+	} else {
+		log.Debug("blockCommitMode set as " + agent.BlockCommitMode)
+	}
+	if agent.BlockCommitMode != BlockCommitModePoll && agent.BlockCommitMode != BlockCommitModeWebsocket {
+		return fmt.Errorf("unknown blockCommitMode: %s. it should be `%s` or `%s`", agent.BlockCommitMode, BlockCommitModePoll, BlockCommitModeWebsocket)
+	}
+
+	if agent.AgentName == "" {
 		v := os.Getenv(EnvAgentName)
 		if v == "" {
 			log.Warn(errors.New("Could not found agent(node)'s agentName. it'll be set as `instance` temporarily. \n" +
 				"You should set node's agentName as fast as possible. it may cause confusion.").Error())
-			cfg.Agent.AgentName = DefaultAgentName
+			agent.AgentName = DefaultAgentName
 		} else {
-			cfg.Agent.AgentName = v
-			log.Debug("agentName set as ENV: " + cfg.Agent.AgentName)
+			agent.AgentName = v
+			log.Debug("agentName set as ENV: " + agent.AgentName)
 		}
 	} else {
-		log.Debug("agentName set as " + cfg.Agent.AgentName)
+		log.Debug("agentName set as " + agent.AgentName)
+	}
+
+	if len(agent.Endpoints) == 0 {
+		v := os.Getenv(EnvAgentEndpoints)
+		if v != "" {
+			endpoints, err := parseEnvEndpoints(v)
+			if err != nil {
+				return err
+			}
+			agent.Endpoints = endpoints
+			log.Debug("endpoints set as ENV: " + v)
+		}
+	}
+
+	// Primary endpoint represents the agent's host when host isn't set explicitly.
+	if len(agent.Endpoints) > 0 && agent.Host == "" && os.Getenv(EnvAgentHost) == "" {
+		agent.Host = agent.Endpoints[0].Host
+		if agent.Port == 0 {
+			agent.Port = agent.Endpoints[0].Port
+		}
 	}
 
-	if cfg.Agent.Host == "" {
+	if agent.Host == "" {
 		v := os.Getenv(EnvAgentHost)
 		if v == "" {
-			cfg.Agent.Host = DefaultAgentHost
-			log.Debug("host set as default: " + cfg.Agent.Host)
+			agent.Host = DefaultAgentHost
+			log.Debug("host set as default: " + agent.Host)
 		} else {
-			cfg.Agent.Host = v
-			log.Debug("host set as ENV: " + cfg.Agent.Host)
+			agent.Host = v
+			log.Debug("host set as ENV: " + agent.Host)
 		}
 	} else {
-		log.Debug("host set as " + cfg.Agent.Host)
+		log.Debug("host set as " + agent.Host)
 	}
 
-	if cfg.Agent.Port == 0 {
+	if agent.Port == 0 {
 		v := os.Getenv(EnvAgentPort)
 		if v == "" {
-			cfg.Agent.Port = DefaultAgentPort
-			log.Debug("port set as " + strconv.Itoa(cfg.Agent.Port))
+			agent.Port = DefaultAgentPort
+			log.Debug("port set as " + strconv.Itoa(agent.Port))
 		} else {
 			port, err := strconv.Atoi(v)
 			if err != nil {
 				return errors.New(err.Error())
 			}
-			cfg.Agent.Port = port
-			log.Debug("port set as ENV" + strconv.Itoa(cfg.Agent.Port))
+			agent.Port = port
+			log.Debug("port set as ENV" + strconv.Itoa(agent.Port))
 		}
 	} else {
-		log.Debug("port set as " + strconv.Itoa(cfg.Agent.Port))
+		log.Debug("port set as " + strconv.Itoa(agent.Port))
 	}
 
-	if len(cfg.Agent.Monitors) == 0 {
+	if len(agent.Endpoints) == 0 {
+		agent.Endpoints = []Endpoint{{Host: agent.Host, Port: agent.Port}}
+	}
+	for i, endpoint := range agent.Endpoints {
+		if endpoint.Host == "" {
+			return fmt.Errorf("host of endpoint[%d] is empty", i)
+		}
+		if endpoint.Port == 0 {
+			agent.Endpoints[i].Port = DefaultAgentPort
+		}
+		applyEndpointConfigFromEnv(&agent.Endpoints[i])
+		if err := validateEndpoint(agent.Endpoints[i]); err != nil {
+			return fmt.Errorf("endpoint %s: %w", endpoint.String(), err)
+		}
+	}
+	// Endpoints are logged by address only, since their headers may hold credentials.
+	log.Debug(fmt.Sprintf("endpoints set as %v", endpointAddresses(agent.Endpoints)))
+
+	if len(agent.Monitors) == 0 {
 		v := os.Getenv(EnvMonitors)
 		if v == "" {
-			for _, monFunc := range MonitorRegistry {
-				cfg.Agent.Monitors = append(cfg.Agent.Monitors, monFunc)
+			names := DefaultMonitors()
+			for _, name := range names {
+				agent.Monitors = append(agent.Monitors, MonitorSpec{Name: name})
 			}
+			log.Debug("monitors set as default: " + strings.Join(names, ","))
 		} else {
 			for _, name := range strings.Split(v, ",") {
-				monitorFunc, exists := MonitorRegistry[name]
-				if !exists {
-					return errors.New("unknown service: " + name)
+				spec := MonitorSpec{Name: name}
+				if _, err := NewMonitor(spec); err != nil {
+					return err
 				}
-				cfg.Agent.Monitors = append(cfg.Agent.Monitors, monitorFunc)
+				agent.Monitors = append(agent.Monitors, spec)
 			}
 			log.Debug("monitors set as " + v)
 		}
 	}
 
-	if cfg.Agent.CommitId == "" {
+	if agent.Platform == "" {
+		agent.Platform = os.Getenv(EnvAgentPlatform)
+		log.Debug("platform set as ENV: " + agent.Platform)
+	}
+
+	if agent.Location == "" {
+		agent.Location = os.Getenv(EnvAgentLocation)
+		log.Debug("location set as ENV: " + agent.Location)
+	}
+
+	if agent.CommitId == "" {
 		v := os.Getenv(EnvCommitId)
 		if v == "" {
 			return errors.New("No commit id found. please set commit id through config.yaml or env($COMMIT_ID)")
 		}
-		cfg.Agent.CommitId = v
-		log.Debug("CommitId set as ENV: " + cfg.Agent.CommitId)
+		agent.CommitId = v
+		log.Debug("CommitId set as ENV: " + agent.CommitId)
 	} else {
-		log.Debug("CommitId set as " + cfg.Agent.CommitId)
+		log.Debug("CommitId set as " + agent.CommitId)
 	}
 
 	return nil
 }
 
+// parseEnvEndpoints parses comma separated `host:port` list. (e.g. `10.0.0.1:26657,10.0.0.2:26657`)
+func endpointAddresses(endpoints []Endpoint) []string {
+	addresses := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		addresses[i] = endpoint.String()
+	}
+	return addresses
+}
+
+// applyEndpointConfigFromEnv applies the environmental variables to the scheme, auth and tls the endpoint doesn't set.
+// Credentials given as env are read when they're used, and the ones given as file are read again once they're modified.
+func applyEndpointConfigFromEnv(endpoint *Endpoint) {
+	if endpoint.Scheme == "" {
+		endpoint.Scheme = os.Getenv(EnvRPCScheme)
+	}
+
+	if endpoint.Auth == nil {
+		switch {
+		case os.Getenv(EnvRPCBearerTokenFile) != "":
+			endpoint.Auth = &EndpointAuth{BearerToken: &Secret{File: os.Getenv(EnvRPCBearerTokenFile)}}
+		case os.Getenv(EnvRPCBearerToken) != "":
+			endpoint.Auth = &EndpointAuth{BearerToken: &Secret{Env: EnvRPCBearerToken}}
+		case os.Getenv(EnvRPCUsername) != "":
+			endpoint.Auth = &EndpointAuth{Username: os.Getenv(EnvRPCUsername)}
+			if os.Getenv(EnvRPCPassword) != "" {
+				endpoint.Auth.Password = &Secret{Env: EnvRPCPassword}
+			}
+		}
+	}
+
+	if endpoint.TLS == nil {
+		tlsConfig := TLSConfig{
+			CAFile:   os.Getenv(EnvRPCCAFile),
+			CertFile: os.Getenv(EnvRPCCertFile),
+			KeyFile:  os.Getenv(EnvRPCKeyFile),
+		}
+		if tlsConfig != (TLSConfig{}) {
+			endpoint.TLS = &tlsConfig
+		}
+	}
+}
+
+func parseEnvEndpoints(input string) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	for _, hostWithPort := range strings.Split(input, ",") {
+		hostWithPort = strings.TrimSpace(hostWithPort)
+		idx := strings.LastIndex(hostWithPort, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("could not parse '%s' into an endpoint. it should be `host:port`", hostWithPort)
+		}
+		port, err := strconv.Atoi(hostWithPort[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse port of '%s': %w", hostWithPort, err)
+		}
+		endpoints = append(endpoints, Endpoint{Host: hostWithPort[:idx], Port: port})
+	}
+	return endpoints, nil
+}
+
 func parseEnvDuration(input string) (time.Duration, error) {
 	duration, err := time.ParseDuration(input)
 	if err != nil {
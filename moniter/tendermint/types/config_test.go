@@ -1,6 +1,7 @@
 package types
 
 import (
+	"github.com/b-harvest/Harvestmon/moniter/tendermint/collector"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 	"testing"
@@ -37,16 +38,88 @@ func Test(t *testing.T) {
 
 		ts := time.Second * 10
 		assert.Equal(t, MonitorConfig{
+			DrainTimeout: &DefaultDrainTimeout,
+			Wal: &WalConfig{
+				Dir:            DefaultWalDir,
+				MaxBytes:       DefaultWalMaxBytes,
+				ReplayInterval: &DefaultWalReplayInterval,
+			},
+			Health: &HealthConfig{},
+			Sink:   &SinkConfig{},
+			Collector: &CollectorConfig{
+				Address:      DefaultCollectorAddress,
+				MaxBodyBytes: collector.DefaultMaxBodyBytes,
+			},
 			Agent: MonitoringAgent{
-				AgentName:    "polkachu.com",
-				Host:         "cosmos-rpc.polkachu.com",
-				Port:         443,
-				PushInterval: &ts,
-				Timeout:      &ts,
-				CommitId:     "19ge4rgndfifji",
-				Monitors:     nil,
+				AgentName:                 "polkachu.com",
+				Host:                      "cosmos-rpc.polkachu.com",
+				Port:                      443,
+				Endpoints:                 []Endpoint{{Host: "cosmos-rpc.polkachu.com", Port: 443}},
+				PushInterval:              &ts,
+				Jitter:                    &DefaultJitter,
+				BlockCommitMaxConcurrency: DefaultBlockCommitMaxConcurrency,
+				BlockCommitMode:           DefaultBlockCommitMode,
+				Timeout:                   &ts,
+				CommitId:                  "19ge4rgndfifji",
+				Monitors:                  nil,
 			},
 		}, mConfig)
 	})
 
+	t.Run("multiple agents", func(t *testing.T) {
+		var mConfig MonitorConfig
+
+		configBytes := []byte(
+			"agents:\n" +
+				"  - name: \"node-a\"\n" +
+				"    host: \"10.0.0.1\"\n" +
+				"    commitId: 19ge4rgndfifji\n" +
+				"  - name: \"node-b\"\n" +
+				"    host: \"10.0.0.2\"\n" +
+				"    port: 36657\n" +
+				"    pushInterval: 30s\n" +
+				"    commitId: 19ge4rgndfifji\n")
+
+		err := yaml.Unmarshal(configBytes, &mConfig)
+		assert.NoError(t, err)
+
+		err = mConfig.ApplyConfigFromEnvAndDefault()
+		assert.NoError(t, err)
+
+		agents := mConfig.MonitoringAgents()
+		assert.Len(t, agents, 2)
+		assert.Equal(t, []Endpoint{{Host: "10.0.0.1", Port: DefaultAgentPort}}, agents[0].Endpoints)
+		assert.Equal(t, DefaultPushInterval, *agents[0].PushInterval)
+		assert.Equal(t, []Endpoint{{Host: "10.0.0.2", Port: 36657}}, agents[1].Endpoints)
+		assert.Equal(t, 30*time.Second, *agents[1].PushInterval)
+
+		agentConfig := mConfig.ForAgent(agents[1])
+		assert.Equal(t, "node-b", agentConfig.Agent.AgentName)
+		assert.Equal(t, mConfig.DrainTimeout, agentConfig.DrainTimeout)
+		assert.Nil(t, agentConfig.Agents)
+	})
+
+	t.Run("duplicated agent name", func(t *testing.T) {
+		mConfig := MonitorConfig{Agents: []MonitoringAgent{
+			{AgentName: "node-a", CommitId: "19ge4rgndfifji"},
+			{AgentName: "node-a", CommitId: "19ge4rgndfifji"},
+		}}
+		assert.Error(t, mConfig.ApplyConfigFromEnvAndDefault())
+
+		mConfig = MonitorConfig{Agents: []MonitoringAgent{{CommitId: "19ge4rgndfifji"}}}
+		assert.Error(t, mConfig.ApplyConfigFromEnvAndDefault())
+	})
+
+	t.Run("sink", func(t *testing.T) {
+		mConfig := MonitorConfig{Agent: MonitoringAgent{CommitId: "19ge4rgndfifji"}, Sink: &SinkConfig{Address: "https://collector:8443"}}
+		t.Setenv(EnvSinkToken, "")
+		assert.ErrorContains(t, mConfig.ApplyConfigFromEnvAndDefault(), "sink token is required")
+
+		t.Setenv(EnvSinkToken, "secret")
+		assert.NoError(t, mConfig.ApplyConfigFromEnvAndDefault())
+		assert.Equal(t, "secret", mConfig.Sink.Token)
+		assert.Equal(t, DefaultSinkRetries, *mConfig.Sink.Retries)
+		assert.Equal(t, DefaultSinkBatchSize, mConfig.Sink.BatchSize)
+	})
+
 }
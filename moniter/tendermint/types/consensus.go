@@ -0,0 +1,170 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CometBFTConsensusStateResult struct {
+	Result  ResultConsensusState `json:"result"`
+	ID      int64                `json:"id"`
+	Jsonrpc string               `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+type ResultConsensusState struct {
+	RoundState RoundStateSimple `json:"round_state"`
+}
+
+// RoundStateSimple is the summary of the node's consensus round state served by /consensus_state.
+type RoundStateSimple struct {
+	HeightRoundStep   string         `json:"height/round/step"`
+	StartTime         time.Time      `json:"start_time"`
+	ProposalBlockHash string         `json:"proposal_block_hash"`
+	LockedBlockHash   string         `json:"locked_block_hash"`
+	ValidBlockHash    string         `json:"valid_block_hash"`
+	HeightVoteSet     []RoundVoteSet `json:"height_vote_set"`
+}
+
+type CometBFTDumpConsensusStateResult struct {
+	Result  ResultDumpConsensusState `json:"result"`
+	ID      int64                    `json:"id"`
+	Jsonrpc string                   `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+// ResultDumpConsensusState is the result of /dump_consensus_state. Peers' states are not decoded.
+type ResultDumpConsensusState struct {
+	RoundState RoundState `json:"round_state"`
+}
+
+type RoundState struct {
+	Height     string    `json:"height"`
+	Round      int32     `json:"round"`
+	Step       int       `json:"step"`
+	StartTime  time.Time `json:"start_time"`
+	Validators struct {
+		Validators []Validator `json:"validators"`
+	} `json:"validators"`
+	Votes []RoundVoteSet `json:"votes"`
+}
+
+// RoundVoteSet is the votes received in a round.
+// Each vote is the string form of CometBFT's Vote. (e.g. `Vote{0:AB12CD34EF56 100/00/SIGNED_MSG_TYPE_PREVOTE(Prevote) ...}`)
+type RoundVoteSet struct {
+	Round              int32    `json:"round"`
+	Prevotes           []string `json:"prevotes"`
+	PrevotesBitArray   string   `json:"prevotes_bit_array"`
+	Precommits         []string `json:"precommits"`
+	PrecommitsBitArray string   `json:"precommits_bit_array"`
+}
+
+// Round steps of CometBFT consensus.
+const (
+	RoundStepNewHeight = iota + 1
+	RoundStepNewRound
+	RoundStepPropose
+	RoundStepPrevote
+	RoundStepPrevoteWait
+	RoundStepPrecommit
+	RoundStepPrecommitWait
+	RoundStepCommit
+)
+
+// ParseHeightRoundStep parses `height/round/step` of /consensus_state. (e.g. `100/0/3`)
+func ParseHeightRoundStep(heightRoundStep string) (uint64, int32, int, error) {
+	parts := strings.Split(heightRoundStep, "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("could not parse '%s' into height/round/step", heightRoundStep)
+	}
+
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not parse height of '%s': %w", heightRoundStep, err)
+	}
+	round, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not parse round of '%s': %w", heightRoundStep, err)
+	}
+	step, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not parse step of '%s': %w", heightRoundStep, err)
+	}
+
+	return height, int32(round), step, nil
+}
+
+// VoteSetOf returns the vote set of round. ok is false if no vote has been received for the round yet.
+func VoteSetOf(voteSets []RoundVoteSet, round int32) (RoundVoteSet, bool) {
+	for _, voteSet := range voteSets {
+		if voteSet.Round == round {
+			return voteSet, true
+		}
+	}
+	return RoundVoteSet{}, false
+}
+
+// HasVote reports whether votes contain the vote of the validator.
+// When validatorIndex is negative, the vote is matched by the fingerprint(first 6 bytes) of validatorAddress,
+// otherwise it's matched by the validator's index in the set.
+func HasVote(votes []string, validatorAddress string, validatorIndex int) bool {
+	fingerprint := strings.ToUpper(validatorAddress)
+	if len(fingerprint) > 12 {
+		fingerprint = fingerprint[:12]
+	}
+
+	for _, vote := range votes {
+		index, voteFingerprint, ok := parseVote(vote)
+		if !ok {
+			continue
+		}
+		if validatorIndex >= 0 {
+			if index == validatorIndex {
+				return true
+			}
+		} else if voteFingerprint == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVote parses the validator's index and address fingerprint from the string form of a vote.
+// A vote which hasn't been received is `nil-Vote`.
+func parseVote(vote string) (int, string, bool) {
+	if !strings.HasPrefix(vote, "Vote{") {
+		return 0, "", false
+	}
+	fields := strings.Fields(strings.TrimPrefix(vote, "Vote{"))
+	if len(fields) == 0 {
+		return 0, "", false
+	}
+	indexWithFingerprint := strings.SplitN(fields[0], ":", 2)
+	if len(indexWithFingerprint) != 2 {
+		return 0, "", false
+	}
+	index, err := strconv.Atoi(indexWithFingerprint[0])
+	if err != nil {
+		return 0, "", false
+	}
+	return index, strings.ToUpper(indexWithFingerprint[1]), true
+}
+
+// ParseVoteRatio parses the ratio of voting power which has voted from a vote bit array. (e.g. `BA{4:xx__} 20/40 = 0.50`)
+func ParseVoteRatio(bitArray string) float64 {
+	idx := strings.LastIndex(bitArray, "=")
+	if idx < 0 {
+		return 0
+	}
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(bitArray[idx+1:]), 64)
+	if err != nil {
+		return 0
+	}
+	return ratio
+}
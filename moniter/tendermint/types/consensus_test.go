@@ -0,0 +1,39 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConsensusState(t *testing.T) {
+
+	t.Run("parse height/round/step", func(t *testing.T) {
+		height, round, step, err := ParseHeightRoundStep("21534110/1/4")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(21534110), height)
+		assert.Equal(t, int32(1), round)
+		assert.Equal(t, RoundStepPrevote, step)
+
+		_, _, _, err = ParseHeightRoundStep("21534110/1")
+		assert.Error(t, err)
+	})
+
+	t.Run("find vote by fingerprint or index", func(t *testing.T) {
+		votes := []string{
+			"Vote{0:0A1B2C3D4E5F 21534110/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 8B01023386C3 6A1F4E2B7C9D 000000000000 @ 2024-09-01T00:00:00.000Z}",
+			"nil-Vote",
+			"Vote{2:FFEEDDCCBBAA 21534110/01/SIGNED_MSG_TYPE_PREVOTE(Prevote) 000000000000 1C2D3E4F5A6B 000000000000 @ 2024-09-01T00:00:00.000Z}",
+		}
+
+		assert.True(t, HasVote(votes, "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", -1))
+		assert.False(t, HasVote(votes, "1111111111111111111111111111111111111111", -1))
+		assert.True(t, HasVote(votes, "", 2))
+		assert.False(t, HasVote(votes, "", 1))
+	})
+
+	t.Run("parse vote ratio", func(t *testing.T) {
+		assert.Equal(t, 0.67, ParseVoteRatio("BA{4:xxx_} 67/100 = 0.67"))
+		assert.Equal(t, float64(0), ParseVoteRatio(""))
+	})
+
+}
@@ -0,0 +1,229 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"github.com/b-harvest/Harvestmon/util"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	CosmosProtocolRest = "rest"
+	CosmosProtocolGrpc = "grpc"
+
+	ed25519PubKeyType = "/cosmos.crypto.ed25519.PubKey"
+
+	proposalStatusVotingPeriod = "PROPOSAL_STATUS_VOTING_PERIOD"
+	// maxProposals is how many proposals in voting period are fetched at most.
+	maxProposals = 100
+)
+
+// CosmosConfig is the Cosmos SDK API of the node. It's the `config` block of the monitors querying it.
+//
+//	monitors:
+//	  - name: cosmos_validator
+//	    config:
+//	      protocol: grpc
+//	      address: "127.0.0.1:9090"
+//	      valoperAddress: "cosmosvaloper1..."
+type CosmosConfig struct {
+	// Protocol is `rest` or `grpc`.
+	Protocol string `yaml:"protocol"`
+	// Address of the API. (e.g. `http://127.0.0.1:1317` for rest, `127.0.0.1:9090` for grpc)
+	Address string `yaml:"address"`
+	// ValoperAddress is the operator address of our validator. (e.g. `cosmosvaloper1...`)
+	ValoperAddress string `yaml:"valoperAddress"`
+}
+
+// ApplyConfigFromEnvAndDefault fills the fields left unset from env, or defaults them to the API on host.
+func (c *CosmosConfig) ApplyConfigFromEnvAndDefault(host string) error {
+	if c.Protocol == "" {
+		v := os.Getenv(EnvCosmosProtocol)
+		if v == "" {
+			c.Protocol = DefaultCosmosProtocol
+			log.Debug("cosmos protocol set as default: " + c.Protocol)
+		} else {
+			c.Protocol = v
+			log.Debug("cosmos protocol set as ENV: " + c.Protocol)
+		}
+	} else {
+		log.Debug("cosmos protocol set as " + c.Protocol)
+	}
+	if c.Protocol != CosmosProtocolRest && c.Protocol != CosmosProtocolGrpc {
+		return fmt.Errorf("unknown cosmos protocol: %s. it should be `%s` or `%s`", c.Protocol, CosmosProtocolRest, CosmosProtocolGrpc)
+	}
+	if c.Address == "" {
+		v := os.Getenv(EnvCosmosAddress)
+		if v == "" {
+			if c.Protocol == CosmosProtocolGrpc {
+				c.Address = fmt.Sprintf("%s:%d", host, DefaultCosmosGrpcPort)
+			} else {
+				c.Address = fmt.Sprintf("http://%s:%d", host, DefaultCosmosRestPort)
+			}
+			log.Debug("cosmos address set as default: " + c.Address)
+		} else {
+			c.Address = v
+			log.Debug("cosmos address set as ENV: " + c.Address)
+		}
+	} else {
+		log.Debug("cosmos address set as " + c.Address)
+	}
+	if c.ValoperAddress == "" {
+		v := os.Getenv(EnvValoperAddress)
+		if v != "" {
+			c.ValoperAddress = v
+			log.Debug("valoperAddress set as ENV: " + c.ValoperAddress)
+		}
+	} else {
+		log.Debug("valoperAddress set as " + c.ValoperAddress)
+	}
+	return nil
+}
+
+// CosmosValidator is a validator of x/staking. Decimals are formatted like `0.050000000000000000`.
+type CosmosValidator struct {
+	OperatorAddress string
+	// ConsensusPubkeyType is type url of the consensus public key. (e.g. `/cosmos.crypto.ed25519.PubKey`)
+	ConsensusPubkeyType     string
+	ConsensusPubkey         []byte
+	Jailed                  bool
+	Status                  string
+	Tokens                  string
+	DelegatorShares         string
+	CommissionRate          string
+	CommissionMaxRate       string
+	CommissionMaxChangeRate string
+}
+
+// ConsensusAddress returns the bech32 consensus address of the validator. (e.g. `cosmosvalcons1...`)
+func (v CosmosValidator) ConsensusAddress() (string, error) {
+	if v.ConsensusPubkeyType != ed25519PubKeyType {
+		return "", errors.New("unsupported consensus pubkey type: " + v.ConsensusPubkeyType)
+	}
+
+	prefix, _, err := util.DecodeBech32(v.OperatorAddress)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(prefix, "valoper") {
+		return "", errors.New("not a valoper address: " + v.OperatorAddress)
+	}
+
+	hash := sha256.Sum256(v.ConsensusPubkey)
+	return util.EncodeBech32(strings.TrimSuffix(prefix, "valoper")+"valcons", hash[:20])
+}
+
+// AccountAddress returns the bech32 account address of the validator operator. (e.g. `cosmos1...`)
+func (c CosmosConfig) AccountAddress() (string, error) {
+	prefix, _, err := util.DecodeBech32(c.ValoperAddress)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(prefix, "valoper") {
+		return "", errors.New("not a valoper address: " + c.ValoperAddress)
+	}
+	return util.ConvertBech32Prefix(c.ValoperAddress, strings.TrimSuffix(prefix, "valoper"))
+}
+
+// CosmosSigningInfo is a validator's liveness of x/slashing.
+type CosmosSigningInfo struct {
+	Address             string
+	StartHeight         int64
+	IndexOffset         int64
+	JailedUntil         time.Time
+	Tombstoned          bool
+	MissedBlocksCounter int64
+}
+
+// CosmosSlashingParams is params of x/slashing. Decimals are formatted like `0.050000000000000000`.
+type CosmosSlashingParams struct {
+	SignedBlocksWindow      int64
+	MinSignedPerWindow      string
+	DowntimeJailDuration    time.Duration
+	SlashFractionDoubleSign string
+	SlashFractionDowntime   string
+}
+
+// CosmosProposal is a proposal of x/gov v1.
+type CosmosProposal struct {
+	ProposalId      uint64
+	Title           string
+	Status          string
+	VotingStartTime time.Time
+	VotingEndTime   time.Time
+}
+
+// CosmosVote is a voter's vote on a proposal. Options are more than one for a weighted vote.
+type CosmosVote struct {
+	ProposalId uint64
+	Voter      string
+	Options    []string
+}
+
+// CosmosUpgradePlan is a scheduled software upgrade of x/upgrade.
+type CosmosUpgradePlan struct {
+	Name   string
+	Height int64
+	Info   string
+}
+
+// CosmosQueryClient queries Cosmos SDK modules of the chain.
+type CosmosQueryClient interface {
+	GetValidator(ctx context.Context, valoperAddress string) (*CosmosValidator, error)
+	GetSigningInfo(ctx context.Context, consAddress string) (*CosmosSigningInfo, error)
+	GetSlashingParams(ctx context.Context) (*CosmosSlashingParams, error)
+	// GetVotingProposals returns proposals in voting period.
+	GetVotingProposals(ctx context.Context) ([]CosmosProposal, error)
+	// GetVote returns nil when the voter hasn't voted on the proposal.
+	GetVote(ctx context.Context, proposalId uint64, voter string) (*CosmosVote, error)
+	// GetCurrentPlan returns nil when no upgrade is scheduled.
+	GetCurrentPlan(ctx context.Context) (*CosmosUpgradePlan, error)
+	Close() error
+}
+
+func newCosmosQueryClient(cfg *CosmosConfig, httpClient HttpClient, timeout time.Duration) (CosmosQueryClient, error) {
+	switch cfg.Protocol {
+	case CosmosProtocolGrpc:
+		return newCosmosGrpcClient(cfg.Address, timeout)
+	default:
+		return &cosmosRestClient{address: strings.TrimSuffix(cfg.Address, "/"), httpClient: httpClient, timeout: timeout}, nil
+	}
+}
+
+// NewCosmosClient returns a client of the chain's Cosmos SDK API sharing the http client and the agent's timeout.
+// It should be closed by the monitor which made it.
+func (r *MonitorClient) NewCosmosClient(cfg *CosmosConfig) (CosmosQueryClient, error) {
+	return newCosmosQueryClient(cfg, r.httpClient, r.timeout)
+}
+
+// proposalTitle returns title of a proposal. Proposals before v0.47 don't have title, so metadata is used instead.
+func proposalTitle(title, metadata string) string {
+	const maxTitleLength = 255
+
+	if title == "" {
+		title = metadata
+	}
+	if len(title) > maxTitleLength {
+		title = strings.ToValidUTF8(title[:maxTitleLength], "")
+	}
+	return title
+}
+
+// formatLegacyDec formats a sdk.Dec on the wire(an integer of 18 decimal places) as a decimal.
+// (e.g. `50000000000000000` -> `0.050000000000000000`)
+func formatLegacyDec(s string) string {
+	const precision = 18
+
+	if s == "" {
+		return s
+	}
+	if len(s) <= precision {
+		s = strings.Repeat("0", precision+1-len(s)) + s
+	}
+	return s[:len(s)-precision] + "." + s[len(s)-precision:]
+}
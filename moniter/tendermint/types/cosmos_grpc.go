@@ -0,0 +1,403 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stakingValidatorMethod    = "/cosmos.staking.v1beta1.Query/Validator"
+	slashingSigningInfoMethod = "/cosmos.slashing.v1beta1.Query/SigningInfo"
+	slashingParamsMethod      = "/cosmos.slashing.v1beta1.Query/Params"
+	govProposalsMethod        = "/cosmos.gov.v1.Query/Proposals"
+	govVoteMethod             = "/cosmos.gov.v1.Query/Vote"
+	upgradeCurrentPlanMethod  = "/cosmos.upgrade.v1beta1.Query/CurrentPlan"
+
+	// proposalStatusVotingPeriodValue is gov.v1.ProposalStatus of PROPOSAL_STATUS_VOTING_PERIOD.
+	proposalStatusVotingPeriodValue = 2
+)
+
+// proposalStatuses is names of gov.v1.ProposalStatus.
+var proposalStatuses = map[uint64]string{
+	0: "PROPOSAL_STATUS_UNSPECIFIED",
+	1: "PROPOSAL_STATUS_DEPOSIT_PERIOD",
+	2: "PROPOSAL_STATUS_VOTING_PERIOD",
+	3: "PROPOSAL_STATUS_PASSED",
+	4: "PROPOSAL_STATUS_REJECTED",
+	5: "PROPOSAL_STATUS_FAILED",
+}
+
+// voteOptions is names of gov.v1.VoteOption.
+var voteOptions = map[uint64]string{
+	0: "VOTE_OPTION_UNSPECIFIED",
+	1: "VOTE_OPTION_YES",
+	2: "VOTE_OPTION_ABSTAIN",
+	3: "VOTE_OPTION_NO",
+	4: "VOTE_OPTION_NO_WITH_VETO",
+}
+
+// bondStatuses is names of staking.v1beta1.BondStatus.
+var bondStatuses = map[uint64]string{
+	0: "BOND_STATUS_UNSPECIFIED",
+	1: "BOND_STATUS_UNBONDED",
+	2: "BOND_STATUS_UNBONDING",
+	3: "BOND_STATUS_BONDED",
+}
+
+// cosmosGrpcClient queries through the gRPC server of the node. (e.g. `127.0.0.1:9090`)
+// Messages are encoded by hand to avoid depending on the Cosmos SDK.
+type cosmosGrpcClient struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func newCosmosGrpcClient(address string, timeout time.Duration) (*cosmosGrpcClient, error) {
+	// It doesn't connect until the first query.
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.New("failed to create grpc client. address: " + address + ", err: " + err.Error())
+	}
+	return &cosmosGrpcClient{conn: conn, timeout: timeout}, nil
+}
+
+func (c *cosmosGrpcClient) GetValidator(ctx context.Context, valoperAddress string) (*CosmosValidator, error) {
+	// QueryValidatorRequest{validator_addr = 1}
+	res, err := c.invoke(ctx, stakingValidatorMethod, protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), valoperAddress))
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := res.message(1)
+	if err != nil {
+		return nil, err
+	}
+	consensusPubkey, err := validator.message(2)
+	if err != nil {
+		return nil, err
+	}
+	// google.protobuf.Any{type_url = 1, value = 2}, and the value is PubKey{key = 1}
+	pubkey, err := protoMessageOf(consensusPubkey.bytes(2))
+	if err != nil {
+		return nil, err
+	}
+	commission, err := validator.message(10)
+	if err != nil {
+		return nil, err
+	}
+	commissionRates, err := commission.message(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosValidator{
+		OperatorAddress:         validator.string(1),
+		ConsensusPubkeyType:     consensusPubkey.string(1),
+		ConsensusPubkey:         pubkey.bytes(1),
+		Jailed:                  validator.varint(3) != 0,
+		Status:                  enumName(bondStatuses, validator.varint(4)),
+		Tokens:                  validator.string(5),
+		DelegatorShares:         formatLegacyDec(validator.string(6)),
+		CommissionRate:          formatLegacyDec(commissionRates.string(1)),
+		CommissionMaxRate:       formatLegacyDec(commissionRates.string(2)),
+		CommissionMaxChangeRate: formatLegacyDec(commissionRates.string(3)),
+	}, nil
+}
+
+func (c *cosmosGrpcClient) GetSigningInfo(ctx context.Context, consAddress string) (*CosmosSigningInfo, error) {
+	// QuerySigningInfoRequest{cons_address = 1}
+	res, err := c.invoke(ctx, slashingSigningInfoMethod, protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), consAddress))
+	if err != nil {
+		return nil, err
+	}
+
+	signingInfo, err := res.message(1)
+	if err != nil {
+		return nil, err
+	}
+	jailedUntil, err := signingInfo.message(4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosSigningInfo{
+		Address:             signingInfo.string(1),
+		StartHeight:         int64(signingInfo.varint(2)),
+		IndexOffset:         int64(signingInfo.varint(3)),
+		JailedUntil:         jailedUntil.timestamp(),
+		Tombstoned:          signingInfo.varint(5) != 0,
+		MissedBlocksCounter: int64(signingInfo.varint(6)),
+	}, nil
+}
+
+func (c *cosmosGrpcClient) GetSlashingParams(ctx context.Context) (*CosmosSlashingParams, error) {
+	res, err := c.invoke(ctx, slashingParamsMethod, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := res.message(1)
+	if err != nil {
+		return nil, err
+	}
+	downtimeJailDuration, err := params.message(3)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosSlashingParams{
+		SignedBlocksWindow:      int64(params.varint(1)),
+		MinSignedPerWindow:      formatLegacyDec(params.string(2)),
+		DowntimeJailDuration:    downtimeJailDuration.duration(),
+		SlashFractionDoubleSign: formatLegacyDec(params.string(4)),
+		SlashFractionDowntime:   formatLegacyDec(params.string(5)),
+	}, nil
+}
+
+func (c *cosmosGrpcClient) GetVotingProposals(ctx context.Context) ([]CosmosProposal, error) {
+	// QueryProposalsRequest{proposal_status = 1, pagination = 4} and PageRequest{limit = 3}
+	var req, pagination []byte
+	pagination = protowire.AppendTag(pagination, 3, protowire.VarintType)
+	pagination = protowire.AppendVarint(pagination, maxProposals)
+	req = protowire.AppendTag(req, 1, protowire.VarintType)
+	req = protowire.AppendVarint(req, proposalStatusVotingPeriodValue)
+	req = protowire.AppendTag(req, 4, protowire.BytesType)
+	req = protowire.AppendBytes(req, pagination)
+
+	res, err := c.invoke(ctx, govProposalsMethod, req)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := res.messages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposals []CosmosProposal
+	for _, p := range messages {
+		votingStartTime, err := p.message(8)
+		if err != nil {
+			return nil, err
+		}
+		votingEndTime, err := p.message(9)
+		if err != nil {
+			return nil, err
+		}
+
+		proposals = append(proposals, CosmosProposal{
+			ProposalId:      p.varint(1),
+			Title:           proposalTitle(p.string(11), p.string(10)),
+			Status:          enumName(proposalStatuses, p.varint(3)),
+			VotingStartTime: votingStartTime.timestamp(),
+			VotingEndTime:   votingEndTime.timestamp(),
+		})
+	}
+
+	return proposals, nil
+}
+
+func (c *cosmosGrpcClient) GetVote(ctx context.Context, proposalId uint64, voter string) (*CosmosVote, error) {
+	// QueryVoteRequest{proposal_id = 1, voter = 2}
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.VarintType)
+	req = protowire.AppendVarint(req, proposalId)
+	req = protowire.AppendTag(req, 2, protowire.BytesType)
+	req = protowire.AppendString(req, voter)
+
+	res, err := c.invoke(ctx, govVoteMethod, req)
+	if err != nil {
+		// The SDK answers InvalidArgument or NotFound depending on its version.
+		if s, ok := status.FromError(err); ok && (s.Code() == codes.NotFound ||
+			(s.Code() == codes.InvalidArgument && strings.Contains(s.Message(), "not found"))) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	v, err := res.message(1)
+	if err != nil {
+		return nil, err
+	}
+	// Vote{options = 4} and WeightedVoteOption{option = 1}
+	options, err := v.messages(4)
+	if err != nil {
+		return nil, err
+	}
+
+	vote := CosmosVote{ProposalId: proposalId, Voter: v.string(2)}
+	for _, option := range options {
+		vote.Options = append(vote.Options, enumName(voteOptions, option.varint(1)))
+	}
+	return &vote, nil
+}
+
+func (c *cosmosGrpcClient) GetCurrentPlan(ctx context.Context) (*CosmosUpgradePlan, error) {
+	res, err := c.invoke(ctx, upgradeCurrentPlanMethod, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res[1]) == 0 {
+		return nil, nil
+	}
+
+	// Plan{name = 1, height = 3, info = 4}
+	plan, err := res.message(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosUpgradePlan{
+		Name:   plan.string(1),
+		Height: int64(plan.varint(3)),
+		Info:   plan.string(4),
+	}, nil
+}
+
+func (c *cosmosGrpcClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *cosmosGrpcClient) invoke(ctx context.Context, method string, req []byte) (protoMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var res []byte
+	err := c.conn.Invoke(ctx, method, req, &res, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return nil, fmt.Errorf("Could not fetch cosmos api. target: %s, method: %s, err: %w", c.conn.Target(), method, err)
+	}
+
+	return protoMessageOf(res)
+}
+
+// enumName returns name of the enum value, or the value itself if it's unknown.
+func enumName(names map[uint64]string, value uint64) string {
+	if name, exists := names[value]; exists {
+		return name
+	}
+	return strconv.FormatUint(value, 10)
+}
+
+// rawCodec passes already encoded messages through.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type: %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type: %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// protoMessage is fields of a decoded protobuf message. Accessors return the last one of repeated fields.
+type protoMessage map[protowire.Number][]protoField
+
+type protoField struct {
+	varint uint64
+	bytes  []byte
+}
+
+func protoMessageOf(b []byte) (protoMessage, error) {
+	m := protoMessage{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			m[num] = append(m[num], protoField{varint: v})
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			m[num] = append(m[num], protoField{bytes: v})
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return m, nil
+}
+
+func (m protoMessage) last(num protowire.Number) protoField {
+	fields := m[num]
+	if len(fields) == 0 {
+		return protoField{}
+	}
+	return fields[len(fields)-1]
+}
+
+func (m protoMessage) varint(num protowire.Number) uint64 {
+	return m.last(num).varint
+}
+
+func (m protoMessage) bytes(num protowire.Number) []byte {
+	return m.last(num).bytes
+}
+
+func (m protoMessage) string(num protowire.Number) string {
+	return string(m.last(num).bytes)
+}
+
+// message decodes an embedded message. Absent message is decoded as empty.
+func (m protoMessage) message(num protowire.Number) (protoMessage, error) {
+	return protoMessageOf(m.last(num).bytes)
+}
+
+// messages decodes a repeated embedded message.
+func (m protoMessage) messages(num protowire.Number) ([]protoMessage, error) {
+	var messages []protoMessage
+	for _, field := range m[num] {
+		message, err := protoMessageOf(field.bytes)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// timestamp decodes google.protobuf.Timestamp{seconds = 1, nanos = 2}.
+func (m protoMessage) timestamp() time.Time {
+	return time.Unix(int64(m.varint(1)), int64(m.varint(2))).UTC()
+}
+
+// duration decodes google.protobuf.Duration{seconds = 1, nanos = 2}.
+func (m protoMessage) duration() time.Duration {
+	return time.Duration(int64(m.varint(1)))*time.Second + time.Duration(int64(m.varint(2)))
+}
@@ -0,0 +1,303 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stakingValidatorEndpoint    = "/cosmos/staking/v1beta1/validators/"
+	slashingSigningInfoEndpoint = "/cosmos/slashing/v1beta1/signing_infos/"
+	slashingParamsEndpoint      = "/cosmos/slashing/v1beta1/params"
+	govProposalsEndpoint        = "/cosmos/gov/v1/proposals"
+	upgradeCurrentPlanEndpoint  = "/cosmos/upgrade/v1beta1/current_plan"
+)
+
+// cosmosRestClient queries through the REST API(grpc-gateway) of the node. (e.g. `http://127.0.0.1:1317`)
+type cosmosRestClient struct {
+	address    string
+	httpClient HttpClient
+	timeout    time.Duration
+}
+
+type cosmosRestValidatorResponse struct {
+	Validator struct {
+		OperatorAddress string `json:"operator_address"`
+		ConsensusPubkey struct {
+			Type string `json:"@type"`
+			Key  string `json:"key"`
+		} `json:"consensus_pubkey"`
+		Jailed          bool   `json:"jailed"`
+		Status          string `json:"status"`
+		Tokens          string `json:"tokens"`
+		DelegatorShares string `json:"delegator_shares"`
+		Commission      struct {
+			CommissionRates struct {
+				Rate          string `json:"rate"`
+				MaxRate       string `json:"max_rate"`
+				MaxChangeRate string `json:"max_change_rate"`
+			} `json:"commission_rates"`
+		} `json:"commission"`
+	} `json:"validator"`
+}
+
+type cosmosRestSigningInfoResponse struct {
+	ValSigningInfo struct {
+		Address             string    `json:"address"`
+		StartHeight         string    `json:"start_height"`
+		IndexOffset         string    `json:"index_offset"`
+		JailedUntil         time.Time `json:"jailed_until"`
+		Tombstoned          bool      `json:"tombstoned"`
+		MissedBlocksCounter string    `json:"missed_blocks_counter"`
+	} `json:"val_signing_info"`
+}
+
+type cosmosRestSlashingParamsResponse struct {
+	Params struct {
+		SignedBlocksWindow      string `json:"signed_blocks_window"`
+		MinSignedPerWindow      string `json:"min_signed_per_window"`
+		DowntimeJailDuration    string `json:"downtime_jail_duration"`
+		SlashFractionDoubleSign string `json:"slash_fraction_double_sign"`
+		SlashFractionDowntime   string `json:"slash_fraction_downtime"`
+	} `json:"params"`
+}
+
+type cosmosRestProposalsResponse struct {
+	Proposals []struct {
+		Id              string    `json:"id"`
+		Title           string    `json:"title"`
+		Metadata        string    `json:"metadata"`
+		Status          string    `json:"status"`
+		VotingStartTime time.Time `json:"voting_start_time"`
+		VotingEndTime   time.Time `json:"voting_end_time"`
+	} `json:"proposals"`
+}
+
+type cosmosRestVoteResponse struct {
+	Vote struct {
+		ProposalId string `json:"proposal_id"`
+		Voter      string `json:"voter"`
+		Options    []struct {
+			Option string `json:"option"`
+			Weight string `json:"weight"`
+		} `json:"options"`
+	} `json:"vote"`
+}
+
+type cosmosRestCurrentPlanResponse struct {
+	Plan *struct {
+		Name   string `json:"name"`
+		Height string `json:"height"`
+		Info   string `json:"info"`
+	} `json:"plan"`
+}
+
+// cosmosApiError is a response of the REST API which isn't 200 OK.
+type cosmosApiError struct {
+	statusCode int
+	address    string
+	body       string
+}
+
+func (e *cosmosApiError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, address: %s, body: %s", e.statusCode, e.address, e.body)
+}
+
+func (c *cosmosRestClient) GetValidator(ctx context.Context, valoperAddress string) (*CosmosValidator, error) {
+	var res cosmosRestValidatorResponse
+	err := c.get(ctx, stakingValidatorEndpoint+valoperAddress, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	pubkey, err := base64.StdEncoding.DecodeString(res.Validator.ConsensusPubkey.Key)
+	if err != nil {
+		return nil, errors.New("invalid consensus pubkey: " + err.Error())
+	}
+
+	return &CosmosValidator{
+		OperatorAddress:         res.Validator.OperatorAddress,
+		ConsensusPubkeyType:     res.Validator.ConsensusPubkey.Type,
+		ConsensusPubkey:         pubkey,
+		Jailed:                  res.Validator.Jailed,
+		Status:                  res.Validator.Status,
+		Tokens:                  res.Validator.Tokens,
+		DelegatorShares:         res.Validator.DelegatorShares,
+		CommissionRate:          res.Validator.Commission.CommissionRates.Rate,
+		CommissionMaxRate:       res.Validator.Commission.CommissionRates.MaxRate,
+		CommissionMaxChangeRate: res.Validator.Commission.CommissionRates.MaxChangeRate,
+	}, nil
+}
+
+func (c *cosmosRestClient) GetSigningInfo(ctx context.Context, consAddress string) (*CosmosSigningInfo, error) {
+	var res cosmosRestSigningInfoResponse
+	err := c.get(ctx, slashingSigningInfoEndpoint+consAddress, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	signingInfo := CosmosSigningInfo{
+		Address:     res.ValSigningInfo.Address,
+		JailedUntil: res.ValSigningInfo.JailedUntil,
+		Tombstoned:  res.ValSigningInfo.Tombstoned,
+	}
+	signingInfo.StartHeight, err = parseInt64(res.ValSigningInfo.StartHeight)
+	if err != nil {
+		return nil, err
+	}
+	signingInfo.IndexOffset, err = parseInt64(res.ValSigningInfo.IndexOffset)
+	if err != nil {
+		return nil, err
+	}
+	signingInfo.MissedBlocksCounter, err = parseInt64(res.ValSigningInfo.MissedBlocksCounter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingInfo, nil
+}
+
+func (c *cosmosRestClient) GetSlashingParams(ctx context.Context) (*CosmosSlashingParams, error) {
+	var res cosmosRestSlashingParamsResponse
+	err := c.get(ctx, slashingParamsEndpoint, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	signedBlocksWindow, err := parseInt64(res.Params.SignedBlocksWindow)
+	if err != nil {
+		return nil, err
+	}
+	downtimeJailDuration, err := time.ParseDuration(res.Params.DowntimeJailDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosSlashingParams{
+		SignedBlocksWindow:      signedBlocksWindow,
+		MinSignedPerWindow:      res.Params.MinSignedPerWindow,
+		DowntimeJailDuration:    downtimeJailDuration,
+		SlashFractionDoubleSign: res.Params.SlashFractionDoubleSign,
+		SlashFractionDowntime:   res.Params.SlashFractionDowntime,
+	}, nil
+}
+
+func (c *cosmosRestClient) GetVotingProposals(ctx context.Context) ([]CosmosProposal, error) {
+	var res cosmosRestProposalsResponse
+	err := c.get(ctx, fmt.Sprintf("%s?proposal_status=%s&pagination.limit=%d", govProposalsEndpoint, proposalStatusVotingPeriod, maxProposals), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposals []CosmosProposal
+	for _, p := range res.Proposals {
+		proposalId, err := strconv.ParseUint(p.Id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		proposals = append(proposals, CosmosProposal{
+			ProposalId:      proposalId,
+			Title:           proposalTitle(p.Title, p.Metadata),
+			Status:          p.Status,
+			VotingStartTime: p.VotingStartTime,
+			VotingEndTime:   p.VotingEndTime,
+		})
+	}
+
+	return proposals, nil
+}
+
+func (c *cosmosRestClient) GetVote(ctx context.Context, proposalId uint64, voter string) (*CosmosVote, error) {
+	var res cosmosRestVoteResponse
+	err := c.get(ctx, fmt.Sprintf("%s/%d/votes/%s", govProposalsEndpoint, proposalId, voter), &res)
+	if err != nil {
+		var apiErr *cosmosApiError
+		// The SDK answers 400(InvalidArgument) or 404(NotFound) depending on its version.
+		if errors.As(err, &apiErr) && (apiErr.statusCode == http.StatusNotFound ||
+			(apiErr.statusCode == http.StatusBadRequest && strings.Contains(apiErr.body, "not found"))) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	vote := CosmosVote{ProposalId: proposalId, Voter: res.Vote.Voter}
+	for _, option := range res.Vote.Options {
+		vote.Options = append(vote.Options, option.Option)
+	}
+	return &vote, nil
+}
+
+func (c *cosmosRestClient) GetCurrentPlan(ctx context.Context) (*CosmosUpgradePlan, error) {
+	var res cosmosRestCurrentPlanResponse
+	err := c.get(ctx, upgradeCurrentPlanEndpoint, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Plan == nil {
+		return nil, nil
+	}
+
+	height, err := parseInt64(res.Plan.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosmosUpgradePlan{
+		Name:   res.Plan.Name,
+		Height: height,
+		Info:   res.Plan.Info,
+	}, nil
+}
+
+// Close does nothing, since the http client is shared.
+func (c *cosmosRestClient) Close() error {
+	return nil
+}
+
+func (c *cosmosRestClient) get(ctx context.Context, path string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	address := c.address + path
+	req, err := requestGet(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.New("Could not fetch cosmos api. address: " + address + ", err: " + err.Error())
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return &cosmosApiError{statusCode: res.StatusCode, address: address, body: string(body)}
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return errors.New("Json marshaling error: " + err.Error())
+	}
+	return nil
+}
+
+// parseInt64 parses int64 which is encoded as string in json of Cosmos SDK.
+func parseInt64(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
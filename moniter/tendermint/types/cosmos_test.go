@@ -0,0 +1,141 @@
+package types
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCosmos(t *testing.T) {
+
+	t.Run("format legacy dec", func(t *testing.T) {
+		assert.Equal(t, "0.050000000000000000", formatLegacyDec("50000000000000000"))
+		assert.Equal(t, "1.000000000000000000", formatLegacyDec("1000000000000000000"))
+		assert.Equal(t, "0.000000000000000001", formatLegacyDec("1"))
+		assert.Equal(t, "", formatLegacyDec(""))
+	})
+
+	t.Run("consensus address", func(t *testing.T) {
+		validator := CosmosValidator{
+			OperatorAddress:     "cosmosvaloper1clpqr4nrk4khgkxj78fcwwh6dl3uw4epsluffn",
+			ConsensusPubkeyType: ed25519PubKeyType,
+			ConsensusPubkey:     make([]byte, 32),
+		}
+		address, err := validator.ConsensusAddress()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(address, "cosmosvalcons1"))
+
+		validator.ConsensusPubkeyType = "/cosmos.crypto.secp256k1.PubKey"
+		_, err = validator.ConsensusAddress()
+		assert.Error(t, err)
+	})
+
+	t.Run("decode protobuf message", func(t *testing.T) {
+		// Timestamp{seconds = 1725148800, nanos = 5}
+		var timestamp []byte
+		timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, 1725148800)
+		timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, 5)
+
+		// ValidatorSigningInfo{address = 1, jailed_until = 4, tombstoned = 5, missed_blocks_counter = 6} with an unknown fixed64 field
+		var signingInfo []byte
+		signingInfo = protowire.AppendTag(signingInfo, 1, protowire.BytesType)
+		signingInfo = protowire.AppendString(signingInfo, "cosmosvalcons1abc")
+		signingInfo = protowire.AppendTag(signingInfo, 4, protowire.BytesType)
+		signingInfo = protowire.AppendBytes(signingInfo, timestamp)
+		signingInfo = protowire.AppendTag(signingInfo, 5, protowire.VarintType)
+		signingInfo = protowire.AppendVarint(signingInfo, 1)
+		signingInfo = protowire.AppendTag(signingInfo, 6, protowire.VarintType)
+		signingInfo = protowire.AppendVarint(signingInfo, 42)
+		signingInfo = protowire.AppendTag(signingInfo, 7, protowire.Fixed64Type)
+		signingInfo = protowire.AppendFixed64(signingInfo, 7)
+
+		m, err := protoMessageOf(signingInfo)
+		assert.NoError(t, err)
+		assert.Equal(t, "cosmosvalcons1abc", m.string(1))
+		assert.Equal(t, uint64(1), m.varint(5))
+		assert.Equal(t, uint64(42), m.varint(6))
+
+		jailedUntil, err := m.message(4)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Unix(1725148800, 5).UTC(), jailedUntil.timestamp())
+
+		_, err = protoMessageOf(signingInfo[:len(signingInfo)-1])
+		assert.Error(t, err)
+	})
+
+	t.Run("query through rest", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case slashingParamsEndpoint:
+				w.Write([]byte(`{"params":{"signed_blocks_window":"10000","min_signed_per_window":"0.050000000000000000","downtime_jail_duration":"600s","slash_fraction_double_sign":"0.050000000000000000","slash_fraction_downtime":"0.000100000000000000"}}`))
+			case slashingSigningInfoEndpoint + "cosmosvalcons1abc":
+				w.Write([]byte(`{"val_signing_info":{"address":"cosmosvalcons1abc","start_height":"0","index_offset":"123","jailed_until":"1970-01-01T00:00:00Z","tombstoned":false,"missed_blocks_counter":"7"}}`))
+			case upgradeCurrentPlanEndpoint:
+				w.Write([]byte(`{"plan":{"name":"v18","time":"0001-01-01T00:00:00Z","height":"21000000","info":"","upgraded_client_state":null}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		client, err := newCosmosQueryClient(&CosmosConfig{Protocol: CosmosProtocolRest, Address: server.URL + "/"}, server.Client(), time.Second)
+		assert.NoError(t, err)
+
+		params, err := client.GetSlashingParams(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10000), params.SignedBlocksWindow)
+		assert.Equal(t, 10*time.Minute, params.DowntimeJailDuration)
+
+		signingInfo, err := client.GetSigningInfo(context.Background(), "cosmosvalcons1abc")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), signingInfo.MissedBlocksCounter)
+		assert.Equal(t, int64(123), signingInfo.IndexOffset)
+
+		plan, err := client.GetCurrentPlan(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "v18", plan.Name)
+		assert.Equal(t, int64(21000000), plan.Height)
+
+		_, err = client.GetValidator(context.Background(), "cosmosvaloper1unknown")
+		assert.Error(t, err)
+	})
+
+	t.Run("no upgrade plan through rest", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"plan":null}`))
+		}))
+		defer server.Close()
+
+		client, err := newCosmosQueryClient(&CosmosConfig{Protocol: CosmosProtocolRest, Address: server.URL}, server.Client(), time.Second)
+		assert.NoError(t, err)
+
+		plan, err := client.GetCurrentPlan(context.Background())
+		assert.NoError(t, err)
+		assert.Nil(t, plan)
+	})
+
+	t.Run("config defaults to the api of the host", func(t *testing.T) {
+		t.Setenv(EnvCosmosProtocol, "")
+		t.Setenv(EnvCosmosAddress, "")
+		t.Setenv(EnvValoperAddress, "cosmosvaloper1abc")
+
+		cfg := CosmosConfig{}
+		assert.NoError(t, cfg.ApplyConfigFromEnvAndDefault("10.0.0.2"))
+		assert.Equal(t, CosmosConfig{Protocol: CosmosProtocolRest, Address: "http://10.0.0.2:1317", ValoperAddress: "cosmosvaloper1abc"}, cfg)
+
+		cfg = CosmosConfig{Protocol: CosmosProtocolGrpc}
+		assert.NoError(t, cfg.ApplyConfigFromEnvAndDefault("10.0.0.2"))
+		assert.Equal(t, "10.0.0.2:9090", cfg.Address)
+
+		cfg = CosmosConfig{Protocol: "websocket"}
+		assert.Error(t, cfg.ApplyConfigFromEnvAndDefault("10.0.0.2"))
+	})
+
+}
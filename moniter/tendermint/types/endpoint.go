@@ -0,0 +1,179 @@
+package types
+
+import (
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint is one RPC listener of the agent(node).
+// Endpoints are listed in order of priority, the first one is the primary.
+type Endpoint struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// Scheme is `http` or `https`. When it's empty, it's taken from Host(e.g. `https://rpc.example.com`),
+	// or https for port 443 and http otherwise.
+	Scheme string `yaml:"scheme"`
+	// Headers are sent with every request to the endpoint. (e.g. api key of a proxy)
+	Headers map[string]Secret `yaml:"headers"`
+	Auth    *EndpointAuth     `yaml:"auth"`
+	TLS     *TLSConfig        `yaml:"tls"`
+}
+
+func (e Endpoint) String() string {
+	return fmt.Sprintf("%s:%s", e.Host, strconv.Itoa(e.Port))
+}
+
+// url returns the address of path on the endpoint.
+func (e Endpoint) url(path string) string {
+	host := e.Host
+	scheme, hostWithoutScheme, found := strings.Cut(host, "://")
+	if found {
+		host = hostWithoutScheme
+	} else {
+		scheme = "http"
+		if e.Port == 443 {
+			scheme = "https"
+		}
+	}
+	if e.Scheme != "" {
+		scheme = e.Scheme
+	}
+	return fmt.Sprintf("%s://%s:%d%s", scheme, host, e.Port, path)
+}
+
+const (
+	// endpointFailureThreshold is how many consecutive failures mark an endpoint as unhealthy.
+	endpointFailureThreshold = 3
+	// endpointProbeInterval is how long an unhealthy endpoint is avoided before it's tried again.
+	// A successful probe on a higher priority endpoint makes the client fail back to it.
+	endpointProbeInterval = 30 * time.Second
+	// endpointScoreWeight is the weight of the latest result in the EWMA health score.
+	endpointScoreWeight = 0.3
+)
+
+type endpointHealth struct {
+	endpoint    Endpoint
+	priority    int
+	score       float64
+	failures    int
+	lastFailure time.Time
+	requests    uint64
+	errors      uint64
+}
+
+func (h *endpointHealth) healthy(now time.Time) bool {
+	return h.failures < endpointFailureThreshold || now.Sub(h.lastFailure) >= endpointProbeInterval
+}
+
+// EndpointStatus is a snapshot of an endpoint's health.
+type EndpointStatus struct {
+	Endpoint            string
+	Score               float64
+	ConsecutiveFailures int
+	Healthy             bool
+	// Requests and Errors are counted since the process started.
+	Requests uint64
+	Errors   uint64
+}
+
+// endpointPool health-scores the agent's endpoints and decides which one serves the next request.
+type endpointPool struct {
+	mu      sync.Mutex
+	healths []*endpointHealth
+	current string
+}
+
+func newEndpointPool(endpoints []Endpoint) *endpointPool {
+	pool := &endpointPool{}
+	for i, e := range endpoints {
+		pool.healths = append(pool.healths, &endpointHealth{endpoint: e, priority: i, score: 1})
+	}
+	if len(endpoints) > 0 {
+		pool.current = endpoints[0].String()
+	}
+	return pool
+}
+
+// candidates returns endpoints in the order they should be tried.
+// Healthy ones come first by priority, then unhealthy ones by health score.
+func (p *endpointPool) candidates() []Endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now                = time.Now()
+		healthy, unhealthy []*endpointHealth
+	)
+	for _, h := range p.healths {
+		if h.healthy(now) {
+			healthy = append(healthy, h)
+		} else {
+			unhealthy = append(unhealthy, h)
+		}
+	}
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return unhealthy[i].score > unhealthy[j].score
+	})
+
+	var result []Endpoint
+	for _, h := range append(healthy, unhealthy...) {
+		result = append(result, h.endpoint)
+	}
+	return result
+}
+
+func (p *endpointPool) report(endpoint Endpoint, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, h := range p.healths {
+		if h.endpoint.String() != endpoint.String() {
+			continue
+		}
+
+		h.requests++
+		if success {
+			h.failures = 0
+			h.score = h.score*(1-endpointScoreWeight) + endpointScoreWeight
+			if p.current != endpoint.String() {
+				log.Warn(fmt.Sprintf("[endpoint] switched rpc endpoint %s -> %s (priority: %d)", p.current, endpoint.String(), h.priority))
+				p.current = endpoint.String()
+			}
+		} else {
+			h.errors++
+			h.failures++
+			h.lastFailure = time.Now()
+			h.score = h.score * (1 - endpointScoreWeight)
+			if h.failures == endpointFailureThreshold {
+				log.Warn(fmt.Sprintf("[endpoint] rpc endpoint %s marked as unhealthy after %d consecutive failures", endpoint.String(), h.failures))
+			}
+		}
+		return
+	}
+}
+
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now    = time.Now()
+		result []EndpointStatus
+	)
+	for _, h := range p.healths {
+		result = append(result, EndpointStatus{
+			Endpoint:            h.endpoint.String(),
+			Score:               h.score,
+			ConsecutiveFailures: h.failures,
+			Healthy:             h.healthy(now),
+			Requests:            h.requests,
+			Errors:              h.errors,
+		})
+	}
+	return result
+}
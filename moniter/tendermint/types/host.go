@@ -0,0 +1,289 @@
+package types
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HostStat is resources of the host read from procfs.
+type HostStat struct {
+	MemTotalBytes  uint64
+	MemAvailBytes  uint64
+	SwapTotalBytes uint64
+	SwapFreeBytes  uint64
+	Load1          float64
+	Load5          float64
+	Load15         float64
+	NumCPU         int
+	// OpenFds is the number of allocated file descriptors of the whole system, MaxFds is its limit. (fs.file-max)
+	OpenFds uint64
+	MaxFds  uint64
+}
+
+// Mount is an entry of /proc/mounts.
+type Mount struct {
+	Device     string
+	MountPoint string
+	FsType     string
+}
+
+// DiskStat is usage of the filesystem which contains Path.
+type DiskStat struct {
+	Path        string
+	Mount       Mount
+	TotalBytes  uint64
+	UsedBytes   uint64
+	AvailBytes  uint64
+	TotalInodes uint64
+	FreeInodes  uint64
+}
+
+// ReadHostStat reads memory, load average, cpu count and file descriptors from procfs mounted at procPath.
+func ReadHostStat(procPath string) (*HostStat, error) {
+	var stat HostStat
+
+	err := readProcFile(procPath, "meminfo", func(r io.Reader) error {
+		return parseMeminfo(r, &stat)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readProcFile(procPath, "loadavg", func(r io.Reader) error {
+		return parseLoadavg(r, &stat)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readProcFile(procPath, "stat", func(r io.Reader) error {
+		numCPU, err := countCPUs(r)
+		stat.NumCPU = numCPU
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readProcFile(procPath, "sys/fs/file-nr", func(r io.Reader) error {
+		return parseFileNr(r, &stat)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stat, nil
+}
+
+// ReadMounts reads mounted filesystems backed by a block device, one per device.
+func ReadMounts(procPath string) ([]Mount, error) {
+	var mounts []Mount
+
+	err := readProcFile(procPath, "mounts", func(r io.Reader) error {
+		all, err := parseMounts(r)
+		if err != nil {
+			return err
+		}
+
+		// The same device may be mounted several times by bind mounts.
+		seen := map[string]bool{}
+		for _, m := range all {
+			if !strings.HasPrefix(m.Device, "/dev/") || seen[m.Device] {
+				continue
+			}
+			seen[m.Device] = true
+			mounts = append(mounts, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mounts, nil
+}
+
+// FindMount returns the mount which contains path.
+func FindMount(mounts []Mount, path string) (Mount, bool) {
+	var (
+		found Mount
+		ok    bool
+	)
+	path = filepath.Clean(path)
+	for _, m := range mounts {
+		if !isSubPath(m.MountPoint, path) {
+			continue
+		}
+		if !ok || len(m.MountPoint) > len(found.MountPoint) {
+			found, ok = m, true
+		}
+	}
+	return found, ok
+}
+
+func isSubPath(parent, path string) bool {
+	if parent == "/" || parent == path {
+		return true
+	}
+	return strings.HasPrefix(path, parent+"/")
+}
+
+func readProcFile(procPath, name string, parse func(r io.Reader) error) error {
+	f, err := os.Open(filepath.Join(procPath, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+// parseMeminfo parses /proc/meminfo. Values are in kB.
+func parseMeminfo(r io.Reader, stat *HostStat) error {
+	fields := map[string]*uint64{
+		"MemTotal":     &stat.MemTotalBytes,
+		"MemAvailable": &stat.MemAvailBytes,
+		"SwapTotal":    &stat.SwapTotalBytes,
+		"SwapFree":     &stat.SwapFreeBytes,
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		field, exists := fields[key]
+		if !exists {
+			continue
+		}
+
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			return err
+		}
+		*field = kb * 1024
+		delete(fields, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if _, exists := fields["MemTotal"]; exists {
+		return fmt.Errorf("MemTotal not found")
+	}
+	return nil
+}
+
+// parseLoadavg parses /proc/loadavg. (e.g. `0.20 0.18 0.12 1/80 11206`)
+func parseLoadavg(r io.Reader, stat *HostStat) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return fmt.Errorf("unexpected format: %s", string(b))
+	}
+
+	loads := []*float64{&stat.Load1, &stat.Load5, &stat.Load15}
+	for i, load := range loads {
+		*load, err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countCPUs counts `cpuN` lines of /proc/stat.
+func countCPUs(r io.Reader) (int, error) {
+	var count int
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) > 3 && strings.HasPrefix(line, "cpu") && line[3] >= '0' && line[3] <= '9' {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// parseFileNr parses /proc/sys/fs/file-nr. (allocated, unused, max)
+func parseFileNr(r io.Reader, stat *HostStat) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return fmt.Errorf("unexpected format: %s", string(b))
+	}
+
+	allocated, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	unused, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return err
+	}
+	stat.MaxFds, err = strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	// Since 2.6 the kernel always reports 0 unused, but older ones don't.
+	stat.OpenFds = allocated - unused
+	return nil
+}
+
+// parseMounts parses /proc/mounts. Spaces in paths are escaped as octal. (e.g. `\040`)
+func parseMounts(r io.Reader) ([]Mount, error) {
+	var mounts []Mount
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			FsType:     fields[2],
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"syscall"
+)
+
+// ReadDiskStat reads usage of the filesystem which contains path.
+func ReadDiskStat(path string, mount Mount) (*DiskStat, error) {
+	var fs syscall.Statfs_t
+	err := syscall.Statfs(path, &fs)
+	if err != nil {
+		return nil, err
+	}
+
+	bsize := uint64(fs.Bsize)
+	return &DiskStat{
+		Path:        path,
+		Mount:       mount,
+		TotalBytes:  fs.Blocks * bsize,
+		UsedBytes:   (fs.Blocks - fs.Bfree) * bsize,
+		AvailBytes:  fs.Bavail * bsize,
+		TotalInodes: fs.Files,
+		FreeInodes:  fs.Ffree,
+	}, nil
+}
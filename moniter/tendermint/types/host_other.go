@@ -0,0 +1,12 @@
+//go:build !linux
+
+package types
+
+import (
+	"errors"
+)
+
+// ReadDiskStat is only supported on linux.
+func ReadDiskStat(path string, mount Mount) (*DiskStat, error) {
+	return nil, errors.New("disk usage is only supported on linux. path: " + path)
+}
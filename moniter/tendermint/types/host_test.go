@@ -0,0 +1,62 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestHostStat(t *testing.T) {
+
+	t.Run("parse meminfo", func(t *testing.T) {
+		var stat HostStat
+		err := parseMeminfo(strings.NewReader(`MemTotal:       16318412 kB
+MemFree:          512340 kB
+MemAvailable:    8159206 kB
+Buffers:          123456 kB
+SwapTotal:       2097148 kB
+SwapFree:        2097148 kB
+`), &stat)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(16318412*1024), stat.MemTotalBytes)
+		assert.Equal(t, uint64(8159206*1024), stat.MemAvailBytes)
+		assert.Equal(t, uint64(2097148*1024), stat.SwapTotalBytes)
+		assert.Equal(t, uint64(2097148*1024), stat.SwapFreeBytes)
+
+		assert.Error(t, parseMeminfo(strings.NewReader("MemFree: 1 kB\n"), &stat))
+	})
+
+	t.Run("parse loadavg, file-nr and cpus", func(t *testing.T) {
+		var stat HostStat
+		assert.NoError(t, parseLoadavg(strings.NewReader("0.20 1.18 2.12 1/80 11206\n"), &stat))
+		assert.Equal(t, []float64{0.20, 1.18, 2.12}, []float64{stat.Load1, stat.Load5, stat.Load15})
+
+		assert.NoError(t, parseFileNr(strings.NewReader("3200\t0\t9223372036854775807\n"), &stat))
+		assert.Equal(t, uint64(3200), stat.OpenFds)
+		assert.Equal(t, uint64(9223372036854775807), stat.MaxFds)
+
+		numCPU, err := countCPUs(strings.NewReader("cpu  1 2 3\ncpu0 1 2 3\ncpu1 1 2 3\nintr 1\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, numCPU)
+	})
+
+	t.Run("find mount of the path", func(t *testing.T) {
+		mounts, err := parseMounts(strings.NewReader(`/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/nvme0n1 /data xfs rw,relatime 0 0
+/dev/sdb1 /mnt/node\040data ext4 rw,relatime 0 0
+`))
+		assert.NoError(t, err)
+		assert.Len(t, mounts, 4)
+		assert.Equal(t, "/mnt/node data", mounts[3].MountPoint)
+
+		mount, ok := FindMount(mounts, "/data/.gaia/data")
+		assert.True(t, ok)
+		assert.Equal(t, "/dev/nvme0n1", mount.Device)
+
+		mount, ok = FindMount(mounts, "/database")
+		assert.True(t, ok)
+		assert.Equal(t, "/", mount.MountPoint)
+	})
+
+}
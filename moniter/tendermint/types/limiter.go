@@ -0,0 +1,105 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// limiterInitialLimit is the concurrency an agent starts with. It grows up to the agent's BlockCommitMaxConcurrency.
+	limiterInitialLimit = 4
+	// limiterDecreaseInterval keeps concurrent throttled requests from halving the limit more than once.
+	limiterDecreaseInterval = time.Second
+)
+
+// StatusCodeError is returned when the rpc responds with a status code which has no usable body.
+type StatusCodeError struct {
+	StatusCode int
+}
+
+func (e *StatusCodeError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// isThrottled reports whether the rpc is rate limiting or overloaded.
+func isThrottled(err error) bool {
+	var statusCodeErr *StatusCodeError
+	return errors.As(err, &statusCodeErr) &&
+		(statusCodeErr.StatusCode == http.StatusTooManyRequests || statusCodeErr.StatusCode >= http.StatusInternalServerError)
+}
+
+// adaptiveLimiter bounds concurrent requests to an agent with AIMD.
+// The limit grows by one after as many successful requests as the limit, and halves when the rpc throttles.
+type adaptiveLimiter struct {
+	mu           sync.Mutex
+	limit        float64
+	max          int
+	inFlight     int
+	lastDecrease time.Time
+	// released is closed and replaced whenever a spot may have become available.
+	released chan struct{}
+}
+
+func newAdaptiveLimiter(max int) *adaptiveLimiter {
+	if max < 1 {
+		max = 1
+	}
+	return &adaptiveLimiter{
+		limit:    float64(min(max, limiterInitialLimit)),
+		max:      max,
+		released: make(chan struct{}),
+	}
+}
+
+// acquire blocks until a spot is available or ctx is done.
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// release frees the spot and adjusts the limit by the request's result.
+// Errors other than throttling (e.g. not found, cancelled) don't change the limit.
+func (l *adaptiveLimiter) release(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	switch {
+	case err == nil:
+		l.limit = min(l.limit+1/l.limit, float64(l.max))
+	case isThrottled(err):
+		now := time.Now()
+		if now.Sub(l.lastDecrease) >= limiterDecreaseInterval {
+			l.limit = max(l.limit/2, 1)
+			l.lastDecrease = now
+		}
+	}
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Limit returns the current concurrency limit.
+func (l *adaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
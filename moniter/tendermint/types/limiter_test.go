@@ -0,0 +1,65 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+
+	t.Run("grows additively up to max", func(t *testing.T) {
+		limiter := newAdaptiveLimiter(6)
+		assert.Equal(t, limiterInitialLimit, limiter.Limit())
+
+		// Each success adds 1/limit, so the limit grows by about one per round of requests.
+		for i := 0; i < 2*limiterInitialLimit; i++ {
+			assert.NoError(t, limiter.acquire(context.Background()))
+			limiter.release(nil)
+		}
+		assert.Equal(t, limiterInitialLimit+1, limiter.Limit())
+
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, limiter.acquire(context.Background()))
+			limiter.release(nil)
+		}
+		assert.Equal(t, 6, limiter.Limit())
+	})
+
+	t.Run("halves on throttling once per interval", func(t *testing.T) {
+		limiter := newAdaptiveLimiter(4)
+		throttled := &StatusCodeError{StatusCode: http.StatusTooManyRequests}
+
+		assert.NoError(t, limiter.acquire(context.Background()))
+		assert.NoError(t, limiter.acquire(context.Background()))
+		limiter.release(throttled)
+		limiter.release(throttled)
+		assert.Equal(t, 2, limiter.Limit())
+
+		limiter.lastDecrease = time.Now().Add(-limiterDecreaseInterval)
+		assert.NoError(t, limiter.acquire(context.Background()))
+		limiter.release(&StatusCodeError{StatusCode: http.StatusBadGateway})
+		assert.Equal(t, 1, limiter.Limit())
+
+		// Errors other than throttling don't change the limit.
+		assert.NoError(t, limiter.acquire(context.Background()))
+		limiter.release(errors.New("not found"))
+		assert.Equal(t, 1, limiter.Limit())
+	})
+
+	t.Run("blocks until a spot is released", func(t *testing.T) {
+		limiter := newAdaptiveLimiter(1)
+		assert.NoError(t, limiter.acquire(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, limiter.acquire(ctx), context.DeadlineExceeded)
+
+		go limiter.release(nil)
+		assert.NoError(t, limiter.acquire(context.Background()))
+	})
+
+}
@@ -0,0 +1,172 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Monitor is a monitor plugin. A monitor registers itself by RegisterMonitor in its init(),
+// so adding one needs no change of main or config.
+//
+// An instance is made for every monitor of every agent. Init is called once before it's scheduled, Run on every interval,
+// and Close once it stops being scheduled(on shutdown, or replaced on reload) and its last Run has returned.
+type Monitor interface {
+	// Name is the name of the monitor in `monitors` of config.
+	Name() string
+	// Config returns a pointer which the monitor's `config` block is decoded into, or nil when it takes no config.
+	// Fields left unset should be defaulted in Init.
+	Config() any
+	// Init sets up the monitor for the agent. An error fails startup, or rejects the config on reload.
+	Init(ctx context.Context, c *MonitorConfig, client *MonitorClient) error
+	// Run is given a context which is cancelled on shutdown.
+	// It should stop fetching new data then and flush what it has already collected.
+	Run(ctx context.Context) error
+	Close() error
+}
+
+// DefaultMonitor is implemented by a monitor which is useful only when something is configured for it.
+// Such a monitor is left out of the default monitors, which run when `monitors` is omitted, unless EnabledByDefault reports true.
+// It's called before the monitor's config is decoded, so it can only look at env. A monitor listed in `monitors` always runs.
+type DefaultMonitor interface {
+	EnabledByDefault() bool
+}
+
+// MonitorFunc is a monitor which keeps no state of its own. It's registered by RegisterMonitorFunc.
+type MonitorFunc func(ctx context.Context, c *MonitorConfig, rpcClient *MonitorClient) error
+
+// MonitorSpec is a monitor of an agent in config.
+//
+//	monitors:
+//	  - name: prometheus
+//	    interval: 30s
+//	    config:
+//	      ...
+type MonitorSpec struct {
+	Name     string         `yaml:"name"`
+	Interval *time.Duration `yaml:"interval"`
+	Jitter   *time.Duration `yaml:"jitter"`
+	// Config is the monitor's own config block, decoded into Config() of the monitor.
+	Config yaml.Node `yaml:"config"`
+}
+
+var (
+	monitorRegistryMu sync.RWMutex
+	monitorRegistry   = make(map[string]func() Monitor)
+)
+
+// RegisterMonitor makes the monitors made by newMonitor available by their name.
+// It panics when the name is already registered, same as database/sql.Register.
+func RegisterMonitor(newMonitor func() Monitor) {
+	name := newMonitor().Name()
+
+	monitorRegistryMu.Lock()
+	defer monitorRegistryMu.Unlock()
+	if _, exists := monitorRegistry[name]; exists {
+		panic("monitor already registered: " + name)
+	}
+	monitorRegistry[name] = newMonitor
+}
+
+// RegisterMonitorFunc registers a monitor which takes no config and needs no Init or Close.
+func RegisterMonitorFunc(name string, fn MonitorFunc) {
+	RegisterMonitor(func() Monitor {
+		return &funcMonitor{name: name, fn: fn}
+	})
+}
+
+// RegisteredMonitors returns names of every registered monitor in order.
+func RegisteredMonitors() []string {
+	monitorRegistryMu.RLock()
+	defer monitorRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(monitorRegistry))
+	for name := range monitorRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultMonitors returns names of the registered monitors which run when `monitors` is omitted, in order.
+func DefaultMonitors() []string {
+	var names []string
+	for _, name := range RegisteredMonitors() {
+		monitorRegistryMu.RLock()
+		monitor := monitorRegistry[name]()
+		monitorRegistryMu.RUnlock()
+
+		if defaultMonitor, ok := monitor.(DefaultMonitor); ok && !defaultMonitor.EnabledByDefault() {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// NewMonitor makes an instance of the monitor, with its config block decoded. It's yet to be Init-ed.
+func NewMonitor(spec MonitorSpec) (Monitor, error) {
+	monitorRegistryMu.RLock()
+	newMonitor, exists := monitorRegistry[spec.Name]
+	monitorRegistryMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown monitor: %s", spec.Name)
+	}
+
+	monitor := newMonitor()
+	if spec.Config.IsZero() {
+		return monitor, nil
+	}
+	config := monitor.Config()
+	if config == nil {
+		return nil, fmt.Errorf("monitor(%s) takes no config", spec.Name)
+	}
+	err := spec.Config.Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config of monitor(%s): %w", spec.Name, err)
+	}
+	return monitor, nil
+}
+
+// UnmarshalYAML rejects unknown monitors and invalid config blocks while config is being loaded.
+func (s *MonitorSpec) UnmarshalYAML(value *yaml.Node) error {
+	type plain MonitorSpec
+	err := value.Decode((*plain)(s))
+	if err != nil {
+		return err
+	}
+
+	_, err = NewMonitor(*s)
+	return err
+}
+
+type funcMonitor struct {
+	name   string
+	fn     MonitorFunc
+	c      *MonitorConfig
+	client *MonitorClient
+}
+
+func (m *funcMonitor) Name() string {
+	return m.name
+}
+
+func (m *funcMonitor) Config() any {
+	return nil
+}
+
+func (m *funcMonitor) Init(ctx context.Context, c *MonitorConfig, client *MonitorClient) error {
+	m.c, m.client = c, client
+	return nil
+}
+
+func (m *funcMonitor) Run(ctx context.Context) error {
+	return m.fn(ctx, m.c, m.client)
+}
+
+func (m *funcMonitor) Close() error {
+	return nil
+}
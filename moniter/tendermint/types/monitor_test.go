@@ -0,0 +1,126 @@
+package types
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"os"
+	"testing"
+	"time"
+)
+
+type thresholdMonitor struct {
+	config struct {
+		Threshold int    `yaml:"threshold"`
+		Label     string `yaml:"label"`
+	}
+}
+
+func (m *thresholdMonitor) Name() string { return "threshold" }
+func (m *thresholdMonitor) Config() any  { return &m.config }
+func (m *thresholdMonitor) Init(context.Context, *MonitorConfig, *MonitorClient) error {
+	if m.config.Label == "" {
+		m.config.Label = "default"
+	}
+	return nil
+}
+func (m *thresholdMonitor) Run(context.Context) error { return nil }
+func (m *thresholdMonitor) Close() error              { return nil }
+
+// optInMonitor runs by default only when $OPT_IN_ENABLED is set.
+type optInMonitor struct{}
+
+func (m *optInMonitor) Name() string                                               { return "opt_in" }
+func (m *optInMonitor) Config() any                                                { return nil }
+func (m *optInMonitor) Init(context.Context, *MonitorConfig, *MonitorClient) error { return nil }
+func (m *optInMonitor) Run(context.Context) error                                  { return nil }
+func (m *optInMonitor) Close() error                                               { return nil }
+func (m *optInMonitor) EnabledByDefault() bool                                     { return os.Getenv("OPT_IN_ENABLED") != "" }
+
+func TestMonitor(t *testing.T) {
+	defer func(registry map[string]func() Monitor) {
+		monitorRegistry = registry
+	}(monitorRegistry)
+	monitorRegistry = make(map[string]func() Monitor)
+
+	var ranWith *MonitorConfig
+	RegisterMonitor(func() Monitor { return &thresholdMonitor{} })
+	RegisterMonitorFunc("status", func(ctx context.Context, c *MonitorConfig, rpcClient *MonitorClient) error {
+		ranWith = c
+		return nil
+	})
+
+	t.Run("registered monitors", func(t *testing.T) {
+		assert.Equal(t, []string{"status", "threshold"}, RegisteredMonitors())
+		assert.Panics(t, func() {
+			RegisterMonitorFunc("status", nil)
+		})
+	})
+
+	t.Run("config block is decoded into the monitor", func(t *testing.T) {
+		var agent MonitoringAgent
+		err := yaml.Unmarshal([]byte(
+			"monitors:\n"+
+				"  - name: status\n"+
+				"    interval: 5s\n"+
+				"  - name: threshold\n"+
+				"    config:\n"+
+				"      threshold: 3\n"), &agent)
+		assert.NoError(t, err)
+		assert.Len(t, agent.Monitors, 2)
+		assert.Equal(t, 5*time.Second, *agent.Monitors[0].Interval)
+
+		mon, err := NewMonitor(agent.Monitors[1])
+		assert.NoError(t, err)
+		assert.NoError(t, mon.Init(context.Background(), &MonitorConfig{}, nil))
+		assert.Equal(t, 3, mon.(*thresholdMonitor).config.Threshold)
+		assert.Equal(t, "default", mon.(*thresholdMonitor).config.Label)
+
+		// Every instance has its own config.
+		other, err := NewMonitor(MonitorSpec{Name: "threshold"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, other.(*thresholdMonitor).config.Threshold)
+	})
+
+	t.Run("func monitor runs with the config it's initialized with", func(t *testing.T) {
+		mon, err := NewMonitor(MonitorSpec{Name: "status"})
+		assert.NoError(t, err)
+
+		c := &MonitorConfig{DbBatchSize: 7}
+		assert.NoError(t, mon.Init(context.Background(), c, nil))
+		assert.NoError(t, mon.Run(context.Background()))
+		assert.Same(t, c, ranWith)
+	})
+
+	t.Run("invalid monitors are rejected while loading", func(t *testing.T) {
+		var agent MonitoringAgent
+		assert.ErrorContains(t, yaml.Unmarshal([]byte("monitors:\n  - name: unknown\n"), &agent), "unknown monitor: unknown")
+		assert.ErrorContains(t, yaml.Unmarshal([]byte("monitors:\n  - name: status\n    config:\n      a: 1\n"), &agent), "takes no config")
+		assert.ErrorContains(t, yaml.Unmarshal([]byte("monitors:\n  - name: threshold\n    config:\n      threshold: many\n"), &agent), "invalid config of monitor(threshold)")
+	})
+
+	t.Run("default monitors are every registered one but opt-in ones", func(t *testing.T) {
+		RegisterMonitor(func() Monitor { return &optInMonitor{} })
+		t.Setenv(EnvMonitors, "")
+		t.Setenv("OPT_IN_ENABLED", "")
+		agent := MonitoringAgent{AgentName: "node-a", CommitId: "19ge4rgndfifji"}
+		assert.NoError(t, agent.ApplyConfigFromEnvAndDefault())
+		assert.Equal(t, []MonitorSpec{{Name: "status"}, {Name: "threshold"}}, agent.Monitors)
+
+		t.Setenv("OPT_IN_ENABLED", "true")
+		agent = MonitoringAgent{AgentName: "node-a", CommitId: "19ge4rgndfifji"}
+		assert.NoError(t, agent.ApplyConfigFromEnvAndDefault())
+		assert.Equal(t, []MonitorSpec{{Name: "opt_in"}, {Name: "status"}, {Name: "threshold"}}, agent.Monitors)
+
+		t.Setenv("OPT_IN_ENABLED", "")
+		t.Setenv(EnvMonitors, "opt_in")
+		agent = MonitoringAgent{AgentName: "node-a", CommitId: "19ge4rgndfifji"}
+		assert.NoError(t, agent.ApplyConfigFromEnvAndDefault())
+		assert.Equal(t, []MonitorSpec{{Name: "opt_in"}}, agent.Monitors)
+
+		t.Setenv(EnvMonitors, "threshold,unknown")
+		agent = MonitoringAgent{AgentName: "node-a", CommitId: "19ge4rgndfifji"}
+		assert.ErrorContains(t, agent.ApplyConfigFromEnvAndDefault(), "unknown monitor: unknown")
+	})
+
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ConnectionQuality is the peer connection's status of net_info in numbers.
+type ConnectionQuality struct {
+	// Duration is how long the connection has been up.
+	Duration  time.Duration
+	SendBytes int64
+	RecvBytes int64
+	// SendRate and RecvRate are the current transfer rates in bytes per second.
+	SendRate int64
+	RecvRate int64
+	// SendQueueSize and SendQueueCapacity are summed over the connection's channels.
+	SendQueueSize     int
+	SendQueueCapacity int
+	// SendQueueSaturation is the highest ratio of queued messages to the capacity among the channels.
+	SendQueueSaturation float64
+}
+
+// Quality parses the connection status, whose numbers are given as strings.
+func (s ConnectionStatus) Quality() (ConnectionQuality, error) {
+	var quality ConnectionQuality
+	duration, err := parseStatusInt("duration", s.Duration)
+	if err != nil {
+		return ConnectionQuality{}, err
+	}
+	quality.Duration = time.Duration(duration)
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *int64
+	}{
+		{"send bytes", s.SendMonitor.Bytes, &quality.SendBytes},
+		{"recv bytes", s.RecvMonitor.Bytes, &quality.RecvBytes},
+		{"send rate", s.SendMonitor.CurRate, &quality.SendRate},
+		{"recv rate", s.RecvMonitor.CurRate, &quality.RecvRate},
+	} {
+		*field.dest, err = parseStatusInt(field.name, field.value)
+		if err != nil {
+			return ConnectionQuality{}, err
+		}
+	}
+
+	for _, channel := range s.Channels {
+		size, err := parseStatusInt(fmt.Sprintf("send queue size of channel %#x", channel.ID), channel.SendQueueSize)
+		if err != nil {
+			return ConnectionQuality{}, err
+		}
+		capacity, err := parseStatusInt(fmt.Sprintf("send queue capacity of channel %#x", channel.ID), channel.SendQueueCapacity)
+		if err != nil {
+			return ConnectionQuality{}, err
+		}
+
+		quality.SendQueueSize += int(size)
+		quality.SendQueueCapacity += int(capacity)
+		if capacity > 0 {
+			quality.SendQueueSaturation = max(quality.SendQueueSaturation, float64(size)/float64(capacity))
+		}
+	}
+	return quality, nil
+}
+
+// parseStatusInt parses the number of the connection status. Empty one is taken as 0.
+func parseStatusInt(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse %s '%s' of the connection status: %w", name, value, err)
+	}
+	return n, nil
+}
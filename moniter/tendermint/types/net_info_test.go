@@ -0,0 +1,47 @@
+package types
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConnectionStatus(t *testing.T) {
+
+	t.Run("parses quality of the connection", func(t *testing.T) {
+		var peer Peer
+		assert.NoError(t, json.Unmarshal([]byte(`{
+  "connection_status": {
+    "Duration": "90000000000",
+    "SendMonitor": {"Bytes": "1048576", "CurRate": "2048", "AvgRate": "1000"},
+    "RecvMonitor": {"Bytes": "4194304", "CurRate": "8192", "AvgRate": "4000"},
+    "Channels": [
+      {"ID": 32, "SendQueueCapacity": "100", "SendQueueSize": "0", "Priority": "6", "RecentlySent": "0"},
+      {"ID": 48, "SendQueueCapacity": "100", "SendQueueSize": "75", "Priority": "10", "RecentlySent": "12"},
+      {"ID": 56, "SendQueueCapacity": "0", "SendQueueSize": "0", "Priority": "5", "RecentlySent": "0"}
+    ]
+  },
+  "remote_ip": "10.0.0.1"
+}`), &peer))
+
+		quality, err := peer.ConnectionStatus.Quality()
+		assert.NoError(t, err)
+		assert.Equal(t, ConnectionQuality{
+			Duration:            90 * time.Second,
+			SendBytes:           1048576,
+			RecvBytes:           4194304,
+			SendRate:            2048,
+			RecvRate:            8192,
+			SendQueueSize:       75,
+			SendQueueCapacity:   200,
+			SendQueueSaturation: 0.75,
+		}, quality)
+	})
+
+	t.Run("returns error with an invalid number", func(t *testing.T) {
+		_, err := ConnectionStatus{SendMonitor: Status{CurRate: "fast"}}.Quality()
+		assert.ErrorContains(t, err, "send rate")
+	})
+
+}
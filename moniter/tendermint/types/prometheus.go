@@ -0,0 +1,162 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusSample is a sample of a series in Prometheus text exposition format.
+type PrometheusSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// GetPrometheusMetrics scrapes the metrics endpoint of the node. (e.g. `http://127.0.0.1:26660/metrics`)
+// Unlike rpc requests, it doesn't fail over to other endpoints.
+func (r *MonitorClient) GetPrometheusMetrics(ctx context.Context, address string) ([]PrometheusSample, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := requestGet(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.New("Could not fetch prometheus metrics. address: " + address + ", err: " + err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, address: %s", res.StatusCode, address)
+	}
+
+	return ParsePrometheusText(res.Body)
+}
+
+// ParsePrometheusText parses samples from Prometheus text exposition format.
+// Comments(HELP, TYPE) and timestamps are ignored.
+func ParsePrometheusText(reader io.Reader) ([]PrometheusSample, error) {
+	var (
+		samples []PrometheusSample
+		scanner = bufio.NewScanner(reader)
+		lineNum int
+	)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, err := parsePrometheusLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// parsePrometheusLine parses `name{label="value",...} value [timestamp]`.
+func parsePrometheusLine(line string) (PrometheusSample, error) {
+	sample := PrometheusSample{Labels: map[string]string{}}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return sample, fmt.Errorf("could not parse metric name of '%s'", line)
+	}
+	sample.Name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		rest, err = parsePrometheusLabels(rest[1:], sample.Labels)
+		if err != nil {
+			return sample, fmt.Errorf("could not parse labels of '%s': %w", sample.Name, err)
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("no value found for '%s'", sample.Name)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("could not parse value of '%s': %w", sample.Name, err)
+	}
+	sample.Value = value
+
+	return sample, nil
+}
+
+// parsePrometheusLabels parses labels after `{` into labels and returns the rest after `}`.
+func parsePrometheusLabels(input string, labels map[string]string) (string, error) {
+	i := 0
+	for {
+		for i < len(input) && (input[i] == ' ' || input[i] == ',') {
+			i++
+		}
+		if i >= len(input) {
+			return "", errors.New("unexpected end of labels")
+		}
+		if input[i] == '}' {
+			return input[i+1:], nil
+		}
+
+		eq := strings.IndexByte(input[i:], '=')
+		if eq <= 0 {
+			return "", errors.New("label name not found")
+		}
+		name := strings.TrimSpace(input[i : i+eq])
+		i += eq + 1
+		if i >= len(input) || input[i] != '"' {
+			return "", fmt.Errorf("value of label '%s' is not quoted", name)
+		}
+		i++
+
+		var value strings.Builder
+		for ; ; i++ {
+			if i >= len(input) {
+				return "", fmt.Errorf("value of label '%s' is not closed", name)
+			}
+			c := input[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(input) {
+				i++
+				switch input[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(input[i])
+				}
+				continue
+			}
+			value.WriteByte(c)
+		}
+		labels[name] = value.String()
+	}
+}
+
+// IsFinite reports whether the sample can be stored as a number. (NaN, ±Inf can't)
+func (s PrometheusSample) IsFinite() bool {
+	return !math.IsNaN(s.Value) && !math.IsInf(s.Value, 0)
+}
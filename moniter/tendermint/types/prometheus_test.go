@@ -0,0 +1,51 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParsePrometheusText(t *testing.T) {
+
+	t.Run("parse samples with labels", func(t *testing.T) {
+		text := `# HELP cometbft_consensus_height Height of the chain.
+# TYPE cometbft_consensus_height gauge
+cometbft_consensus_height{chain_id="cosmoshub-4"} 21534110
+cometbft_p2p_peer_receive_bytes_total{chID="0x20",chain_id="cosmoshub-4",peer_id="abc"} 1.234e+06
+cometbft_mempool_size 12 1725148800000
+escaped{path="C:\\dir",msg="say \"hi\", {ok}"} 1
+
+process_start_time_seconds NaN
+`
+		samples, err := ParsePrometheusText(strings.NewReader(text))
+		assert.NoError(t, err)
+		assert.Len(t, samples, 5)
+
+		assert.Equal(t, "cometbft_consensus_height", samples[0].Name)
+		assert.Equal(t, map[string]string{"chain_id": "cosmoshub-4"}, samples[0].Labels)
+		assert.Equal(t, float64(21534110), samples[0].Value)
+
+		assert.Equal(t, "0x20", samples[1].Labels["chID"])
+		assert.Equal(t, 1.234e+06, samples[1].Value)
+
+		assert.Equal(t, float64(12), samples[2].Value)
+		assert.Empty(t, samples[2].Labels)
+
+		assert.Equal(t, `C:\dir`, samples[3].Labels["path"])
+		assert.Equal(t, `say "hi", {ok}`, samples[3].Labels["msg"])
+
+		assert.True(t, math.IsNaN(samples[4].Value))
+		assert.False(t, samples[4].IsFinite())
+	})
+
+	t.Run("malformed lines are rejected", func(t *testing.T) {
+		_, err := ParsePrometheusText(strings.NewReader(`broken{chain_id="x} 1`))
+		assert.Error(t, err)
+
+		_, err = ParsePrometheusText(strings.NewReader(`no_value`))
+		assert.Error(t, err)
+	})
+
+}
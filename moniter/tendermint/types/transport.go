@@ -0,0 +1,207 @@
+package types
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret is a credential given inline, or read from a file or an env when it's used.
+//
+//	bearerToken: "inline"
+//	bearerToken: {file: /var/run/secrets/rpc-token}
+//	bearerToken: {env: RPC_TOKEN}
+type Secret struct {
+	Value string `yaml:"value"`
+	File  string `yaml:"file"`
+	Env   string `yaml:"env"`
+}
+
+func (s *Secret) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		s.Value = value.Value
+		return nil
+	}
+	type plain Secret
+	return value.Decode((*plain)(s))
+}
+
+type EndpointAuth struct {
+	// BearerToken is sent as `Authorization: Bearer <token>`.
+	BearerToken *Secret `yaml:"bearerToken"`
+	// Username and Password are sent as basic auth.
+	Username string  `yaml:"username"`
+	Password *Secret `yaml:"password"`
+}
+
+type TLSConfig struct {
+	// CAFile is a bundle of CAs to trust in addition to the system's. (e.g. of a private proxy)
+	CAFile string `yaml:"caFile"`
+	// CertFile and KeyFile are the client certificate presented for mTLS.
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// secretReader reads the secret. Its file is read again only when it has been modified, e.g. a rotated token
+type secretReader struct {
+	secret Secret
+
+	mu      sync.Mutex
+	value   string
+	modTime time.Time
+}
+
+func (r *secretReader) read() (string, error) {
+	switch {
+	case r.secret.File != "":
+		stat, err := os.Stat(r.secret.File)
+		if err != nil {
+			return "", err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if !stat.ModTime().Equal(r.modTime) {
+			b, err := os.ReadFile(r.secret.File)
+			if err != nil {
+				return "", err
+			}
+			r.value = strings.TrimSpace(string(b))
+			r.modTime = stat.ModTime()
+		}
+		return r.value, nil
+	case r.secret.Env != "":
+		v := os.Getenv(r.secret.Env)
+		if v == "" {
+			return "", fmt.Errorf("env $%s is empty", r.secret.Env)
+		}
+		return v, nil
+	default:
+		return r.secret.Value, nil
+	}
+}
+
+// endpointTransport sends requests to an endpoint with its headers, auth and tls.
+type endpointTransport struct {
+	httpClient HttpClient
+	// tlsConfig is nil when the endpoint has no tls config of its own.
+	tlsConfig   *tls.Config
+	headers     map[string]*secretReader
+	bearerToken *secretReader
+	username    string
+	password    *secretReader
+}
+
+// newEndpointTransport returns the transport of the endpoint. Endpoints without tls config share httpClient.
+func newEndpointTransport(endpoint Endpoint, httpClient HttpClient) (*endpointTransport, error) {
+	t := &endpointTransport{httpClient: httpClient, headers: make(map[string]*secretReader)}
+	for name, secret := range endpoint.Headers {
+		t.headers[name] = &secretReader{secret: secret}
+	}
+	if auth := endpoint.Auth; auth != nil {
+		if auth.BearerToken != nil {
+			t.bearerToken = &secretReader{secret: *auth.BearerToken}
+		}
+		t.username = auth.Username
+		if auth.Password != nil {
+			t.password = &secretReader{secret: *auth.Password}
+		}
+	}
+
+	if endpoint.TLS != nil {
+		tlsConfig, err := newTLSConfig(endpoint.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("tls of endpoint %s: %w", endpoint.String(), err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		t.tlsConfig = tlsConfig
+		t.httpClient = &http.Client{Transport: transport}
+	}
+	return t, nil
+}
+
+func newTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// header returns headers to send, with the credentials read now.
+func (t *endpointTransport) header() (http.Header, error) {
+	header := make(http.Header)
+	for name, reader := range t.headers {
+		value, err := reader.read()
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		header.Set(name, value)
+	}
+
+	if t.bearerToken != nil {
+		token, err := t.bearerToken.read()
+		if err != nil {
+			return nil, fmt.Errorf("bearer token: %w", err)
+		}
+		header.Set("Authorization", "Bearer "+token)
+	} else if t.username != "" {
+		var password string
+		if t.password != nil {
+			var err error
+			password, err = t.password.read()
+			if err != nil {
+				return nil, fmt.Errorf("password: %w", err)
+			}
+		}
+		req := http.Request{Header: header}
+		req.SetBasicAuth(t.username, password)
+	}
+	return header, nil
+}
+
+// validateEndpoint checks the endpoint's scheme, auth and tls config.
+func validateEndpoint(endpoint Endpoint) error {
+	if endpoint.Scheme != "" && endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return fmt.Errorf("unknown scheme: %s. it should be `http` or `https`", endpoint.Scheme)
+	}
+	if auth := endpoint.Auth; auth != nil && auth.BearerToken != nil && auth.Username != "" {
+		return errors.New("bearerToken and username can't be used together")
+	}
+	if tlsConfig := endpoint.TLS; tlsConfig != nil && (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return errors.New("certFile and keyFile of tls must be set together")
+	}
+	return nil
+}
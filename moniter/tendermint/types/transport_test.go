@@ -0,0 +1,116 @@
+package types
+
+import (
+	"context"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEndpointTransport(t *testing.T) {
+
+	t.Run("builds url with the scheme", func(t *testing.T) {
+		assert.Equal(t, "http://10.0.4.43:26657/status", Endpoint{Host: "10.0.4.43", Port: 26657}.url(statusEndpoint))
+		assert.Equal(t, "http://rpc443.example.com:26657/status", Endpoint{Host: "rpc443.example.com", Port: 26657}.url(statusEndpoint))
+		assert.Equal(t, "https://rpc.example.com:443/status", Endpoint{Host: "rpc.example.com", Port: 443}.url(statusEndpoint))
+		assert.Equal(t, "https://rpc.example.com:8443/status", Endpoint{Host: "https://rpc.example.com", Port: 8443}.url(statusEndpoint))
+		assert.Equal(t, "https://rpc.example.com:26657/status", Endpoint{Host: "rpc.example.com", Port: 26657, Scheme: "https"}.url(statusEndpoint))
+	})
+
+	t.Run("sends headers and auth read from files and env", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		assert.NoError(t, os.WriteFile(tokenFile, []byte("first\n"), 0600))
+		t.Setenv("TEST_RPC_API_KEY", "api-key")
+
+		var authorizations []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "api-key", r.Header.Get("X-Api-Key"))
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"result":{}}`))
+		}))
+		defer server.Close()
+
+		endpoint := testEndpoint(t, server)
+		endpoint.Headers = map[string]Secret{"X-Api-Key": {Env: "TEST_RPC_API_KEY"}}
+		endpoint.Auth = &EndpointAuth{BearerToken: &Secret{File: tokenFile}}
+		transport, err := newEndpointTransport(endpoint, http.DefaultClient)
+		assert.NoError(t, err)
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{endpoint}),
+			transports: map[string]*endpointTransport{endpoint.String(): transport},
+			timeout:    time.Second,
+			retries:    1,
+		}
+
+		_, err = client.GetCometBFTStatus(context.Background())
+		assert.NoError(t, err)
+		// Rotated token is read again.
+		assert.NoError(t, os.WriteFile(tokenFile, []byte("second\n"), 0600))
+		assert.NoError(t, os.Chtimes(tokenFile, time.Now(), time.Now().Add(time.Minute)))
+		_, err = client.GetCometBFTStatus(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bearer first", "Bearer second"}, authorizations)
+
+		basicAuth, err := newEndpointTransport(Endpoint{Auth: &EndpointAuth{Username: "user", Password: &Secret{Value: "pass"}}}, http.DefaultClient)
+		assert.NoError(t, err)
+		header, err := basicAuth.header()
+		assert.NoError(t, err)
+		assert.Equal(t, "Basic dXNlcjpwYXNz", header.Get("Authorization"))
+	})
+
+	t.Run("trusts the ca of tls", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"result":{}}`))
+		}))
+		defer server.Close()
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		assert.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+		endpoint := testEndpoint(t, server)
+		endpoint.Scheme = "https"
+		untrusted := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{endpoint}),
+			timeout:    time.Second,
+			retries:    1,
+		}
+		_, err := untrusted.GetCometBFTStatus(context.Background())
+		assert.Error(t, err)
+
+		endpoint.TLS = &TLSConfig{CAFile: caFile}
+		transport, err := newEndpointTransport(endpoint, http.DefaultClient)
+		assert.NoError(t, err)
+		client := &MonitorClient{
+			httpClient: http.DefaultClient,
+			endpoints:  newEndpointPool([]Endpoint{endpoint}),
+			transports: map[string]*endpointTransport{endpoint.String(): transport},
+			timeout:    time.Second,
+			retries:    1,
+		}
+		_, err = client.GetCometBFTStatus(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("reads secret from a scalar or a mapping", func(t *testing.T) {
+		var auth EndpointAuth
+		assert.NoError(t, yaml.Unmarshal([]byte("bearerToken: inline\npassword: {file: /run/secrets/password}\n"), &auth))
+		assert.Equal(t, &Secret{Value: "inline"}, auth.BearerToken)
+		assert.Equal(t, &Secret{File: "/run/secrets/password"}, auth.Password)
+	})
+
+	t.Run("rejects invalid endpoint config", func(t *testing.T) {
+		assert.Error(t, validateEndpoint(Endpoint{Host: "rpc", Scheme: "ws"}))
+		assert.Error(t, validateEndpoint(Endpoint{Host: "rpc", Auth: &EndpointAuth{BearerToken: &Secret{Value: "token"}, Username: "user"}}))
+		assert.Error(t, validateEndpoint(Endpoint{Host: "rpc", TLS: &TLSConfig{CertFile: "cert.pem"}}))
+		assert.NoError(t, validateEndpoint(Endpoint{Host: "rpc", Scheme: "https", TLS: &TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}}))
+	})
+
+}
@@ -1,28 +1,17 @@
 package types
 
 import (
+	"fmt"
 	"time"
 )
 
-type Monitor interface {
-	Run(c *MonitorConfig, rpcClient *MonitorClient)
-}
-
-type Func struct {
-	MonitorFunc `yaml:"name"`
-	Interval    *time.Duration `yaml:"interval"`
-}
-
-type MonitorFunc func(c *MonitorConfig, rpcClient *MonitorClient)
-
-func (f Func) Run(c *MonitorConfig, rpcClient *MonitorClient) {
-	f.MonitorFunc(c, rpcClient)
-}
-
 type CometBFTStatusResult struct {
 	Result  ResultStatus `json:"result"`
 	ID      int64        `json:"id"`
 	Jsonrpc string       `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
 }
 
 // Node Status
@@ -90,6 +79,9 @@ type CometBFTNetInfoResult struct {
 	Result  ResultNetInfo `json:"result"`
 	ID      int64         `json:"id"`
 	Jsonrpc string        `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
 }
 
 type ResultNetInfo struct {
@@ -143,6 +135,9 @@ type CometBFTCommitResult struct {
 	Result  ResultCommit `json:"result"`
 	ID      int64        `json:"id"`
 	Jsonrpc string       `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
 }
 
 type ResultCommit struct {
@@ -218,3 +213,163 @@ type CommitSig struct {
 	Timestamp        time.Time `json:"timestamp"`
 	Signature        string    `json:"signature"`
 }
+
+// RPCError is the error of a JSON-RPC response.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error. code: %d, message: %s, data: %s", e.Code, e.Message, e.Data)
+}
+
+type CometBFTBlockchainResult struct {
+	Result  ResultBlockchain `json:"result"`
+	Error   *RPCError        `json:"error"`
+	ID      int64            `json:"id"`
+	Jsonrpc string           `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+type ResultBlockchain struct {
+	LastHeight string      `json:"last_height"`
+	BlockMetas []BlockMeta `json:"block_metas"`
+}
+
+type BlockMeta struct {
+	BlockID   BlockID `json:"block_id"`
+	BlockSize string  `json:"block_size"`
+	Header    Header  `json:"header"`
+	NumTxs    string  `json:"num_txs"`
+}
+
+type CometBFTBlockSearchResult struct {
+	Result  ResultBlockSearch `json:"result"`
+	Error   *RPCError         `json:"error"`
+	ID      int64             `json:"id"`
+	Jsonrpc string            `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+type ResultBlockSearch struct {
+	Blocks     []ResultBlock `json:"blocks"`
+	TotalCount string        `json:"total_count"`
+}
+
+type ResultBlock struct {
+	BlockID BlockID `json:"block_id"`
+	Block   Block   `json:"block"`
+}
+
+type CometBFTValidatorsResult struct {
+	Result  ResultValidators `json:"result"`
+	ID      int64            `json:"id"`
+	Jsonrpc string           `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+// Validators for a height.
+type ResultValidators struct {
+	BlockHeight string      `json:"block_height"`
+	Validators  []Validator `json:"validators"`
+	// Count of validators in this page.
+	Count string `json:"count"`
+	// Total number of validators.
+	Total string `json:"total"`
+}
+
+type Validator struct {
+	Address          string `json:"address"`
+	PubKey           PubKey `json:"pub_key"`
+	VotingPower      string `json:"voting_power"`
+	ProposerPriority string `json:"proposer_priority"`
+}
+
+type PubKey struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type CometBFTUnconfirmedTxsResult struct {
+	Result  ResultUnconfirmedTxs `json:"result"`
+	ID      int64                `json:"id"`
+	Jsonrpc string               `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+// List of mempool txs
+type ResultUnconfirmedTxs struct {
+	Count      string `json:"n_txs"`
+	Total      string `json:"total"`
+	TotalBytes string `json:"total_bytes"`
+}
+
+type CometBFTAbciInfoResult struct {
+	Result  ResultABCIInfo `json:"result"`
+	ID      int64          `json:"id"`
+	Jsonrpc string         `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+// Info abci msg
+type ResultABCIInfo struct {
+	Response ResponseInfo `json:"response"`
+}
+
+type ResponseInfo struct {
+	Data       string `json:"data"`
+	Version    string `json:"version"`
+	AppVersion string `json:"app_version"`
+	// LastBlockHeight may be omitted before the first block.
+	LastBlockHeight  string `json:"last_block_height"`
+	LastBlockAppHash []byte `json:"last_block_app_hash"`
+}
+
+type CometBFTBlockResultsResult struct {
+	Result  ResultBlockResults `json:"result"`
+	ID      int64              `json:"id"`
+	Jsonrpc string             `json:"jsonrpc"`
+
+	// Endpoint is the rpc endpoint which served this result.
+	Endpoint string `json:"-"`
+}
+
+// ABCI results from a block.
+// BeginBlockEvents and EndBlockEvents are replaced with FinalizeBlockEvents since CometBFT v0.38.
+type ResultBlockResults struct {
+	Height              string         `json:"height"`
+	TxsResults          []ExecTxResult `json:"txs_results"`
+	BeginBlockEvents    []ABCIEvent    `json:"begin_block_events"`
+	EndBlockEvents      []ABCIEvent    `json:"end_block_events"`
+	FinalizeBlockEvents []ABCIEvent    `json:"finalize_block_events"`
+}
+
+type ExecTxResult struct {
+	Code      uint32 `json:"code"`
+	GasWanted string `json:"gas_wanted"`
+	GasUsed   string `json:"gas_used"`
+	Codespace string `json:"codespace"`
+}
+
+type ABCIEvent struct {
+	Type       string               `json:"type"`
+	Attributes []ABCIEventAttribute `json:"attributes"`
+}
+
+type ABCIEventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Index bool   `json:"index"`
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/b-harvest/Harvestmon/log"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	websocketEndpoint = "/websocket"
+	newBlockQuery     = "tm.event='NewBlock'"
+	// websocketReadTimeout is how long the subscription waits for any message before it's considered dead.
+	// CometBFT pings subscribers every ~27s, so a healthy connection never stays silent this long.
+	websocketReadTimeout = time.Minute
+)
+
+// Block is a block delivered by NewBlock event. Only the parts used by monitors are decoded.
+type Block struct {
+	Header Header `json:"header"`
+	// LastCommit is the commit of previous height(Header.Height - 1).
+	LastCommit *Commit `json:"last_commit"`
+}
+
+type NewBlockEvent struct {
+	Block Block
+
+	// Endpoint is the rpc endpoint which delivered this event.
+	Endpoint string
+}
+
+type websocketRequest struct {
+	Jsonrpc string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	ID      int64             `json:"id"`
+	Params  map[string]string `json:"params"`
+}
+
+type websocketResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+type newBlockEventResult struct {
+	Query string `json:"query"`
+	Data  struct {
+		Type  string `json:"type"`
+		Value struct {
+			Block *Block `json:"block"`
+		} `json:"value"`
+	} `json:"data"`
+}
+
+// SubscribeNewBlock subscribes NewBlock events from the healthiest endpoint and calls handle for every block.
+// It blocks until ctx is done, the connection is lost or handle returns an error.
+// The returned error is nil only when ctx is done.
+func (r *MonitorClient) SubscribeNewBlock(ctx context.Context, handle func(event *NewBlockEvent) error) error {
+	conn, endpoint, err := r.dialWebsocket(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock ReadMessage below when ctx is done.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	err = conn.WriteJSON(websocketRequest{
+		Jsonrpc: "2.0",
+		Method:  "subscribe",
+		ID:      1,
+		Params:  map[string]string{"query": newBlockQuery},
+	})
+	if err != nil {
+		return errors.New("failed to subscribe. endpoint: " + endpoint.String() + ", err: " + err.Error())
+	}
+	log.Info("[websocket] subscribed " + newBlockQuery + ". endpoint: " + endpoint.String())
+
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(websocketReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(r.timeout))
+	})
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(websocketReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			r.endpoints.report(endpoint, false)
+			return errors.New("websocket connection lost. endpoint: " + endpoint.String() + ", err: " + err.Error())
+		}
+
+		var response websocketResponse
+		err = json.Unmarshal(message, &response)
+		if err != nil {
+			return errors.New("Json marshaling error: " + err.Error())
+		}
+		if response.Error != nil {
+			return fmt.Errorf("subscription error. endpoint: %s, code: %d, message: %s, data: %s", endpoint.String(), response.Error.Code, response.Error.Message, response.Error.Data)
+		}
+
+		var result newBlockEventResult
+		err = json.Unmarshal(response.Result, &result)
+		if err != nil {
+			return errors.New("Json marshaling error: " + err.Error())
+		}
+		// The first response only acknowledges the subscription.
+		if result.Data.Value.Block == nil {
+			continue
+		}
+
+		err = handle(&NewBlockEvent{Block: *result.Data.Value.Block, Endpoint: endpoint.String()})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dialWebsocket connects to the first endpoint which accepts the websocket handshake, in order of health.
+func (r *MonitorClient) dialWebsocket(ctx context.Context) (*websocket.Conn, Endpoint, error) {
+	var (
+		errMsg string
+		dialer = websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: r.timeout,
+		}
+	)
+
+	candidates := r.endpoints.candidates()
+	if len(candidates) == 0 {
+		return nil, Endpoint{}, errors.New("no rpc endpoint configured")
+	}
+
+	for _, endpoint := range candidates {
+		var (
+			endpointDialer = dialer
+			header         http.Header
+			conn           *websocket.Conn
+			err            error
+		)
+		if transport, exists := r.transports[endpoint.String()]; exists {
+			endpointDialer.TLSClientConfig = transport.tlsConfig
+			header, err = transport.header()
+		}
+		if err == nil {
+			conn, _, err = endpointDialer.DialContext(ctx, getWebsocketAddress(endpoint), header)
+		}
+		if ctx.Err() != nil {
+			return nil, Endpoint{}, ctx.Err()
+		}
+		r.endpoints.report(endpoint, err == nil)
+		if err != nil {
+			errMsg = "could not connect websocket. err: " + err.Error() + ", endpoint: " + endpoint.String()
+			log.Warn(errMsg)
+			continue
+		}
+		return conn, endpoint, nil
+	}
+
+	return nil, Endpoint{}, errors.New(errMsg)
+}
+
+func getWebsocketAddress(endpoint Endpoint) string {
+	// http:// -> ws://, https:// -> wss://
+	return "ws" + strings.TrimPrefix(endpoint.url(websocketEndpoint), "http")
+}
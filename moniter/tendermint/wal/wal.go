@@ -0,0 +1,409 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	logFileName    = "queue.log"
+	offsetFileName = "queue.offset"
+)
+
+// ErrFull is returned by Append when the pending records would exceed the queue's size limit.
+var ErrFull = errors.New("wal queue is full")
+
+// Record is a buffered write. Name is of the writer which replays Payload.
+type Record struct {
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Stats describes the queue. Pending records are the ones not replayed yet.
+type Stats struct {
+	PendingRecords int
+	PendingBytes   int64
+	Appended       uint64
+	Replayed       uint64
+	// Dropped is how many records were rejected because the queue was full.
+	Dropped uint64
+}
+
+// Queue is a durable FIFO of writes which failed to reach the database.
+// Records are appended as json lines into a log file, and the offset of the first pending record is kept in another file,
+// so pending records survive restarts and are replayed in the order they were appended.
+// Replayed records are compacted away by copying the pending ones into a log file of the next generation.
+type Queue struct {
+	dir      string
+	maxBytes int64
+
+	mu         sync.Mutex
+	file       *os.File
+	generation int64
+	size       int64
+	offset     int64
+	pending    int
+	stats      Stats
+
+	// replaying serializes Replay. Append doesn't wait for it.
+	replaying sync.Mutex
+}
+
+// Open opens the queue in dir, creating it if it doesn't exist.
+// maxBytes limits the size of pending records. 0 means no limit.
+func Open(dir string, maxBytes int64) (*Queue, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Queue{dir: dir, maxBytes: maxBytes}
+
+	q.offset, q.generation, err = q.readOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	q.file, err = os.OpenFile(q.logPath(q.generation), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.recover()
+	if err != nil {
+		q.file.Close()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// logPath returns the path of the log file of generation. The first generation is kept as queue.log.
+func (q *Queue) logPath(generation int64) string {
+	if generation == 0 {
+		return filepath.Join(q.dir, logFileName)
+	}
+	return filepath.Join(q.dir, fmt.Sprintf("%s.%d", logFileName, generation))
+}
+
+// recover counts pending records and truncates a record torn by a crash while it was being appended.
+func (q *Queue) recover() error {
+	info, err := q.file.Stat()
+	if err != nil {
+		return err
+	}
+	if q.offset > info.Size() {
+		q.offset = info.Size()
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(q.file, q.offset, info.Size()-q.offset))
+	end := q.offset
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		end += int64(len(line))
+		q.pending++
+	}
+
+	if end != info.Size() {
+		err = q.file.Truncate(end)
+		if err != nil {
+			return err
+		}
+	}
+	q.size = end
+	q.stats.PendingRecords = q.pending
+	q.stats.PendingBytes = q.size - q.offset
+
+	// Log files of the other generations are left behind by a crash while compacting.
+	logs, err := filepath.Glob(filepath.Join(q.dir, logFileName+"*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range logs {
+		if path != q.logPath(q.generation) {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// Append durably appends a record. It returns ErrFull when the size limit would be exceeded.
+func (q *Queue) Append(name string, payload []byte) error {
+	line, err := json.Marshal(Record{Name: name, Payload: payload})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxBytes > 0 && q.size-q.offset+int64(len(line)) > q.maxBytes {
+		q.stats.Dropped++
+		return ErrFull
+	}
+
+	_, err = q.file.WriteAt(line, q.size)
+	if err != nil {
+		// Don't leave a torn record behind.
+		q.file.Truncate(q.size)
+		return err
+	}
+	err = q.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	q.size += int64(len(line))
+	q.pending++
+	q.stats.Appended++
+	q.stats.PendingRecords = q.pending
+	q.stats.PendingBytes = q.size - q.offset
+	return nil
+}
+
+// Replay passes pending records to apply in order. A record is removed from the queue when apply returns nil.
+// It stops at the first error of apply, leaving the record and the ones after it for the next Replay.
+// It returns how many records were replayed.
+func (q *Queue) Replay(ctx context.Context, apply func(record Record) error) (int, error) {
+	q.replaying.Lock()
+	defer q.replaying.Unlock()
+
+	var replayed int
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		record, length, err := q.readAt(q.firstOffset())
+		if err != nil {
+			return replayed, err
+		}
+		if length == 0 {
+			return replayed, nil
+		}
+
+		err = apply(record)
+		if err != nil {
+			return replayed, err
+		}
+
+		err = q.advance(length)
+		if err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+}
+
+// ReplayBatch is Replay passing up to batchSize pending records to apply at a time.
+// apply returns how many of the records, from the first, have been applied. They're removed from the queue even when it returns an error.
+func (q *Queue) ReplayBatch(ctx context.Context, batchSize int, apply func(records []Record) (int, error)) (int, error) {
+	q.replaying.Lock()
+	defer q.replaying.Unlock()
+
+	var replayed int
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		var (
+			records []Record
+			lengths []int64
+			offset  = q.firstOffset()
+		)
+		for len(records) < batchSize {
+			record, length, err := q.readAt(offset)
+			if err != nil {
+				return replayed, err
+			}
+			if length == 0 {
+				break
+			}
+			records = append(records, record)
+			lengths = append(lengths, length)
+			offset += length
+		}
+		if len(records) == 0 {
+			return replayed, nil
+		}
+
+		applied, applyErr := apply(records)
+		for _, length := range lengths[:min(applied, len(lengths))] {
+			err := q.advance(length)
+			if err != nil {
+				return replayed, err
+			}
+			replayed++
+		}
+		if applyErr != nil {
+			return replayed, applyErr
+		}
+	}
+}
+
+func (q *Queue) firstOffset() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.offset
+}
+
+// readAt reads the pending record at offset. length is 0 when there is no pending record there.
+func (q *Queue) readAt(offset int64) (Record, int64, error) {
+	q.mu.Lock()
+	file, size := q.file, q.size
+	q.mu.Unlock()
+
+	if offset >= size {
+		return Record{}, 0, nil
+	}
+
+	line, err := bufio.NewReader(io.NewSectionReader(file, offset, size-offset)).ReadBytes('\n')
+	if err != nil {
+		return Record{}, 0, err
+	}
+
+	var record Record
+	err = json.Unmarshal(bytes.TrimSuffix(line, []byte{'\n'}), &record)
+	if err != nil {
+		return Record{}, 0, fmt.Errorf("corrupted wal record at offset %d: %w", offset, err)
+	}
+	return record, int64(len(line)), nil
+}
+
+// advance removes the first pending record. The log file is truncated once every record has been replayed,
+// and compacted once replayed records take as much of it as pending ones, so it stays within twice the pending bytes.
+func (q *Queue) advance(length int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.offset += length
+	q.pending--
+	q.stats.Replayed++
+
+	var err error
+	switch {
+	case q.offset >= q.size:
+		err = q.file.Truncate(0)
+		if err != nil {
+			return err
+		}
+		q.offset, q.size = 0, 0
+		err = q.writeOffset(q.offset, q.generation)
+	case q.offset >= q.size-q.offset:
+		err = q.compact()
+	default:
+		err = q.writeOffset(q.offset, q.generation)
+	}
+
+	q.stats.PendingRecords = q.pending
+	q.stats.PendingBytes = q.size - q.offset
+	return err
+}
+
+// compact copies the pending records into the log file of the next generation, which the offset file is switched to.
+// A crash leaves either the old log and offset or the new ones, and the unused log file is removed by recover.
+func (q *Queue) compact() error {
+	path := q.logPath(q.generation + 1)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, io.NewSectionReader(q.file, q.offset, q.size-q.offset))
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = q.writeOffset(0, q.generation+1)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		// Keep replaying from the old log file.
+		return errors.Join(err, q.writeOffset(q.offset, q.generation))
+	}
+
+	q.file.Close()
+	os.Remove(q.logPath(q.generation))
+	q.file = file
+	q.generation++
+	q.size -= q.offset
+	q.offset = 0
+	return nil
+}
+
+// Stats returns a snapshot of the queue's stats.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// Len returns how many records are pending.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending
+}
+
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+// readOffset reads the offset of the first pending record and the generation of the log file it is in.
+// An offset file without generation is of the first one.
+func (q *Queue) readOffset() (offset int64, generation int64, err error) {
+	b, err := os.ReadFile(filepath.Join(q.dir, offsetFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, fmt.Errorf("corrupted wal offset: %q", b)
+	}
+	offset, err = strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("corrupted wal offset: %w", err)
+	}
+	if len(fields) == 2 {
+		generation, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("corrupted wal generation: %w", err)
+		}
+	}
+	return offset, generation, nil
+}
+
+// writeOffset replaces the offset file atomically, so a crash leaves either the old or the new offset.
+func (q *Queue) writeOffset(offset, generation int64) error {
+	path := filepath.Join(q.dir, offsetFileName)
+	err := os.WriteFile(path+".tmp", []byte(fmt.Sprintf("%d %d", offset, generation)), 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
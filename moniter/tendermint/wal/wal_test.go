@@ -0,0 +1,201 @@
+package wal
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+
+	t.Run("replay in order", func(t *testing.T) {
+		q, err := Open(t.TempDir(), 0)
+		assert.NoError(t, err)
+		defer q.Close()
+
+		assert.NoError(t, q.Append("status", []byte(`{"height":1}`)))
+		assert.NoError(t, q.Append("mempool", []byte(`{"height":2}`)))
+		assert.Equal(t, 2, q.Len())
+
+		var names []string
+		replayed, err := q.Replay(context.Background(), func(record Record) error {
+			names = append(names, record.Name)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, replayed)
+		assert.Equal(t, []string{"status", "mempool"}, names)
+		assert.Equal(t, 0, q.Len())
+		assert.Equal(t, int64(0), q.Stats().PendingBytes)
+	})
+
+	t.Run("stop at failure and resume after reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := Open(dir, 0)
+		assert.NoError(t, err)
+
+		for _, name := range []string{"a", "b", "c"} {
+			assert.NoError(t, q.Append(name, []byte(`{}`)))
+		}
+
+		replayed, err := q.Replay(context.Background(), func(record Record) error {
+			if record.Name == "b" {
+				return errors.New("database is unreachable")
+			}
+			return nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, replayed)
+		assert.NoError(t, q.Close())
+
+		q, err = Open(dir, 0)
+		assert.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, 2, q.Len())
+
+		var names []string
+		_, err = q.Replay(context.Background(), func(record Record) error {
+			names = append(names, record.Name)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, names)
+	})
+
+	t.Run("replay in batches", func(t *testing.T) {
+		q, err := Open(t.TempDir(), 0)
+		assert.NoError(t, err)
+		defer q.Close()
+
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			assert.NoError(t, q.Append(name, []byte(`{}`)))
+		}
+
+		var batches [][]string
+		replayed, err := q.ReplayBatch(context.Background(), 2, func(records []Record) (int, error) {
+			var names []string
+			for _, record := range records {
+				names = append(names, record.Name)
+			}
+			batches = append(batches, names)
+			// Only the first record of the batch starting with `c` is applied.
+			if names[0] == "c" {
+				return 1, errors.New("collector is unreachable")
+			}
+			return len(records), nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 3, replayed)
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, batches)
+		assert.Equal(t, 2, q.Len())
+
+		batches = nil
+		replayed, err = q.ReplayBatch(context.Background(), 2, func(records []Record) (int, error) {
+			batches = append(batches, []string{records[0].Name, records[len(records)-1].Name})
+			return len(records), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, replayed)
+		assert.Equal(t, [][]string{{"d", "e"}}, batches)
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		q, err := Open(t.TempDir(), 64)
+		assert.NoError(t, err)
+		defer q.Close()
+
+		assert.NoError(t, q.Append("a", []byte(`{"v":1}`)))
+		assert.ErrorIs(t, q.Append("a", []byte(`{"v":"too large to fit into the queue"}`)), ErrFull)
+		assert.Equal(t, uint64(1), q.Stats().Dropped)
+		assert.Equal(t, 1, q.Len())
+	})
+
+	t.Run("compact replayed records", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := Open(dir, 0)
+		assert.NoError(t, err)
+
+		for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			assert.NoError(t, q.Append(name, []byte(`{}`)))
+		}
+		recordBytes := q.Stats().PendingBytes / 8
+
+		replayed, err := q.ReplayBatch(context.Background(), 2, func(records []Record) (int, error) {
+			if records[0].Name == "g" {
+				return 0, errors.New("collector is unreachable")
+			}
+			return len(records), nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 6, replayed)
+
+		// Only the pending records are left in the log file.
+		logs, err := filepath.Glob(filepath.Join(dir, logFileName+"*"))
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+		info, err := os.Stat(logs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, 2*recordBytes, info.Size())
+		assert.NoError(t, q.Close())
+
+		q, err = Open(dir, 0)
+		assert.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, 2, q.Len())
+
+		var names []string
+		_, err = q.Replay(context.Background(), func(record Record) error {
+			names = append(names, record.Name)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"g", "h"}, names)
+	})
+
+	t.Run("remove log file left by crash while compacting", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := Open(dir, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, q.Append("a", []byte(`{}`)))
+		assert.NoError(t, q.Close())
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, logFileName+".1"), []byte(`{"name":"a","pay`), 0o644))
+
+		q, err = Open(dir, 0)
+		assert.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, 1, q.Len())
+		assert.NoFileExists(t, filepath.Join(dir, logFileName+".1"))
+	})
+
+	t.Run("truncate torn record", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := Open(dir, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, q.Append("a", []byte(`{}`)))
+		assert.NoError(t, q.Close())
+
+		f, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(`{"name":"b","payl`))
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+
+		q, err = Open(dir, 0)
+		assert.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, 1, q.Len())
+
+		assert.NoError(t, q.Append("c", []byte(`{}`)))
+		var names []string
+		_, err = q.Replay(context.Background(), func(record Record) error {
+			names = append(names, record.Name)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, names)
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+type TendermintAbciInfo struct {
+	CreatedAt        time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event            Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID        string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	AppName          string    `gorm:"column:app_name;not null;type:varchar(100)"`
+	Version          string    `gorm:"column:version;not null;type:varchar(100)"`
+	AppVersion       uint64    `gorm:"column:app_version;not null;type:bigint"`
+	LastBlockHeight  uint64    `gorm:"column:last_block_height;not null;type:bigint"`
+	LastBlockAppHash string    `gorm:"column:last_block_app_hash;not null;type:varchar(100)"`
+}
+
+func (TendermintAbciInfo) TableName() string {
+	return "tendermint_abci_info"
+}
+
+type AbciInfoRepository struct {
+	BaseRepository
+}
+
+func (r *AbciInfoRepository) Save(abciInfo TendermintAbciInfo) error {
+	eventAssociation := r.DB.Model(&abciInfo).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&abciInfo.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&abciInfo)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `tendermint_abci_info` successfully. eventUUID: " + abciInfo.Event.EventUUID)
+
+	return nil
+}
+
+// FindLatestAbciInfos returns the agent's latest abci infos up to limit, latest first.
+func (r *AbciInfoRepository) FindLatestAbciInfos(limit int, agentName, serviceName string) ([]TendermintAbciInfo, error) {
+	var result []TendermintAbciInfo
+
+	err := r.DB.Raw(`SELECT
+    tai.*
+FROM
+    event e
+        JOIN
+    tendermint_abci_info tai ON e.event_uuid = tai.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:abci_info'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY tai.created_at DESC
+LIMIT ?;
+`, serviceName, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -5,22 +5,55 @@ import (
 	"fmt"
 	"github.com/b-harvest/Harvestmon/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
 type Agent struct {
-	AgentName string `gorm:"column:agent_name;not null;type:varchar(100)"`
-	CommitID  string `gorm:"column:commit_id;not null;type:varchar(255)"`
-	Host      string `gorm:"column:host;not null;type:varchar(30)"`
+	AgentName string `gorm:"primaryKey;column:agent_name;not null;type:varchar(100)"`
+	CommitID  string `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	Host      string `gorm:"column:host;not null;type:varchar(255)"`
 	Port      int    `gorm:"column:port;null;type:int"`
 	Platform  string `gorm:"column:platform;null;type:varchar(255)"`
 	Location  string `gorm:"column:location;null;type:varchar(255)"`
+	// Active is false once the agent's monitor has deregistered it on shutdown.
+	Active bool `gorm:"column:active;not null;type:boolean;default:true"`
 }
 
 func (Agent) TableName() string {
 	return "agent"
 }
 
+type CommitRecord struct {
+	CommitID  string     `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	CreatedAt *time.Time `gorm:"column:created_at;null;type:datetime(6);autoCreateTime:false"`
+}
+
+func (CommitRecord) TableName() string {
+	return "commit_record"
+}
+
+type Service struct {
+	ServiceName  string  `gorm:"primaryKey;column:service_name;not null;type:varchar(100)"`
+	CommitID     string  `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	MonitorImage *string `gorm:"column:monitor_image;null;type:varchar(255)"`
+	CheckerImage *string `gorm:"column:checker_image;null;type:varchar(255)"`
+}
+
+func (Service) TableName() string {
+	return "service"
+}
+
+type AgentService struct {
+	AgentName   string `gorm:"primaryKey;column:agent_name;not null;type:varchar(100)"`
+	ServiceName string `gorm:"primaryKey;column:service_name;not null;type:varchar(100)"`
+	CommitID    string `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+}
+
+func (AgentService) TableName() string {
+	return "agent_service"
+}
+
 type AgentMark struct {
 	AgentName          string     `gorm:"column:agent_name;not null;type:varchar(100)"`
 	MarkStart          *time.Time `gorm:"column:mark_start;not null;type:datetime(6);autoCreateTime:false"`
@@ -70,6 +103,52 @@ where commit_id = ?`, r.CommitId).Scan(&result).Error
 	return result, nil
 }
 
+// Register upserts the agent and the rows its events refer to. (`commit_record`, `service`, `agent_service`)
+// Metadata of an existing agent is refreshed and it's marked active again.
+func (r *AgentRepository) Register(agent Agent, serviceName string) error {
+	agent.CommitID = r.CommitId
+	agent.Active = true
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CommitRecord{CommitID: r.CommitId, CreatedAt: &now}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Service{ServiceName: serviceName, CommitID: r.CommitId}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"host", "port", "platform", "location", "active"})}).Create(&agent).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&AgentService{AgentName: agent.AgentName, ServiceName: serviceName, CommitID: r.CommitId}).Error
+		if err != nil {
+			return err
+		}
+
+		log.Debug("Registered `agent`, `agent_service` successfully. agentName: " + agent.AgentName)
+		return nil
+	})
+}
+
+// Deactivate marks the agent inactive. Its rows are kept, since events refer to them.
+func (r *AgentRepository) Deactivate(agentName string) error {
+	res := r.DB.Model(&Agent{}).
+		Where("agent_name = ? and commit_id = ?", agentName, r.CommitId).
+		Update("active", false)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Deactivated `agent`. agentName: " + agentName)
+	return nil
+}
+
 type AgentMarkRepository struct {
 	BaseRepository
 }
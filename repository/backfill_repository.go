@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+)
+
+// BlockCommitBackfill is the progress of a block commit backfill of a height range.
+type BlockCommitBackfill struct {
+	AgentName   string `gorm:"primaryKey;column:agent_name;not null;type:varchar(100)"`
+	CommitID    string `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	StartHeight uint64 `gorm:"primaryKey;column:start_height;not null;type:bigint"`
+	EndHeight   uint64 `gorm:"primaryKey;column:end_height;not null;type:bigint"`
+	// NextHeight is the height to resume from. Heights below it have been stored.
+	NextHeight uint64    `gorm:"column:next_height;not null;type:bigint"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;not null;type:datetime(6);autoUpdateTime:false"`
+}
+
+func (BlockCommitBackfill) TableName() string {
+	return "block_commit_backfill"
+}
+
+type BackfillRepository struct {
+	BaseRepository
+}
+
+// FindBlockCommitBackfill returns the progress of the range. It returns nil when the range has never been backfilled.
+func (r *BackfillRepository) FindBlockCommitBackfill(agentName string, startHeight, endHeight uint64) (*BlockCommitBackfill, error) {
+	var result []BlockCommitBackfill
+
+	err := r.DB.Raw(`SELECT *
+FROM block_commit_backfill bcb
+WHERE bcb.agent_name = ?
+  and bcb.commit_id = ?
+  and bcb.start_height = ?
+  and bcb.end_height = ?
+`, agentName, r.CommitId, startHeight, endHeight).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
+
+func (r *BackfillRepository) SaveBlockCommitBackfill(agentName string, startHeight, endHeight, nextHeight uint64) error {
+	res := r.DB.Save(&BlockCommitBackfill{
+		AgentName:   agentName,
+		CommitID:    r.CommitId,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		NextHeight:  nextHeight,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"time"
+)
+
+type TendermintBlockResults struct {
+	CreatedAt     time.Time              `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event         Event                  `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID     string                 `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	Height        uint64                 `gorm:"column:height;not null;type:bigint"`
+	TxCount       int                    `gorm:"column:tx_count;not null;type:int"`
+	FailedTxCount int                    `gorm:"column:failed_tx_count;not null;type:int"`
+	GasWanted     int64                  `gorm:"column:gas_wanted;not null;type:bigint"`
+	GasUsed       int64                  `gorm:"column:gas_used;not null;type:bigint"`
+	BlockEvents   []TendermintBlockEvent `gorm:"foreignKey:TendermintBlockResultsCreatedAt,EventUUID;references:CreatedAt,EventUUID"`
+}
+
+func (TendermintBlockResults) TableName() string {
+	return "tendermint_block_results"
+}
+
+// TendermintBlockEvent is a block-level abci event. (e.g. slash, jail, liveness)
+type TendermintBlockEvent struct {
+	TendermintBlockResultsCreatedAt time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	EventUUID                       string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	EventIndex                      int       `gorm:"primaryKey;column:event_index;not null;type:int"`
+	// Stage is where the event was emitted. `begin_block`, `end_block` or `finalize_block`
+	Stage     string `gorm:"column:stage;not null;type:varchar(20)"`
+	EventType string `gorm:"column:event_type;not null;type:varchar(100)"`
+	// Attributes is json array of the event's key/value attributes.
+	Attributes string `gorm:"column:attributes;not null;type:text"`
+}
+
+func (TendermintBlockEvent) TableName() string {
+	return "tendermint_block_event"
+}
+
+type BlockResultsRepository struct {
+	BaseRepository
+}
+
+func (r *BlockResultsRepository) CreateBatch(blockResults []TendermintBlockResults) error {
+	var events []Event
+	for _, blockResult := range blockResults {
+		events = append(events, blockResult.Event)
+	}
+
+	eventRepository := EventRepository{BaseRepository: r.BaseRepository}
+	err := eventRepository.CreateBatch(events)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.CreateInBatches(blockResults, len(blockResults))
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted batch slices for `event`, `tendermint_block_results`, `tendermint_block_event` successfully.")
+
+	return nil
+}
+
+func (r *BlockResultsRepository) FetchHighestHeight(agentName, commitId string) (uint64, error) {
+	var (
+		maxHeight uint64
+	)
+	err := r.DB.Raw(`select max(tbr.height)
+from tendermint_block_results as tbr, event as e
+where tbr.event_uuid = e.event_uuid
+and e.agent_name = ?
+and e.commit_id = ?;`, agentName, commitId).Scan(&maxHeight).Error
+
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("failed to get maximum height: %v", err))
+	}
+
+	return maxHeight, nil
+}
@@ -112,6 +112,24 @@ limit 1;`, agentName, commitId).Scan(&maxHeight).Error
 	return maxHeight, nil
 }
 
+// FindStoredHeights returns heights of [startHeight, endHeight] which the agent has stored already.
+func (r *CommitRepository) FindStoredHeights(agentName string, startHeight, endHeight uint64) ([]uint64, error) {
+	var heights []uint64
+
+	err := r.DB.Raw(`select distinct tm.height
+from tendermint_commit as tm, event as e
+where tm.event_uuid = e.event_uuid
+and e.agent_name = ?
+and e.commit_id = ?
+and tm.height between ? and ?
+order by tm.height;`, agentName, r.CommitId, startHeight, endHeight).Scan(&heights).Error
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to get stored heights: %v", err))
+	}
+
+	return heights, nil
+}
+
 type ValidatorAddressesWithAgents struct {
 	AgentName        string    `gorm:"column:agent_name"`
 	EventUUID        string    `gorm:"column:event_uuid"`
@@ -199,3 +217,32 @@ ORDER BY
 
 	return result, nil
 }
+
+// FindAverageBlockTime returns the average block time of the agent's latest commits. It returns 0 when there are less than 2 commits.
+func (r *CommitRepository) FindAverageBlockTime(agentName string, limit int) (time.Duration, error) {
+	var result struct {
+		Heights      int64 `gorm:"column:heights"`
+		Microseconds int64 `gorm:"column:microseconds"`
+	}
+
+	err := r.DB.Raw(`SELECT
+    coalesce(max(tc.height) - min(tc.height), 0) as heights,
+    coalesce(timestampdiff(MICROSECOND, min(tc.time), max(tc.time)), 0) as microseconds
+FROM
+    (select tc.height, tc.time
+     from tendermint_commit tc
+     JOIN event e ON tc.event_uuid = e.event_uuid
+     WHERE e.agent_name = ?
+       and e.commit_id = ?
+     ORDER BY tc.height DESC
+     LIMIT ?) as tc;
+`, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if result.Heights <= 0 {
+		return 0, nil
+	}
+	return time.Duration(result.Microseconds/result.Heights) * time.Microsecond, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+type TendermintConsensusState struct {
+	CreatedAt time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event     Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	Height    uint64    `gorm:"column:height;not null;type:bigint"`
+	Round     int32     `gorm:"column:round;not null;type:int"`
+	Step      int       `gorm:"column:step;not null;type:int"`
+	StartTime time.Time `gorm:"column:start_time;not null;type:datetime(6)"`
+	// ValidatorAddress is empty when the agent's validator isn't in the active set.
+	ValidatorAddress string  `gorm:"column:validator_address;null;type:varchar(100)"`
+	Prevoted         bool    `gorm:"column:prevoted;not null;type:bool"`
+	Precommitted     bool    `gorm:"column:precommitted;not null;type:bool"`
+	PrevotesRatio    float64 `gorm:"column:prevotes_ratio;not null;type:double"`
+	PrecommitsRatio  float64 `gorm:"column:precommits_ratio;not null;type:double"`
+}
+
+func (TendermintConsensusState) TableName() string {
+	return "tendermint_consensus_state"
+}
+
+type ConsensusStateRepository struct {
+	BaseRepository
+}
+
+func (r *ConsensusStateRepository) Save(consensusState TendermintConsensusState) error {
+	eventAssociation := r.DB.Model(&consensusState).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&consensusState.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&consensusState)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `tendermint_consensus_state` successfully. eventUUID: " + consensusState.Event.EventUUID)
+
+	return nil
+}
+
+// FindConsensusStatesAfterStartTime returns the agent's consensus states created after startTime, latest first.
+func (r *ConsensusStateRepository) FindConsensusStatesAfterStartTime(startTime time.Time, agentName, serviceName string) ([]TendermintConsensusState, error) {
+	var result []TendermintConsensusState
+
+	err := r.DB.Raw(`SELECT
+    tcs.*
+FROM
+    event e
+        JOIN
+    tendermint_consensus_state tcs ON e.event_uuid = tcs.event_uuid
+WHERE e.created_at >= ?
+    and e.service_name = ?
+    and e.event_type = 'tm:event:consensus_state'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY tcs.created_at DESC;
+`, startTime, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindLatestConsensusStates returns the agent's latest consensus states up to limit, latest first.
+func (r *ConsensusStateRepository) FindLatestConsensusStates(limit int, agentName, serviceName string) ([]TendermintConsensusState, error) {
+	var result []TendermintConsensusState
+
+	err := r.DB.Raw(`SELECT
+    tcs.*
+FROM
+    event e
+        JOIN
+    tendermint_consensus_state tcs ON e.event_uuid = tcs.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:consensus_state'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY tcs.created_at DESC
+LIMIT ?;
+`, serviceName, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
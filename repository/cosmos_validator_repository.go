@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+// CosmosValidator is our validator's state in x/staking and x/slashing of the Cosmos SDK.
+type CosmosValidator struct {
+	CreatedAt               time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event                   Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID               string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	OperatorAddress         string    `gorm:"column:operator_address;not null;type:varchar(100)"`
+	ConsensusAddress        string    `gorm:"column:consensus_address;not null;type:varchar(100)"`
+	Status                  string    `gorm:"column:status;not null;type:varchar(30)"`
+	Jailed                  bool      `gorm:"column:jailed;not null;type:bool"`
+	Tombstoned              bool      `gorm:"column:tombstoned;not null;type:bool"`
+	JailedUntil             time.Time `gorm:"column:jailed_until;not null;type:datetime(6)"`
+	Tokens                  string    `gorm:"column:tokens;not null;type:varchar(100)"`
+	DelegatorShares         string    `gorm:"column:delegator_shares;not null;type:varchar(100)"`
+	CommissionRate          string    `gorm:"column:commission_rate;not null;type:varchar(30)"`
+	CommissionMaxRate       string    `gorm:"column:commission_max_rate;not null;type:varchar(30)"`
+	CommissionMaxChangeRate string    `gorm:"column:commission_max_change_rate;not null;type:varchar(30)"`
+	StartHeight             int64     `gorm:"column:start_height;not null;type:bigint"`
+	IndexOffset             int64     `gorm:"column:index_offset;not null;type:bigint"`
+	MissedBlocksCounter     int64     `gorm:"column:missed_blocks_counter;not null;type:bigint"`
+	SignedBlocksWindow      int64     `gorm:"column:signed_blocks_window;not null;type:bigint"`
+	MinSignedPerWindow      string    `gorm:"column:min_signed_per_window;not null;type:varchar(30)"`
+	// DowntimeJailDuration is in seconds.
+	DowntimeJailDuration int64 `gorm:"column:downtime_jail_duration;not null;type:bigint"`
+}
+
+func (CosmosValidator) TableName() string {
+	return "cosmos_validator"
+}
+
+type CosmosValidatorRepository struct {
+	BaseRepository
+}
+
+func (r *CosmosValidatorRepository) Save(cosmosValidator CosmosValidator) error {
+	eventAssociation := r.DB.Model(&cosmosValidator).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&cosmosValidator.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&cosmosValidator)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `cosmos_validator` successfully. eventUUID: " + cosmosValidator.Event.EventUUID)
+
+	return nil
+}
+
+// FindLatestCosmosValidators returns the agent's latest validator states up to limit, latest first.
+func (r *CosmosValidatorRepository) FindLatestCosmosValidators(limit int, agentName, serviceName string) ([]CosmosValidator, error) {
+	var result []CosmosValidator
+
+	err := r.DB.Raw(`SELECT
+    cv.*
+FROM
+    event e
+        JOIN
+    cosmos_validator cv ON e.event_uuid = cv.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:cosmos_validator'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY cv.created_at DESC
+LIMIT ?;
+`, serviceName, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
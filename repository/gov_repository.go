@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"time"
+)
+
+// CosmosProposal is a proposal in voting period and our validator's vote on it.
+// Proposals of a run share an event, so a run without any proposal in voting period is an event alone.
+type CosmosProposal struct {
+	CreatedAt       time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event           Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID       string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	ProposalId      uint64    `gorm:"primaryKey;column:proposal_id;not null;type:bigint unsigned"`
+	Title           string    `gorm:"column:title;null;type:varchar(255)"`
+	Status          string    `gorm:"column:status;not null;type:varchar(50)"`
+	VotingStartTime time.Time `gorm:"column:voting_start_time;not null;type:datetime(6)"`
+	VotingEndTime   time.Time `gorm:"column:voting_end_time;not null;type:datetime(6)"`
+	Voter           string    `gorm:"column:voter;not null;type:varchar(100)"`
+	Voted           bool      `gorm:"column:voted;not null;type:bool"`
+	// VoteOption is comma separated options. (e.g. `VOTE_OPTION_YES`)
+	VoteOption string `gorm:"column:vote_option;null;type:varchar(255)"`
+}
+
+func (CosmosProposal) TableName() string {
+	return "cosmos_proposal"
+}
+
+// GovVoteReminder records that the checker has reminded a proposal's vote at a stage.
+type GovVoteReminder struct {
+	AgentName  string `gorm:"primaryKey;column:agent_name;not null;type:varchar(100)"`
+	CommitID   string `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	ProposalId uint64 `gorm:"primaryKey;column:proposal_id;not null;type:bigint unsigned"`
+	// RemindBefore is the stage in seconds before voting end.
+	RemindBefore int64     `gorm:"primaryKey;column:remind_before;not null;type:bigint"`
+	CreatedAt    time.Time `gorm:"column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+}
+
+func (GovVoteReminder) TableName() string {
+	return "gov_vote_reminder"
+}
+
+type GovRepository struct {
+	BaseRepository
+}
+
+// Save stores the event and proposals of a run.
+func (r *GovRepository) Save(event Event, proposals []CosmosProposal) error {
+	eventRepository := EventRepository{BaseRepository: r.BaseRepository}
+	err := eventRepository.CreateBatch([]Event{event})
+	if err != nil {
+		return err
+	}
+
+	if len(proposals) > 0 {
+		res := r.DB.Omit("Event").Create(&proposals)
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	log.Debug("Inserted `event`, `cosmos_proposal` successfully. eventUUID: " + event.EventUUID)
+
+	return nil
+}
+
+// FindLatestProposals returns proposals of the agent's latest run.
+func (r *GovRepository) FindLatestProposals(agentName, serviceName string) ([]CosmosProposal, error) {
+	var result []CosmosProposal
+
+	err := r.DB.Raw(`SELECT
+    cp.*
+FROM
+    cosmos_proposal cp
+WHERE cp.event_uuid = (
+    SELECT
+        e.event_uuid
+    FROM
+        event e
+    WHERE e.service_name = ?
+        and e.event_type = 'tm:event:gov'
+      and e.agent_name = ?
+    and e.commit_id = ?
+    ORDER BY e.created_at DESC
+    LIMIT 1)
+ORDER BY cp.voting_end_time;
+`, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *GovRepository) ExistsGovVoteReminder(agentName string, proposalId uint64, remindBefore time.Duration) (bool, error) {
+	var result bool
+
+	err := r.DB.Raw(`SELECT exists(SELECT 1
+    FROM gov_vote_reminder gvr
+    WHERE gvr.agent_name = ?
+      and gvr.commit_id = ?
+      and gvr.proposal_id = ?
+      and gvr.remind_before = ?)
+`, agentName, r.CommitId, proposalId, int64(remindBefore.Seconds())).Scan(&result).Error
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (r *GovRepository) SaveGovVoteReminder(agentName string, proposalId uint64, remindBefore time.Duration) error {
+	res := r.DB.Create(&GovVoteReminder{
+		AgentName:    agentName,
+		CommitID:     r.CommitId,
+		ProposalId:   proposalId,
+		RemindBefore: int64(remindBefore.Seconds()),
+		CreatedAt:    time.Now().UTC(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	return nil
+}
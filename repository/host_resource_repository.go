@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+// HostResource is memory, load average and file descriptors of the node host, read from /proc.
+type HostResource struct {
+	CreatedAt      time.Time   `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event          Event       `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID      string      `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	MemTotalBytes  uint64      `gorm:"column:mem_total_bytes;not null;type:bigint unsigned"`
+	MemAvailBytes  uint64      `gorm:"column:mem_avail_bytes;not null;type:bigint unsigned"`
+	SwapTotalBytes uint64      `gorm:"column:swap_total_bytes;not null;type:bigint unsigned"`
+	SwapFreeBytes  uint64      `gorm:"column:swap_free_bytes;not null;type:bigint unsigned"`
+	Load1          float64     `gorm:"column:load1;not null;type:double"`
+	Load5          float64     `gorm:"column:load5;not null;type:double"`
+	Load15         float64     `gorm:"column:load15;not null;type:double"`
+	NumCPU         int         `gorm:"column:num_cpu;not null;type:int"`
+	OpenFds        uint64      `gorm:"column:open_fds;not null;type:bigint unsigned"`
+	MaxFds         uint64      `gorm:"column:max_fds;not null;type:bigint unsigned"`
+	DiskUsages     []DiskUsage `gorm:"foreignKey:CreatedAt,EventUUID;references:CreatedAt,EventUUID"`
+}
+
+func (HostResource) TableName() string {
+	return "host_resource"
+}
+
+// MemUsageRatio is the ratio of memory not available for starting new applications.
+func (h HostResource) MemUsageRatio() float64 {
+	if h.MemTotalBytes == 0 {
+		return 0
+	}
+	return float64(h.MemTotalBytes-h.MemAvailBytes) / float64(h.MemTotalBytes)
+}
+
+// FdUsageRatio is the ratio of allocated file descriptors to the system-wide limit.
+func (h HostResource) FdUsageRatio() float64 {
+	if h.MaxFds == 0 {
+		return 0
+	}
+	return float64(h.OpenFds) / float64(h.MaxFds)
+}
+
+type HostResourceRepository struct {
+	BaseRepository
+}
+
+func (r *HostResourceRepository) Save(hostResource HostResource) error {
+	eventAssociation := r.DB.Model(&hostResource).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&hostResource.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&hostResource)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `host_resource`, `disk_usage` successfully. eventUUID: " + hostResource.Event.EventUUID)
+
+	return nil
+}
+
+// FindLatestHostResources returns the agent's latest host resources up to limit, latest first.
+// DiskUsages are not loaded.
+func (r *HostResourceRepository) FindLatestHostResources(limit int, agentName, serviceName string) ([]HostResource, error) {
+	var result []HostResource
+
+	err := r.DB.Raw(`SELECT
+    hr.*
+FROM
+    event e
+        JOIN
+    host_resource hr ON e.event_uuid = hr.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:host_resource'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY hr.created_at DESC
+LIMIT ?;
+`, serviceName, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
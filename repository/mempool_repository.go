@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+type TendermintMempool struct {
+	CreatedAt  time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event      Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID  string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	NTxs       int       `gorm:"column:n_txs;not null;type:int"`
+	Total      int       `gorm:"column:total;not null;type:int"`
+	TotalBytes int64     `gorm:"column:total_bytes;not null;type:bigint"`
+}
+
+func (TendermintMempool) TableName() string {
+	return "tendermint_mempool"
+}
+
+type MempoolRepository struct {
+	BaseRepository
+}
+
+func (r *MempoolRepository) Save(mempool TendermintMempool) error {
+	eventAssociation := r.DB.Model(&mempool).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&mempool.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&mempool)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `tendermint_mempool` successfully. eventUUID: " + mempool.Event.EventUUID)
+
+	return nil
+}
+
+// FindLatestMempools returns the agent's latest mempool states up to limit, latest first.
+func (r *MempoolRepository) FindLatestMempools(limit int, agentName, serviceName string) ([]TendermintMempool, error) {
+	var result []TendermintMempool
+
+	err := r.DB.Raw(`SELECT
+    tm.*
+FROM
+    event e
+        JOIN
+    tendermint_mempool tm ON e.event_uuid = tm.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:mempool'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY tm.created_at DESC
+LIMIT ?;
+`, serviceName, agentName, r.CommitId, limit).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"time"
+)
+
+// Metric is a sample of a scraped series. Samples of a scrape share an event.
+type Metric struct {
+	CreatedAt   time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event       Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID   string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	MetricIndex int       `gorm:"primaryKey;column:metric_index;not null;type:int"`
+	Name        string    `gorm:"column:name;not null;type:varchar(255)"`
+	// Labels is json object of the series' labels.
+	Labels string  `gorm:"column:labels;null;type:text"`
+	Value  float64 `gorm:"column:value;not null;type:double"`
+}
+
+func (Metric) TableName() string {
+	return "metric"
+}
+
+type MetricRepository struct {
+	BaseRepository
+}
+
+// Save stores the event and samples of a scrape.
+func (r *MetricRepository) Save(event Event, metrics []Metric) error {
+	eventRepository := EventRepository{BaseRepository: r.BaseRepository}
+	err := eventRepository.CreateBatch([]Event{event})
+	if err != nil {
+		return err
+	}
+
+	if len(metrics) > 0 {
+		res := r.DB.Omit("Event").CreateInBatches(metrics, len(metrics))
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	log.Debug("Inserted `event`, `metric` successfully. eventUUID: " + event.EventUUID)
+
+	return nil
+}
+
+// FindLatestMetricsByName returns samples of the series from the agent's latest scrape which contains it.
+func (r *MetricRepository) FindLatestMetricsByName(name, agentName, serviceName string) ([]Metric, error) {
+	var result []Metric
+
+	err := r.DB.Raw(`SELECT
+    m.*
+FROM
+    metric m
+WHERE m.name = ?
+  and m.event_uuid = (
+    SELECT
+        e.event_uuid
+    FROM
+        event e
+            JOIN
+        metric lm ON e.event_uuid = lm.event_uuid
+    WHERE lm.name = ?
+        and e.service_name = ?
+        and e.event_type = 'tm:event:metric'
+      and e.agent_name = ?
+    and e.commit_id = ?
+    ORDER BY e.created_at DESC
+    LIMIT 1);
+`, name, name, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -28,6 +28,23 @@ type TendermintPeerInfo struct {
 	TendermintNodeInfo         TendermintNodeInfo `gorm:"foreignKey:TendermintNodeInfoUUID;references:TendermintNodeInfoUUID"`
 	TendermintNodeInfoUUID     string             `gorm:"column:tendermint_node_info_uuid;not null;type:CHAR(36)"`
 	RemoteIP                   string             `gorm:"column:remote_ip;not null;type:varchar(50)"`
+	// Connection quality at the time of net_info. Rates are in bytes per second.
+	// They're NULL when the peer's connection status couldn't be parsed.
+	ConnectionDuration *time.Duration `gorm:"column:connection_duration;null;type:bigint"`
+	SendBytes          *int64         `gorm:"column:send_bytes;null;type:bigint"`
+	RecvBytes          *int64         `gorm:"column:recv_bytes;null;type:bigint"`
+	SendRate           *int64         `gorm:"column:send_rate;null;type:bigint"`
+	RecvRate           *int64         `gorm:"column:recv_rate;null;type:bigint"`
+	SendQueueSize      *int           `gorm:"column:send_queue_size;null;type:int"`
+	SendQueueCapacity  *int           `gorm:"column:send_queue_capacity;null;type:int"`
+	// SendQueueSaturation is the highest ratio of queued messages to the capacity among the peer's channels.
+	SendQueueSaturation *float64 `gorm:"column:send_queue_saturation;null;type:double"`
+	// Location of RemoteIP looked up from GeoIP databases. Empty when they aren't configured or don't know the ip.
+	CountryCode    string `gorm:"column:country_code;null;type:varchar(2)"`
+	Country        string `gorm:"column:country;null;type:varchar(100)"`
+	City           string `gorm:"column:city;null;type:varchar(100)"`
+	ASN            uint   `gorm:"column:asn;null;type:int unsigned"`
+	ASOrganization string `gorm:"column:as_organization;null;type:varchar(255)"`
 }
 
 func (TendermintPeerInfo) TableName() string {
@@ -166,3 +183,44 @@ GROUP BY
 
 	return result, nil
 }
+
+// PeerNetwork is how many peers of a net_info are in an autonomous system.
+type PeerNetwork struct {
+	// ASN is 0 for peers which weren't located.
+	ASN            uint   `gorm:"column:asn"`
+	ASOrganization string `gorm:"column:as_organization"`
+	Peers          int    `gorm:"column:peers"`
+}
+
+// FindLatestPeerNetworks returns the autonomous systems of the peers in the agent's latest net_info.
+func (r *NetInfoRepository) FindLatestPeerNetworks(agentName, serviceName string) ([]PeerNetwork, error) {
+	var result []PeerNetwork
+
+	err := r.DB.Raw(`SELECT
+    coalesce(tpi.asn, 0) as asn,
+    coalesce(tpi.as_organization, '') as as_organization,
+    count(*) as peers
+FROM
+    tendermint_peer_info tpi
+        JOIN (
+        SELECT tni.event_uuid, tni.created_at
+        FROM
+            event e
+                JOIN
+            tendermint_net_info tni ON e.event_uuid = tni.event_uuid
+        WHERE e.service_name = ?
+          and e.event_type = 'tm:event:net_info'
+          and e.agent_name = ?
+          and e.commit_id = ?
+        ORDER BY tni.created_at DESC
+        LIMIT 1
+    ) latest ON tpi.event_uuid = latest.event_uuid
+        AND tpi.created_at = latest.created_at
+GROUP BY coalesce(tpi.asn, 0), coalesce(tpi.as_organization, '');
+`, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -13,6 +13,8 @@ type TendermintNodeInfo struct {
 	ListenAddr string `gorm:"column:listen_addr;not null;type:varchar(255)"`
 	ChainId    string `gorm:"column:chain_id;not null;type:varchar(20)"`
 	Moniker    string `gorm:"column:moniker;not null;type:varchar(50)"`
+	// Version is the CometBFT version of the node. (e.g. `0.37.5`)
+	Version string `gorm:"column:version;null;type:varchar(50)"`
 
 	TendermintPeerInfos []TendermintPeerInfo `gorm:"foreignKey:TendermintNodeInfoUUID;references:TendermintNodeInfoUUID"`
 	TendermintNodeInfos []TendermintNodeInfo `gorm:"foreignKey:TendermintNodeInfoUUID;references:TendermintNodeInfoUUID"`
@@ -117,3 +119,40 @@ ORDER BY e.agent_name,ts.created_at DESC;
 
 	return result, nil
 }
+
+type NodeVersionStatus struct {
+	CreatedAt         time.Time `gorm:"column:created_at;not null;type:datetime(6)"`
+	LatestBlockHeight uint64    `gorm:"column:latest_block_height"`
+	Version           string    `gorm:"column:version"`
+}
+
+// FindLatestNodeVersionStatus returns the agent's latest height and node version. It returns nil when there is no status.
+func (r *StatusRepository) FindLatestNodeVersionStatus(agentName, serviceName string) (*NodeVersionStatus, error) {
+	var result []NodeVersionStatus
+
+	err := r.DB.Raw(`SELECT
+    ts.created_at,
+    ts.latest_block_height,
+    tni.version
+FROM
+    event e
+        JOIN
+    tendermint_status ts ON e.event_uuid = ts.event_uuid
+        JOIN
+    tendermint_node_info tni ON ts.tendermint_node_info_uuid = tni.tendermint_node_info_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:status'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY ts.created_at DESC
+LIMIT 1;
+`, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
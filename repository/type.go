@@ -12,6 +12,8 @@ type Event struct {
 	CommitID    string    `gorm:"column:commit_id;not null;type:varchar(255)"`
 	EventType   string    `gorm:"column:event_type;not null;type:varchar(100)"`
 	CreatedAt   time.Time `gorm:"column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	// RpcEndpoint is the agent's rpc endpoint which served this event.
+	RpcEndpoint string `gorm:"column:rpc_endpoint;null;type:varchar(255)"`
 
 	TendermintCommits          []TendermintCommit          `gorm:"foreignKey:EventUUID;references:EventUUID"`
 	TendermintCommitSignatures []TendermintCommitSignature `gorm:"foreignKey:EventUUID;references:EventUUID"`
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"github.com/b-harvest/Harvestmon/log"
+	"time"
+)
+
+// CosmosUpgradePlan is a scheduled upgrade of x/upgrade and the node's state when it was observed.
+// Runs without a scheduled upgrade store an event alone.
+type CosmosUpgradePlan struct {
+	CreatedAt time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event     Event     `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	Name      string    `gorm:"column:name;not null;type:varchar(255)"`
+	Height    int64     `gorm:"column:height;not null;type:bigint"`
+	Info      string    `gorm:"column:info;null;type:text"`
+	// LatestBlockHeight and NodeVersion are of the node when the plan was observed.
+	LatestBlockHeight uint64 `gorm:"column:latest_block_height;not null;type:bigint"`
+	NodeVersion       string `gorm:"column:node_version;not null;type:varchar(50)"`
+	// AverageBlockTime is in milliseconds. EstimatedTime is null when the average block time isn't known.
+	AverageBlockTime int64      `gorm:"column:average_block_time;not null;type:bigint"`
+	EstimatedTime    *time.Time `gorm:"column:estimated_time;null;type:datetime(6)"`
+}
+
+func (CosmosUpgradePlan) TableName() string {
+	return "cosmos_upgrade_plan"
+}
+
+// UpgradeReminder records that the checker has reminded an upgrade at a stage.
+type UpgradeReminder struct {
+	AgentName string `gorm:"primaryKey;column:agent_name;not null;type:varchar(100)"`
+	CommitID  string `gorm:"primaryKey;column:commit_id;not null;type:varchar(255)"`
+	PlanName  string `gorm:"primaryKey;column:plan_name;not null;type:varchar(255)"`
+	Height    int64  `gorm:"primaryKey;column:height;not null;type:bigint"`
+	// RemindBefore is the stage in seconds before the estimated time.
+	RemindBefore int64     `gorm:"primaryKey;column:remind_before;not null;type:bigint"`
+	CreatedAt    time.Time `gorm:"column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+}
+
+func (UpgradeReminder) TableName() string {
+	return "upgrade_reminder"
+}
+
+type UpgradeRepository struct {
+	BaseRepository
+}
+
+// Save stores the event, and the plan if an upgrade is scheduled.
+func (r *UpgradeRepository) Save(event Event, plan *CosmosUpgradePlan) error {
+	eventRepository := EventRepository{BaseRepository: r.BaseRepository}
+	err := eventRepository.Save(event)
+	if err != nil {
+		return err
+	}
+
+	if plan != nil {
+		res := r.DB.Omit("Event").Create(plan)
+		if res.Error != nil {
+			return res.Error
+		}
+	}
+
+	log.Debug("Inserted `event`, `cosmos_upgrade_plan` successfully. eventUUID: " + event.EventUUID)
+
+	return nil
+}
+
+type UpgradePlanState struct {
+	CreatedAt         time.Time  `gorm:"column:created_at;not null;type:datetime(6)"`
+	Name              string     `gorm:"column:name"`
+	Height            int64      `gorm:"column:height"`
+	LatestBlockHeight uint64     `gorm:"column:latest_block_height"`
+	NodeVersion       string     `gorm:"column:node_version"`
+	EstimatedTime     *time.Time `gorm:"column:estimated_time;null;type:datetime(6)"`
+	// Scheduled is false when the plan has disappeared since. (applied or cancelled)
+	Scheduled bool `gorm:"column:scheduled"`
+	// OldNodeVersion is the node version when the plan was observed first.
+	OldNodeVersion string `gorm:"column:old_node_version"`
+}
+
+// FindLatestUpgradePlan returns the agent's latest observed plan. It returns nil when no plan has been observed.
+func (r *UpgradeRepository) FindLatestUpgradePlan(agentName, serviceName string) (*UpgradePlanState, error) {
+	var result []UpgradePlanState
+
+	err := r.DB.Raw(`SELECT
+    cup.created_at,
+    cup.name,
+    cup.height,
+    cup.latest_block_height,
+    cup.node_version,
+    cup.estimated_time,
+    NOT EXISTS(SELECT 1
+               FROM event e2
+               WHERE e2.service_name = e.service_name
+                 and e2.event_type = e.event_type
+                 and e2.agent_name = e.agent_name
+                 and e2.commit_id = e.commit_id
+                 and e2.created_at > e.created_at) as scheduled,
+    (SELECT cup2.node_version
+     FROM cosmos_upgrade_plan cup2
+              JOIN event e3 ON cup2.event_uuid = e3.event_uuid
+     WHERE e3.agent_name = e.agent_name
+       and e3.commit_id = e.commit_id
+       and cup2.name = cup.name
+       and cup2.height = cup.height
+     ORDER BY cup2.created_at
+     LIMIT 1) as old_node_version
+FROM
+    event e
+        JOIN
+    cosmos_upgrade_plan cup ON e.event_uuid = cup.event_uuid
+WHERE e.service_name = ?
+    and e.event_type = 'tm:event:upgrade'
+  and e.agent_name = ?
+and e.commit_id = ?
+ORDER BY cup.created_at DESC
+LIMIT 1;
+`, serviceName, agentName, r.CommitId).Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &result[0], nil
+}
+
+func (r *UpgradeRepository) ExistsUpgradeReminder(agentName, planName string, height int64, remindBefore time.Duration) (bool, error) {
+	var result bool
+
+	err := r.DB.Raw(`SELECT exists(SELECT 1
+    FROM upgrade_reminder ur
+    WHERE ur.agent_name = ?
+      and ur.commit_id = ?
+      and ur.plan_name = ?
+      and ur.height = ?
+      and ur.remind_before = ?)
+`, agentName, r.CommitId, planName, height, int64(remindBefore.Seconds())).Scan(&result).Error
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (r *UpgradeRepository) SaveUpgradeReminder(agentName, planName string, height int64, remindBefore time.Duration) error {
+	res := r.DB.Create(&UpgradeReminder{
+		AgentName:    agentName,
+		CommitID:     r.CommitId,
+		PlanName:     planName,
+		Height:       height,
+		RemindBefore: int64(remindBefore.Seconds()),
+		CreatedAt:    time.Now().UTC(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	return nil
+}
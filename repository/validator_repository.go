@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/b-harvest/Harvestmon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"time"
+)
+
+// TendermintValidatorSet is a snapshot of the active validator set.
+// A snapshot is stored only when ValidatorsHash changes, so it's valid from Height until the next snapshot.
+type TendermintValidatorSet struct {
+	CreatedAt        time.Time             `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	Event            Event                 `gorm:"foreignKey:EventUUID;references:EventUUID"`
+	EventUUID        string                `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	ChainID          string                `gorm:"column:chain_id;not null;type:varchar(20)"`
+	Height           uint64                `gorm:"column:height;not null;type:bigint"`
+	ValidatorsHash   string                `gorm:"column:validators_hash;not null;type:varchar(100)"`
+	Total            int                   `gorm:"column:total;not null;type:int"`
+	TotalVotingPower int64                 `gorm:"column:total_voting_power;not null;type:bigint"`
+	Validators       []TendermintValidator `gorm:"foreignKey:TendermintValidatorSetCreatedAt,EventUUID;references:CreatedAt,EventUUID"`
+}
+
+func (TendermintValidatorSet) TableName() string {
+	return "tendermint_validator_set"
+}
+
+type TendermintValidator struct {
+	Address                         string    `gorm:"primaryKey;column:address;not null;type:varchar(100)"`
+	TendermintValidatorSetCreatedAt time.Time `gorm:"primaryKey;column:created_at;not null;type:datetime(6);autoCreateTime:false"`
+	EventUUID                       string    `gorm:"primaryKey;column:event_uuid;not null;type:CHAR(36)"`
+	PubKeyType                      string    `gorm:"column:pub_key_type;not null;type:varchar(100)"`
+	PubKey                          string    `gorm:"column:pub_key;not null;type:varchar(200)"`
+	VotingPower                     int64     `gorm:"column:voting_power;not null;type:bigint"`
+	ProposerPriority                int64     `gorm:"column:proposer_priority;not null;type:bigint"`
+}
+
+func (TendermintValidator) TableName() string {
+	return "tendermint_validator"
+}
+
+type ValidatorRepository struct {
+	BaseRepository
+}
+
+func (r *ValidatorRepository) Save(validatorSet TendermintValidatorSet) error {
+	eventAssociation := r.DB.Model(&validatorSet).Association("Event")
+	eventAssociation.Relationship.Type = schema.BelongsTo
+	err := eventAssociation.Append(&validatorSet.Event)
+	if err != nil {
+		return err
+	}
+
+	res := r.DB.Create(&validatorSet)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	log.Debug("Inserted `event`, `tendermint_validator_set`, `tendermint_validator` successfully. eventUUID: " + validatorSet.Event.EventUUID)
+
+	return nil
+}
+
+// FetchLatestValidatorsHash returns ValidatorsHash of the latest snapshot stored by the agent.
+// It returns an empty string when no snapshot has been stored yet.
+func (r *ValidatorRepository) FetchLatestValidatorsHash(agentName, commitId string) (string, error) {
+	var validatorsHash string
+	err := r.DB.Raw(`select tvs.validators_hash
+from tendermint_validator_set as tvs, event as e
+where tvs.event_uuid = e.event_uuid
+and e.agent_name = ?
+and e.commit_id = ?
+order by tvs.height desc
+limit 1;`, agentName, commitId).Scan(&validatorsHash).Error
+
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("failed to get latest validators hash: %v", err))
+	}
+
+	return validatorsHash, nil
+}
+
+// FindValidatorSetAtHeight returns the validator set which was active at height.
+func (r *ValidatorRepository) FindValidatorSetAtHeight(chainId string, height uint64) (*TendermintValidatorSet, error) {
+	var validatorSet TendermintValidatorSet
+	err := r.DB.Preload("Validators").
+		Where("chain_id = ? and height <= ?", chainId, height).
+		Order("height desc").
+		First(&validatorSet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no validator set snapshot found at or before height %d. chainId: %s", height, chainId)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &validatorSet, nil
+}
+
+type ValidatorVotingPower struct {
+	Address string
+	// SnapshotHeight is the height of the snapshot which the voting power came from.
+	SnapshotHeight   uint64
+	VotingPower      int64
+	TotalVotingPower int64
+}
+
+// FindVotingPowerAtHeight returns voting power of the validator(hex address) at height.
+// It returns nil without error when the address wasn't in the active set at that height.
+func (r *ValidatorRepository) FindVotingPowerAtHeight(chainId, address string, height uint64) (*ValidatorVotingPower, error) {
+	validatorSet, err := r.FindValidatorSetAtHeight(chainId, height)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, validator := range validatorSet.Validators {
+		if validator.Address == address {
+			return &ValidatorVotingPower{
+				Address:          validator.Address,
+				SnapshotHeight:   validatorSet.Height,
+				VotingPower:      validator.VotingPower,
+				TotalVotingPower: validatorSet.TotalVotingPower,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
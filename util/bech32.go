@@ -0,0 +1,126 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// DecodeBech32 decodes a bech32 address(e.g. `cosmosvaloper1...`) into its prefix and bytes.
+func DecodeBech32(address string) (string, []byte, error) {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return "", nil, errors.New("mixed case bech32 address: " + address)
+	}
+	address = strings.ToLower(address)
+
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+7 > len(address) {
+		return "", nil, errors.New("invalid bech32 address: " + address)
+	}
+
+	prefix := address[:sep]
+	data := make([]byte, 0, len(address)-sep-1)
+	for _, c := range address[sep+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q: %s", c, address)
+		}
+		data = append(data, byte(i))
+	}
+
+	if bech32Polymod(append(bech32ExpandPrefix(prefix), data...)) != 1 {
+		return "", nil, errors.New("invalid bech32 checksum: " + address)
+	}
+
+	decoded, err := convertBits(data[:len(data)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return prefix, decoded, nil
+}
+
+// EncodeBech32 encodes bytes into a bech32 address with prefix.
+func EncodeBech32(prefix string, data []byte) (string, error) {
+	converted, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	values := append(bech32ExpandPrefix(prefix), converted...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte('1')
+	for _, b := range converted {
+		sb.WriteByte(bech32Charset[b])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+// ConvertBech32Prefix re-encodes address with another prefix. (e.g. `cosmosvaloper1...` -> `cosmos1...`)
+func ConvertBech32Prefix(address, prefix string) (string, error) {
+	_, data, err := DecodeBech32(address)
+	if err != nil {
+		return "", err
+	}
+	return EncodeBech32(prefix, data)
+}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandPrefix(prefix string) []byte {
+	expanded := make([]byte, 0, len(prefix)*2+1)
+	for i := 0; i < len(prefix); i++ {
+		expanded = append(expanded, prefix[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(prefix); i++ {
+		expanded = append(expanded, prefix[i]&31)
+	}
+	return expanded
+}
+
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var (
+		acc    uint32
+		bits   uint
+		result []byte
+		maxV   = uint32(1)<<toBits - 1
+	)
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxV))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxV))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxV != 0 {
+		return nil, errors.New("invalid padding of bech32 data")
+	}
+	return result, nil
+}
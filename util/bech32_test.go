@@ -0,0 +1,29 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBech32(t *testing.T) {
+
+	t.Run("convert valoper to account address", func(t *testing.T) {
+		address, err := ConvertBech32Prefix("cosmosvaloper1clpqr4nrk4khgkxj78fcwwh6dl3uw4epsluffn", "cosmos")
+		assert.NoError(t, err)
+		assert.Equal(t, "cosmos1clpqr4nrk4khgkxj78fcwwh6dl3uw4ep4tgu9q", address)
+
+		prefix, data, err := DecodeBech32(address)
+		assert.NoError(t, err)
+		assert.Equal(t, "cosmos", prefix)
+		assert.Len(t, data, 20)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, _, err := DecodeBech32("cosmosvaloper1clpqr4nrk4khgkxj78fcwwh6dl3uw4epsluffm")
+		assert.Error(t, err)
+
+		_, _, err = DecodeBech32("cosmos1b")
+		assert.Error(t, err)
+	})
+
+}